@@ -18,11 +18,13 @@ import (
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/agent"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
 	discConfig "github.com/wavefronthq/wavefront-kubernetes-collector/internal/discovery"
+	eventTypes "github.com/wavefronthq/wavefront-kubernetes-collector/internal/events"
 	kube_config "github.com/wavefronthq/wavefront-kubernetes-collector/internal/kubernetes"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/options"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/discovery"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/events"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/manager"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/processors"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sinks"
@@ -105,6 +107,7 @@ func createAgentOrDie(cfg *configuration.Config) *agent.Agent {
 	// create sources manager
 	sourceManager := sources.Manager()
 	sourceManager.SetDefaultCollectionInterval(cfg.DefaultCollectionInterval)
+	sourceManager.SetAlignCollectionIntervals(cfg.AlignCollectionIntervals)
 	err := sourceManager.BuildProviders(*cfg.Sources)
 	if err != nil {
 		log.Fatalf("Failed to create source manager: %v", err)
@@ -117,20 +120,23 @@ func createAgentOrDie(cfg *configuration.Config) *agent.Agent {
 	// create data processors
 	kubeClient := createKubeClientOrDie(*cfg.Sources.SummaryConfig)
 	podLister := getPodListerOrDie(kubeClient)
-	dataProcessors := createDataProcessorsOrDie(kubeClient, clusterName, podLister, *cfg.Sources.SummaryConfig)
+	dataProcessors := createDataProcessorsOrDie(kubeClient, clusterName, podLister, cfg)
 
 	// create discovery manager
 	handler := sourceManager.(metrics.ProviderHandler)
 	dm := createDiscoveryManagerOrDie(kubeClient, cfg, handler, podLister)
 
+	// create event router
+	er := createEventRouter(kubeClient, cfg, sinkManager)
+
 	// create uber manager
-	man, err := manager.NewFlushManager(dataProcessors, sinkManager, cfg.FlushInterval)
+	man, err := manager.NewFlushManager(dataProcessors, sinkManager, cfg.FlushInterval, cfg.AlignCollectionIntervals)
 	if err != nil {
 		log.Fatalf("Failed to create main manager: %v", err)
 	}
 
 	// create and start agent
-	ag := agent.NewAgent(man, dm)
+	ag := agent.NewAgent(man, dm, er)
 	ag.Start()
 	return ag
 }
@@ -172,6 +178,9 @@ func fillDefaults(cfg *configuration.Config) {
 	if cfg.DiscoveryInterval == 0 {
 		cfg.DiscoveryInterval = 10 * time.Minute
 	}
+	if len(cfg.Processors) == 0 {
+		cfg.Processors = configuration.DefaultProcessors()
+	}
 }
 
 // converts flags to configuration for backwards compatibility support
@@ -188,10 +197,10 @@ func convertOrDie(opt *options.CollectorRunOptions, cfg *configuration.Config) *
 	return optsCfg
 }
 
-func setClusterNameOnSinks(clusterName string, sinks []*configuration.WavefrontSinkConfig) {
+func setClusterNameOnSinks(clusterName string, sinks []*configuration.SinkConfig) {
 	log.Infof("using clusterName: %s", clusterName)
 	for _, sink := range sinks {
-		sink.ClusterName = clusterName
+		sink.SetClusterName(clusterName)
 	}
 }
 
@@ -228,6 +237,24 @@ func createDiscoveryManagerOrDie(client *kube_client.Clientset, cfg *configurati
 	return nil
 }
 
+func createEventRouter(client *kube_client.Clientset, cfg *configuration.Config, sinkManager metrics.DataSink) *events.EventRouter {
+	if !cfg.EventsConfig.Enabled {
+		return nil
+	}
+	sink, ok := sinkManager.(eventTypes.EventSink)
+	if !ok {
+		log.Errorf("sink manager does not support exporting events")
+		return nil
+	}
+	return events.NewEventRouter(events.RunConfig{
+		KubeClient:  client,
+		Sink:        sink,
+		ClusterName: cfg.ClusterName,
+		Daemon:      cfg.Daemon,
+		Config:      cfg.EventsConfig,
+	})
+}
+
 func registerVersion() {
 	parts := strings.Split(version, ".")
 	friendly := fmt.Sprintf("%s.%s%s", parts[0], parts[1], parts[2])
@@ -239,7 +266,7 @@ func registerVersion() {
 	m.Update(f)
 }
 
-func createSinkManagerOrDie(cfgs []*configuration.WavefrontSinkConfig, sinkExportDataTimeout time.Duration) metrics.DataSink {
+func createSinkManagerOrDie(cfgs []*configuration.SinkConfig, sinkExportDataTimeout time.Duration) metrics.DataSink {
 	sinksFactory := sinks.NewSinkFactory()
 	sinkList := sinksFactory.BuildAll(cfgs)
 
@@ -254,7 +281,7 @@ func createSinkManagerOrDie(cfgs []*configuration.WavefrontSinkConfig, sinkExpor
 }
 
 func getPodListerOrDie(kubeClient *kube_client.Clientset) v1listers.PodLister {
-	podLister, err := util.GetPodLister(kubeClient)
+	podLister, _, err := util.GetPodLister(kubeClient)
 	if err != nil {
 		log.Fatalf("Failed to create podLister: %v", err)
 	}
@@ -270,41 +297,117 @@ func createKubeClientOrDie(cfg configuration.SummaySourceConfig) *kube_client.Cl
 }
 
 func createDataProcessorsOrDie(kubeClient *kube_client.Clientset, cluster string, podLister v1listers.PodLister,
-	cfg configuration.SummaySourceConfig) []metrics.DataProcessor {
+	cfg *configuration.Config) []metrics.DataProcessor {
 
 	labelCopier, err := util.NewLabelCopier(",", []string{}, []string{})
 	if err != nil {
 		log.Fatalf("Failed to initialize label copier: %v", err)
 	}
 
-	dataProcessors := []metrics.DataProcessor{
-		// Convert cumulative to rate
-		processors.NewRateCalculator(metrics.RateMetricsMapping),
+	var dataProcessors []metrics.DataProcessor
+	for _, pc := range cfg.Processors {
+		if !pc.Enabled {
+			log.Infof("processor %s is disabled", pc.Name)
+			continue
+		}
+		processor, err := createDataProcessor(kubeClient, cluster, podLister, labelCopier, cfg, pc)
+		if err != nil {
+			log.Fatalf("Failed to create processor %s: %v", pc.Name, err)
+		}
+		if pc.Points {
+			processor = processors.NewPointsProcessor(processor)
+		}
+		dataProcessors = append(dataProcessors, processor)
 	}
+	return dataProcessors
+}
 
-	podBasedEnricher, err := processors.NewPodBasedEnricher(podLister, labelCopier)
-	if err != nil {
-		log.Fatalf("Failed to create PodBasedEnricher: %v", err)
-	}
-	dataProcessors = append(dataProcessors, podBasedEnricher)
+func createDataProcessor(kubeClient *kube_client.Clientset, cluster string, podLister v1listers.PodLister,
+	labelCopier *util.LabelCopier, cfg *configuration.Config, pc *configuration.ProcessorConfig) (metrics.DataProcessor, error) {
 
-	namespaceBasedEnricher, err := processors.NewNamespaceBasedEnricher(kubeClient)
-	if err != nil {
-		log.Fatalf("Failed to create NamespaceBasedEnricher: %v", err)
-	}
-	dataProcessors = append(dataProcessors, namespaceBasedEnricher)
+	switch pc.Name {
+	case configuration.RateCalculatorProcessor:
+		// Convert cumulative to rate
+		return processors.NewRateCalculator(metrics.RateMetricsMapping), nil
+	case configuration.PodBasedEnricherProcessor:
+		return processors.NewPodBasedEnricher(podLister, labelCopier)
+	case configuration.NamespaceBasedEnricherProcessor:
+		return processors.NewNamespaceBasedEnricher(kubeClient)
+	case configuration.VolumeEnricherProcessor:
+		return processors.NewVolumeEnricher(kubeClient)
+	case configuration.PodAggregatorProcessor:
+		return processors.NewPodAggregator(), nil
+	case configuration.NamespaceAggregatorProcessor:
+		aggregations, err := aggregationFunctions(pc)
+		if err != nil {
+			return nil, err
+		}
+		return &processors.NamespaceAggregator{
+			MetricsToAggregate: aggregatedMetrics(pc, defaultMetricsToAggregate),
+			Aggregations:       aggregations,
+		}, nil
+	case configuration.NodeAggregatorProcessor:
+		aggregations, err := aggregationFunctions(pc)
+		if err != nil {
+			return nil, err
+		}
+		return &processors.NodeAggregator{
+			MetricsToAggregate: aggregatedMetrics(pc, defaultMetricsToAggregateForNode),
+			Aggregations:       aggregations,
+		}, nil
+	case configuration.ClusterAggregatorProcessor:
+		aggregations, err := aggregationFunctions(pc)
+		if err != nil {
+			return nil, err
+		}
+		return &processors.ClusterAggregator{
+			MetricsToAggregate: aggregatedMetrics(pc, defaultMetricsToAggregate),
+			Aggregations:       aggregations,
+		}, nil
+	case configuration.WorkloadAggregatorProcessor:
+		aggregations, err := aggregationFunctions(pc)
+		if err != nil {
+			return nil, err
+		}
+		return processors.NewWorkloadAggregator(kubeClient, podLister, aggregatedMetrics(pc, defaultMetricsToAggregate), aggregations)
+	case configuration.LabelAggregatorProcessor:
+		aggregations, err := aggregationFunctions(pc)
+		if err != nil {
+			return nil, err
+		}
+		return processors.NewLabelAggregator(*pc.Config.(*configuration.LabelAggregatorConfig),
+			aggregatedMetrics(pc, defaultMetricsToAggregate), aggregations, labelCopier)
+	case configuration.RightsizingProcessor:
+		return processors.NewRightsizingProcessor(*pc.Config.(*configuration.RightsizingConfig))
+	case configuration.CostProcessor:
+		return processors.NewCostProcessor(kubeClient, *pc.Config.(*configuration.CostConfig))
+	case configuration.NodeAutoscalingEnricherProcessor:
+		return processors.NewNodeAutoscalingEnricher(kubeClient, labelCopier)
+	case configuration.PointConverterProcessor:
+		return summary.NewPointConverter(*cfg.Sources.SummaryConfig, cluster)
+	case configuration.CounterConverterProcessor:
+		return processors.NewCounterConverter(*pc.Config.(*configuration.CounterConverterConfig))
+	case configuration.RelabelProcessor:
+		return processors.NewRelabelProcessor(*pc.Config.(*configuration.RelabelProcessorConfig))
+	}
+	return nil, fmt.Errorf("unknown processor")
+}
 
-	// aggregators
-	metricsToAggregate := []string{
+var (
+	defaultMetricsToAggregate = []string{
 		metrics.MetricCpuUsageRate.Name,
 		metrics.MetricMemoryUsage.Name,
 		metrics.MetricCpuRequest.Name,
 		metrics.MetricCpuLimit.Name,
 		metrics.MetricMemoryRequest.Name,
 		metrics.MetricMemoryLimit.Name,
+		metrics.MetricCostCpu.Name,
+		metrics.MetricCostMemory.Name,
+		metrics.MetricCostStorage.Name,
+		metrics.MetricCostTotal.Name,
 	}
 
-	metricsToAggregateForNode := []string{
+	defaultMetricsToAggregateForNode = []string{
 		metrics.MetricCpuRequest.Name,
 		metrics.MetricCpuLimit.Name,
 		metrics.MetricMemoryRequest.Name,
@@ -312,33 +415,30 @@ func createDataProcessorsOrDie(kubeClient *kube_client.Clientset, cluster string
 		metrics.MetricEphemeralStorageRequest.Name,
 		metrics.MetricEphemeralStorageLimit.Name,
 	}
+)
 
-	dataProcessors = append(dataProcessors,
-		processors.NewPodAggregator(),
-		&processors.NamespaceAggregator{
-			MetricsToAggregate: metricsToAggregate,
-		},
-		&processors.NodeAggregator{
-			MetricsToAggregate: metricsToAggregateForNode,
-		},
-		&processors.ClusterAggregator{
-			MetricsToAggregate: metricsToAggregate,
-		})
-
-	nodeAutoscalingEnricher, err := processors.NewNodeAutoscalingEnricher(kubeClient, labelCopier)
-	if err != nil {
-		log.Fatalf("Failed to create NodeAutoscalingEnricher: %v", err)
+func aggregatedMetrics(pc *configuration.ProcessorConfig, defaults []string) []string {
+	if c := aggregatorConfig(pc); c != nil && len(c.Metrics) > 0 {
+		return c.Metrics
 	}
-	dataProcessors = append(dataProcessors, nodeAutoscalingEnricher)
+	return defaults
+}
 
-	// this always needs to be the last processor
-	wavefrontCoverter, err := summary.NewPointConverter(cfg, cluster)
-	if err != nil {
-		log.Fatalf("Failed to create WavefrontPointConverter: %v", err)
+func aggregationFunctions(pc *configuration.ProcessorConfig) ([]processors.Aggregation, error) {
+	if c := aggregatorConfig(pc); c != nil {
+		return processors.NewAggregations(c.Aggregations)
 	}
-	dataProcessors = append(dataProcessors, wavefrontCoverter)
+	return nil, nil
+}
 
-	return dataProcessors
+func aggregatorConfig(pc *configuration.ProcessorConfig) *configuration.AggregatorConfig {
+	switch c := pc.Config.(type) {
+	case *configuration.AggregatorConfig:
+		return c
+	case *configuration.LabelAggregatorConfig:
+		return &c.AggregatorConfig
+	}
+	return nil
 }
 
 func getServiceListerOrDie(kubeClient *kube_client.Clientset) v1listers.ServiceLister {
@@ -370,7 +470,12 @@ func validateCfg(cfg *configuration.Config) error {
 	if len(cfg.Sinks) == 0 {
 		return fmt.Errorf("missing sink")
 	}
-	return nil
+	for _, pc := range cfg.Processors {
+		if pc.Enabled && pc.Name == configuration.PointConverterProcessor {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s processor is missing", configuration.PointConverterProcessor)
 }
 
 func setMaxProcs(opt *options.CollectorRunOptions) {
@@ -4,6 +4,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/discovery"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/events"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/manager"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources"
 )
@@ -11,12 +12,14 @@ import (
 type Agent struct {
 	pm manager.FlushManager
 	dm *discovery.Manager
+	er *events.EventRouter
 }
 
-func NewAgent(pm manager.FlushManager, dm *discovery.Manager) *Agent {
+func NewAgent(pm manager.FlushManager, dm *discovery.Manager, er *events.EventRouter) *Agent {
 	return &Agent{
 		pm: pm,
 		dm: dm,
+		er: er,
 	}
 }
 
@@ -32,6 +35,9 @@ func (a *Agent) Start() {
 	if a.dm != nil {
 		a.dm.Start()
 	}
+	if a.er != nil {
+		a.er.Start()
+	}
 }
 
 func (a *Agent) Stop() {
@@ -40,6 +46,9 @@ func (a *Agent) Stop() {
 	if a.dm != nil {
 		a.dm.Stop()
 	}
+	if a.er != nil {
+		a.er.Stop()
+	}
 	sources.Manager().StopProviders()
 	log.Infof("Agent stopped")
 }
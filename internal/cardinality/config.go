@@ -0,0 +1,26 @@
+package cardinality
+
+import "time"
+
+const (
+	Drop      = "drop"
+	Aggregate = "aggregate"
+)
+
+// Configuration for limiting the number of unique series reported by a source.
+type Config struct {
+	// The maximum number of unique series within the window. Disabled when 0.
+	MaxSeries int `yaml:"maxSeries"`
+
+	// Series that have not been reported for this long no longer count towards the budget. Defaults to 1 hour.
+	Window time.Duration `yaml:"window"`
+
+	// Either drop or aggregate. Defaults to drop.
+	// drop discards new series once the budget is exceeded. aggregate removes the offending tags from
+	// new series and sums the values of the resulting series.
+	Action string `yaml:"action"`
+
+	// The tags to remove from new series when aggregating.
+	// Defaults to the tag with the most unique values for the metric.
+	AggregateTags []string `yaml:"aggregateTags"`
+}
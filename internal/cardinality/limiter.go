@@ -0,0 +1,387 @@
+package cardinality
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gm "github.com/rcrowley/go-metrics"
+	log "github.com/sirupsen/logrus"
+	"github.com/wavefronthq/go-metrics-wavefront/reporting"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+const (
+	defaultWindow = time.Hour
+	logInterval   = 10 * time.Minute
+	topOffenders  = 5
+)
+
+type series struct {
+	metric   string
+	lastSeen time.Time
+}
+
+// tagged is the metric name and decoded tags of a point or distribution
+type tagged struct {
+	metric string
+	tags   map[string]string
+}
+
+type sharedLimiter struct {
+	limiter *Limiter
+	cfg     Config
+	refs    int
+}
+
+var (
+	sharedMtx sync.Mutex
+	shared    = make(map[string]*sharedLimiter)
+)
+
+// Limiter tracks the unique series reported by one or more sources over a sliding window and enforces the configured budget.
+type Limiter struct {
+	name          string
+	maxSeries     int
+	window        time.Duration
+	action        string
+	aggregateTags []string
+
+	mtx        sync.Mutex
+	series     map[string]series
+	lastLogged time.Time
+
+	active     gm.Gauge
+	dropped    gm.Counter
+	aggregated gm.Counter
+}
+
+// NewLimiter returns a limiter for the given source name. Returns nil if no budget is configured.
+// The tags are used for the internal metrics reported by the limiter.
+func NewLimiter(name string, cfg Config, tags map[string]string) (*Limiter, error) {
+	if cfg.MaxSeries <= 0 {
+		return nil, nil
+	}
+	action := cfg.Action
+	if action == "" {
+		action = Drop
+	}
+	if action != Drop && action != Aggregate {
+		return nil, fmt.Errorf("invalid cardinality action: %s", action)
+	}
+	window := cfg.Window
+	if window == 0 {
+		window = defaultWindow
+	}
+
+	return &Limiter{
+		name:          name,
+		maxSeries:     cfg.MaxSeries,
+		window:        window,
+		action:        action,
+		aggregateTags: cfg.AggregateTags,
+		series:        make(map[string]series),
+		active:        gm.GetOrRegisterGauge(reporting.EncodeKey("target.series.active", tags), gm.DefaultRegistry),
+		dropped:       gm.GetOrRegisterCounter(reporting.EncodeKey("target.series.dropped", tags), gm.DefaultRegistry),
+		aggregated:    gm.GetOrRegisterCounter(reporting.EncodeKey("target.series.aggregated", tags), gm.DefaultRegistry),
+	}, nil
+}
+
+// AcquireLimiter returns the limiter shared by the sources using the given name, creating it if it does not
+// exist or its configuration changed. Returns nil if no budget is configured. Release it with ReleaseLimiter.
+func AcquireLimiter(name string, cfg Config, tags map[string]string) (*Limiter, error) {
+	if cfg.MaxSeries <= 0 {
+		return nil, nil
+	}
+	sharedMtx.Lock()
+	defer sharedMtx.Unlock()
+
+	if s, found := shared[name]; found && reflect.DeepEqual(s.cfg, cfg) {
+		s.refs++
+		return s.limiter, nil
+	}
+	l, err := NewLimiter(name, cfg, tags)
+	if err != nil {
+		return nil, err
+	}
+	shared[name] = &sharedLimiter{limiter: l, cfg: cfg, refs: 1}
+	return l, nil
+}
+
+// ReleaseLimiter releases a limiter returned by AcquireLimiter. The limiter is discarded once no source uses it.
+func ReleaseLimiter(l *Limiter) {
+	if l == nil {
+		return
+	}
+	sharedMtx.Lock()
+	defer sharedMtx.Unlock()
+
+	if s, found := shared[l.name]; found && s.limiter == l {
+		s.refs--
+		if s.refs <= 0 {
+			delete(shared, l.name)
+		}
+	}
+}
+
+// Limit returns the points that fit within the budget. Points of known series are always retained.
+// Once the budget is exceeded, points of new series are either dropped or aggregated.
+func (l *Limiter) Limit(points []*metrics.MetricPoint, now time.Time) []*metrics.MetricPoint {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.evict(now)
+
+	result := points[:0]
+	var overflow []*metrics.MetricPoint
+	for _, point := range points {
+		key := seriesKey(point.Metric, point.StrTags, point.Tags)
+		if _, found := l.series[key]; found || len(l.series) < l.maxSeries {
+			l.series[key] = series{metric: point.Metric, lastSeen: now}
+			result = append(result, point)
+			continue
+		}
+		overflow = append(overflow, point)
+	}
+
+	if len(overflow) > 0 {
+		decoded := pointsTagged(overflow)
+		l.logOffenders(decoded, now)
+		if l.action == Aggregate {
+			aggregated := l.aggregate(overflow, decoded, now)
+			l.aggregated.Inc(int64(len(overflow)))
+			result = append(result, aggregated...)
+		} else {
+			l.dropped.Inc(int64(len(overflow)))
+		}
+	}
+	l.active.Update(int64(len(l.series)))
+	return result
+}
+
+// LimitDistributions returns the distributions that fit within the budget shared with the points.
+// Once the budget is exceeded, distributions of new series are either dropped or aggregated.
+func (l *Limiter) LimitDistributions(dists []*metrics.Distribution, now time.Time) []*metrics.Distribution {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.evict(now)
+
+	result := dists[:0]
+	var overflow []*metrics.Distribution
+	for _, dist := range dists {
+		key := seriesKey(dist.Metric, dist.StrTags, dist.Tags)
+		if _, found := l.series[key]; found || len(l.series) < l.maxSeries {
+			l.series[key] = series{metric: dist.Metric, lastSeen: now}
+			result = append(result, dist)
+			continue
+		}
+		overflow = append(overflow, dist)
+	}
+
+	if len(overflow) > 0 {
+		decoded := distributionsTagged(overflow)
+		l.logOffenders(decoded, now)
+		if l.action == Aggregate {
+			aggregated := l.aggregateDistributions(overflow, decoded, now)
+			l.aggregated.Inc(int64(len(overflow)))
+			result = append(result, aggregated...)
+		} else {
+			l.dropped.Inc(int64(len(overflow)))
+		}
+	}
+	l.active.Update(int64(len(l.series)))
+	return result
+}
+
+func (l *Limiter) evict(now time.Time) {
+	for key, s := range l.series {
+		if now.Sub(s.lastSeen) > l.window {
+			delete(l.series, key)
+		}
+	}
+}
+
+// aggregate removes the offending tags from the given points and sums the values of the resulting series.
+// The aggregated series are always retained and may exceed the budget.
+func (l *Limiter) aggregate(points []*metrics.MetricPoint, decoded []tagged, now time.Time) []*metrics.MetricPoint {
+	offending := l.offendingTags(decoded)
+
+	var result []*metrics.MetricPoint
+	aggregated := make(map[string]*metrics.MetricPoint)
+	for i, point := range points {
+		tags := decoded[i].tags
+		for _, k := range offending[point.Metric] {
+			delete(tags, k)
+		}
+
+		key := seriesKey(point.Metric, "", tags)
+		if existing, found := aggregated[key]; found {
+			existing.Value += point.Value
+			continue
+		}
+		p := &metrics.MetricPoint{
+			Metric:    point.Metric,
+			Value:     point.Value,
+			Timestamp: point.Timestamp,
+			Source:    point.Source,
+			Tags:      tags,
+		}
+		aggregated[key] = p
+		l.series[key] = series{metric: point.Metric, lastSeen: now}
+		result = append(result, p)
+	}
+	return result
+}
+
+// aggregateDistributions removes the offending tags from the given distributions and merges the centroids of the
+// resulting series. The aggregated series are always retained and may exceed the budget.
+func (l *Limiter) aggregateDistributions(dists []*metrics.Distribution, decoded []tagged, now time.Time) []*metrics.Distribution {
+	offending := l.offendingTags(decoded)
+
+	var result []*metrics.Distribution
+	aggregated := make(map[string]*metrics.Distribution)
+	for i, dist := range dists {
+		tags := decoded[i].tags
+		for _, k := range offending[dist.Metric] {
+			delete(tags, k)
+		}
+
+		key := seriesKey(dist.Metric, "", tags)
+		if existing, found := aggregated[key]; found {
+			existing.Centroids = append(existing.Centroids, dist.Centroids...)
+			continue
+		}
+		d := &metrics.Distribution{
+			Metric:        dist.Metric,
+			Centroids:     append([]metrics.Centroid(nil), dist.Centroids...),
+			Granularities: dist.Granularities,
+			Timestamp:     dist.Timestamp,
+			Source:        dist.Source,
+			Tags:          tags,
+		}
+		aggregated[key] = d
+		l.series[key] = series{metric: dist.Metric, lastSeen: now}
+		result = append(result, d)
+	}
+	return result
+}
+
+// offendingTags returns the tags to remove per metric name, either the configured tags or the tag with the most unique values.
+func (l *Limiter) offendingTags(overflow []tagged) map[string][]string {
+	if len(l.aggregateTags) == 0 {
+		return highestCardinalityTags(overflow)
+	}
+	result := make(map[string][]string)
+	for _, t := range overflow {
+		result[t.metric] = l.aggregateTags
+	}
+	return result
+}
+
+// highestCardinalityTags returns the tag with the most unique values per metric name.
+func highestCardinalityTags(overflow []tagged) map[string][]string {
+	values := make(map[string]map[string]map[string]bool)
+	for _, t := range overflow {
+		byTag, found := values[t.metric]
+		if !found {
+			byTag = make(map[string]map[string]bool)
+			values[t.metric] = byTag
+		}
+		for k, v := range t.tags {
+			if byTag[k] == nil {
+				byTag[k] = make(map[string]bool)
+			}
+			byTag[k][v] = true
+		}
+	}
+
+	result := make(map[string][]string, len(values))
+	for metric, byTag := range values {
+		highest, count := "", 0
+		for k, vals := range byTag {
+			if len(vals) > count || (len(vals) == count && k < highest) {
+				highest, count = k, len(vals)
+			}
+		}
+		if highest != "" {
+			result[metric] = []string{highest}
+		}
+	}
+	return result
+}
+
+func (l *Limiter) logOffenders(overflow []tagged, now time.Time) {
+	if now.Sub(l.lastLogged) < logInterval {
+		return
+	}
+	l.lastLogged = now
+
+	counts := make(map[string]int)
+	for _, s := range l.series {
+		counts[s.metric]++
+	}
+	for _, t := range overflow {
+		counts[t.metric]++
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return counts[names[i]] > counts[names[j]]
+	})
+	if len(names) > topOffenders {
+		names = names[:topOffenders]
+	}
+	offenders := make([]string, len(names))
+	for i, name := range names {
+		offenders[i] = fmt.Sprintf("%s=%d", name, counts[name])
+	}
+
+	log.WithFields(log.Fields{
+		"source":    l.name,
+		"maxSeries": l.maxSeries,
+		"overflow":  len(overflow),
+		"action":    l.action,
+		"offenders": strings.Join(offenders, ","),
+	}).Warning("series budget exceeded")
+}
+
+func pointsTagged(points []*metrics.MetricPoint) []tagged {
+	result := make([]tagged, len(points))
+	for i, point := range points {
+		result[i] = tagged{metric: point.Metric, tags: metrics.DecodeTags(point.Tags, point.StrTags)}
+	}
+	return result
+}
+
+func distributionsTagged(dists []*metrics.Distribution) []tagged {
+	result := make([]tagged, len(dists))
+	for i, dist := range dists {
+		result[i] = tagged{metric: dist.Metric, tags: metrics.DecodeTags(dist.Tags, dist.StrTags)}
+	}
+	return result
+}
+
+func seriesKey(metric, strTags string, tags map[string]string) string {
+	buf := bytes.NewBufferString(metric)
+	buf.WriteString(strTags)
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf.WriteString(" ")
+		buf.WriteString(k)
+		buf.WriteString("=")
+		buf.WriteString(tags[k])
+	}
+	return buf.String()
+}
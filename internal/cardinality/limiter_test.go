@@ -0,0 +1,143 @@
+package cardinality
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+func testPoints(metric string, from, to int) []*metrics.MetricPoint {
+	var points []*metrics.MetricPoint
+	for i := from; i < to; i++ {
+		points = append(points, &metrics.MetricPoint{
+			Metric:  metric,
+			Value:   1,
+			StrTags: fmt.Sprintf(" path=/%d code=200", i),
+		})
+	}
+	return points
+}
+
+func TestDisabled(t *testing.T) {
+	l, err := NewLimiter("test", Config{}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, l)
+
+	_, err = NewLimiter("test", Config{MaxSeries: 1, Action: "foo"}, nil)
+	assert.Error(t, err)
+}
+
+func TestDrop(t *testing.T) {
+	l, err := NewLimiter("drop", Config{MaxSeries: 10, Window: time.Minute}, nil)
+	require.NoError(t, err)
+	now := time.Now()
+
+	points := l.Limit(testPoints("requests", 0, 8), now)
+	assert.Equal(t, 8, len(points))
+
+	// known series are retained and new series beyond the budget dropped
+	points = l.Limit(testPoints("requests", 0, 20), now.Add(30*time.Second))
+	assert.Equal(t, 10, len(points))
+	assert.Equal(t, int64(10), l.active.Value())
+	assert.Equal(t, int64(10), l.dropped.Count())
+
+	// series expire once outside the window
+	points = l.Limit(testPoints("requests", 100, 110), now.Add(2*time.Minute))
+	assert.Equal(t, 10, len(points))
+	assert.Equal(t, " path=/100 code=200", points[0].StrTags)
+}
+
+func TestAggregate(t *testing.T) {
+	l, err := NewLimiter("aggregate", Config{MaxSeries: 5, Action: Aggregate}, nil)
+	require.NoError(t, err)
+	now := time.Now()
+
+	points := l.Limit(append(testPoints("requests", 0, 10), testPoints("errors", 0, 3)...), now)
+	require.Equal(t, 7, len(points))
+	// the path tag has the most unique values and is removed from the overflowing series
+	assert.Equal(t, "requests", points[5].Metric)
+	assert.Equal(t, 5.0, points[5].Value)
+	assert.Equal(t, map[string]string{"code": "200"}, points[5].Tags)
+	assert.Equal(t, "errors", points[6].Metric)
+	assert.Equal(t, 3.0, points[6].Value)
+	assert.Equal(t, int64(8), l.aggregated.Count())
+
+	// the aggregated series are tracked
+	points = l.Limit(testPoints("requests", 0, 10), now)
+	assert.Equal(t, 6, len(points))
+	assert.Equal(t, int64(7), l.active.Value())
+}
+
+func TestAggregateTags(t *testing.T) {
+	l, err := NewLimiter("aggregate_tags", Config{MaxSeries: 1, Action: Aggregate, AggregateTags: []string{"code"}}, nil)
+	require.NoError(t, err)
+
+	points := l.Limit(testPoints("requests", 0, 3), time.Now())
+	require.Equal(t, 3, len(points))
+	assert.Equal(t, map[string]string{"path": "/1"}, points[1].Tags)
+	assert.Equal(t, map[string]string{"path": "/2"}, points[2].Tags)
+}
+
+func testDistributions(metric string, from, to int) []*metrics.Distribution {
+	var dists []*metrics.Distribution
+	for i := from; i < to; i++ {
+		dists = append(dists, &metrics.Distribution{
+			Metric:    metric,
+			Centroids: []metrics.Centroid{{Value: float64(i), Count: 1}},
+			StrTags:   fmt.Sprintf(" path=/%d code=200", i),
+		})
+	}
+	return dists
+}
+
+func TestLimitDistributions(t *testing.T) {
+	l, err := NewLimiter("distributions", Config{MaxSeries: 5}, map[string]string{"test": "distributions"})
+	require.NoError(t, err)
+	now := time.Now()
+
+	// points and distributions share the budget
+	points := l.Limit(testPoints("requests", 0, 3), now)
+	assert.Equal(t, 3, len(points))
+	dists := l.LimitDistributions(testDistributions("latency", 0, 4), now)
+	assert.Equal(t, 2, len(dists))
+	assert.Equal(t, int64(2), l.dropped.Count())
+
+	l, err = NewLimiter("aggregate_distributions", Config{MaxSeries: 1, Action: Aggregate}, map[string]string{"test": "aggregate_distributions"})
+	require.NoError(t, err)
+	dists = l.LimitDistributions(testDistributions("latency", 0, 4), now)
+	require.Equal(t, 2, len(dists))
+	assert.Equal(t, map[string]string{"code": "200"}, dists[1].Tags)
+	assert.Equal(t, []metrics.Centroid{{Value: 1, Count: 1}, {Value: 2, Count: 1}, {Value: 3, Count: 1}}, dists[1].Centroids)
+	assert.Equal(t, int64(3), l.aggregated.Count())
+}
+
+func TestAcquireLimiter(t *testing.T) {
+	cfg := Config{MaxSeries: 10}
+	l, err := AcquireLimiter("shared", cfg, nil)
+	require.NoError(t, err)
+	shared, err := AcquireLimiter("shared", cfg, nil)
+	require.NoError(t, err)
+	assert.True(t, l == shared)
+
+	// a changed configuration replaces the limiter
+	changed, err := AcquireLimiter("shared", Config{MaxSeries: 20}, nil)
+	require.NoError(t, err)
+	assert.False(t, l == changed)
+
+	ReleaseLimiter(l)
+	ReleaseLimiter(shared)
+	ReleaseLimiter(changed)
+	l, err = AcquireLimiter("shared", Config{MaxSeries: 20}, nil)
+	require.NoError(t, err)
+	assert.False(t, l == changed)
+	ReleaseLimiter(l)
+
+	l, err = AcquireLimiter("disabled", Config{}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, l)
+}
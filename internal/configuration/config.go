@@ -3,9 +3,11 @@ package configuration
 import (
 	"time"
 
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/cardinality"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/discovery"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/httputil"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/relabel"
 )
 
 // The main configuration struct that drives the Wavefront collector
@@ -15,6 +17,9 @@ type Config struct {
 
 	DefaultCollectionInterval time.Duration `yaml:"defaultCollectionInterval"`
 
+	// whether collection and flushes happen at wall-clock multiples of their intervals. Defaults to false.
+	AlignCollectionIntervals bool `yaml:"alignCollectionIntervals"`
+
 	// the timeout for sinks to export data to Wavefront. Defaults to 20 seconds.
 	SinkExportDataTimeout time.Duration `yaml:"sinkExportDataTimeout"`
 
@@ -29,25 +34,33 @@ type Config struct {
 	// Included as a point tag on all metrics sent to Wavefront.
 	ClusterName string `yaml:"clusterName"`
 
-	// list of Wavefront sinks. At least 1 is required.
-	Sinks []*WavefrontSinkConfig `yaml:"sinks"`
+	// list of sinks. The type of each sink defaults to wavefront. At least 1 is required.
+	Sinks []*SinkConfig `yaml:"sinks"`
 
 	// list of sources. SummarySource is mandatory. Others are optional.
 	Sources *SourceConfig `yaml:"sources"`
 
 	DiscoveryConfigs []discovery.PluginConfig `yaml:"discovery_configs"`
 
+	// Ordered list of data processors. Defaults to the standard pipeline for the kubernetes source.
+	Processors []*ProcessorConfig `yaml:"processors"`
+
+	// Configuration for forwarding Kubernetes events to the sinks.
+	EventsConfig EventsConfig `yaml:"events"`
+
 	// Internal use only
 	Daemon bool `yaml:"-"`
 }
 
 // SourceConfig contains configuration for various sources
 type SourceConfig struct {
-	SummaryConfig     *SummaySourceConfig       `yaml:"kubernetes_source"`
-	PrometheusConfigs []*PrometheusSourceConfig `yaml:"prometheus_sources"`
-	TelegrafConfigs   []*TelegrafSourceConfig   `yaml:"telegraf_sources"`
-	SystemdConfig     *SystemdSourceConfig      `yaml:"systemd_source"`
-	StatsConfig       *StatsSourceConfig        `yaml:"internal_stats_source"`
+	SummaryConfig     *SummaySourceConfig          `yaml:"kubernetes_source"`
+	PrometheusConfigs []*PrometheusSourceConfig    `yaml:"prometheus_sources"`
+	TelegrafConfigs   []*TelegrafSourceConfig      `yaml:"telegraf_sources"`
+	SystemdConfig     *SystemdSourceConfig         `yaml:"systemd_source"`
+	StatsConfig       *StatsSourceConfig           `yaml:"internal_stats_source"`
+	StateConfig       *KubernetesStateSourceConfig `yaml:"kubernetes_state_source"`
+	ControlPlane      *ControlPlaneSourceConfig    `yaml:"control_plane_source"`
 }
 
 // Transforms represents transformations that can be applied to metrics at sources or sinks
@@ -69,6 +82,9 @@ type Transforms struct {
 type WavefrontSinkConfig struct {
 	Transforms `yaml:",inline"`
 
+	// Prometheus style relabeling rules applied before the filters.
+	Relabel []relabel.Config `yaml:"relabelConfigs"`
+
 	//  The Wavefront URL of the form https://YOUR_INSTANCE.wavefront.com. Only required for direct ingestion.
 	Server string `yaml:"server"`
 
@@ -81,6 +97,53 @@ type WavefrontSinkConfig struct {
 	// If set to true, metrics are emitted to stdout instead. Defaults to false.
 	TestMode bool `yaml:"testMode"`
 
+	// Optional disk buffer for points that could not be sent.
+	Buffer BufferConfig `yaml:"buffer"`
+
+	// cluster name pulled in from the top level property. Internal use only.
+	ClusterName string `yaml:"-"`
+}
+
+// Configuration options for the disk buffer of the Wavefront sink
+type BufferConfig struct {
+	// If set to true, points that fail to send are buffered on disk and retried. Defaults to false.
+	Enabled bool `yaml:"enabled"`
+
+	// The directory for the buffer files. Must be unique per sink. Defaults to /var/lib/wavefront-collector/buffer/<sink address>.
+	Dir string `yaml:"dir"`
+
+	// The maximum size of the buffer in megabytes. The oldest points are dropped once exceeded. Defaults to 256.
+	MaxSizeMB int64 `yaml:"maxSizeMB"`
+
+	// The maximum age of buffered points. Older points are dropped. Defaults to 24 hours.
+	MaxAge time.Duration `yaml:"maxAge"`
+
+	// The initial interval between retries. Doubled on every failed retry. Defaults to 5 seconds.
+	RetryInterval time.Duration `yaml:"retryInterval"`
+
+	// The maximum interval between retries. Defaults to 5 minutes.
+	MaxRetryInterval time.Duration `yaml:"maxRetryInterval"`
+}
+
+// Configuration options for a Prometheus remote write sink
+type PrometheusSinkConfig struct {
+	Transforms `yaml:",inline"`
+
+	// Prometheus style relabeling rules applied before the filters.
+	Relabel []relabel.Config `yaml:"relabelConfigs"`
+
+	// The remote write endpoint of the form http://cortex.default.svc.cluster.local/api/prom/push.
+	URL string `yaml:"url"`
+
+	// Optional HTTP client configuration.
+	HTTPClientConfig httputil.ClientConfig `yaml:"httpConfig"`
+
+	// The maximum number of series per remote write request. Defaults to 500.
+	BatchSize int `yaml:"batchSize"`
+
+	// The timeout for remote write requests. Defaults to 30 seconds.
+	Timeout time.Duration `yaml:"timeout"`
+
 	// cluster name pulled in from the top level property. Internal use only.
 	ClusterName string `yaml:"-"`
 }
@@ -88,6 +151,9 @@ type WavefrontSinkConfig struct {
 type CollectionConfig struct {
 	Interval time.Duration `yaml:"interval"`
 	Timeout  time.Duration `yaml:"timeout"`
+
+	// Maximum number of sources of a provider scraped in parallel. Defaults to 10.
+	Concurrency int `yaml:"concurrency"`
 }
 
 // Configuration options for the Kubernetes summary source
@@ -116,12 +182,23 @@ type SummaySourceConfig struct {
 
 	// If not using inClusterConfig, this can be set to a valid kubeConfig file provided using a config map.
 	Auth string `yaml:"auth"`
+
+	// Either "summary" (default), "cadvisor" or "resource". The cadvisor mode collects per container stats from the
+	// kubelet's raw container endpoint and the resource mode from the kubelet's /metrics/resource endpoint
+	// instead of the Summary API.
+	Mode string `yaml:"mode"`
+
+	// Defaults to false. Set to true to collect the container probe metrics from the kubelet's /metrics/probes endpoint.
+	Probes bool `yaml:"probes"`
 }
 
 // Configuration options for a Prometheus source
 type PrometheusSourceConfig struct {
 	Transforms `yaml:",inline"`
 
+	// Prometheus style relabeling rules applied before the filters.
+	Relabel []relabel.Config `yaml:"relabelConfigs"`
+
 	Collection CollectionConfig `yaml:"collection"`
 
 	// The URL for a Prometheus metrics endpoint. Kubernetes Service URLs work across namespaces.
@@ -130,9 +207,29 @@ type PrometheusSourceConfig struct {
 	// Optional HTTP client configuration.
 	HTTPClientConfig httputil.ClientConfig `yaml:"httpConfig"`
 
+	// If set to true, the timestamps provided by the exporter are used when present. Defaults to false.
+	HonorTimestamps bool `yaml:"honorTimestamps"`
+
+	// Optional conversion of histograms into Wavefront distributions.
+	Distributions DistributionConfig `yaml:"distributions"`
+
+	// Optional limit on the number of unique series reported by the source.
+	Cardinality cardinality.Config `yaml:"cardinality"`
+
 	// internal use only
 	Discovered string `yaml:"-"`
 	Name       string `yaml:"-"`
+	// the discovery rule whose series budget is shared by the source
+	Rule string `yaml:"-"`
+}
+
+// Configuration options for converting Prometheus histograms into Wavefront distributions
+type DistributionConfig struct {
+	// If set to true, histograms are sent as distributions instead of a point per bucket. Defaults to false.
+	Enabled bool `yaml:"enabled"`
+
+	// The granularities of the distributions: minute, hour and/or day. Defaults to minute.
+	Granularities []string `yaml:"granularities"`
 }
 
 // Configuration options for a Telegraf source
@@ -174,3 +271,70 @@ type StatsSourceConfig struct {
 
 	Collection CollectionConfig `yaml:"collection"`
 }
+
+// Configuration options for the source reporting the state of Kubernetes objects
+type KubernetesStateSourceConfig struct {
+	Transforms `yaml:",inline"`
+
+	Collection CollectionConfig `yaml:"collection"`
+}
+
+// Configuration options for the source scraping the Kubernetes control plane components
+type ControlPlaneSourceConfig struct {
+	Transforms `yaml:",inline"`
+
+	// Prometheus style relabeling rules applied before the filters.
+	Relabel []relabel.Config `yaml:"relabelConfigs"`
+
+	Collection CollectionConfig `yaml:"collection"`
+
+	// The control plane components to scrape. Defaults to the apiserver, scheduler and controller-manager.
+	Components []ControlPlaneComponentConfig `yaml:"components"`
+}
+
+// Configuration options for a single control plane component
+type ControlPlaneComponentConfig struct {
+	// One of apiserver, scheduler, controller-manager or etcd.
+	Name string `yaml:"name"`
+
+	// Optional list of metrics URLs. Disables locating the component within the cluster.
+	URLs []string `yaml:"urls"`
+
+	// The namespace of the static pods or endpoints of the component.
+	Namespace string `yaml:"namespace"`
+
+	// The label selector of the static pods of the component.
+	LabelSelector string `yaml:"labelSelector"`
+
+	// The name of the endpoints of the component. Takes precedence over the label selector.
+	Endpoints string `yaml:"endpoints"`
+
+	// The scheme, port and path of the metrics endpoint of the component.
+	Scheme string `yaml:"scheme"`
+	Port   int    `yaml:"port"`
+	Path   string `yaml:"path"`
+
+	// Optional HTTP client configuration. Replaces the default authentication of the component.
+	HTTPClientConfig httputil.ClientConfig `yaml:"httpConfig"`
+
+	// List of glob patterns excluding the prefix. Replaces the curated list of metrics of the component.
+	MetricWhitelist []string `yaml:"metricWhitelist"`
+}
+
+// Configuration options for forwarding Kubernetes events
+type EventsConfig struct {
+	// Whether Kubernetes events are forwarded. Defaults to false.
+	Enabled bool `yaml:"enabled"`
+
+	// Identical events for the same object are only forwarded once within this interval. Defaults to 5 minutes.
+	DedupeInterval time.Duration `yaml:"dedupeInterval"`
+
+	// The maximum number of events forwarded per minute. Defaults to 60.
+	MaxPerMinute int `yaml:"maxPerMinute"`
+
+	// Only events with tags matching the whitelist are forwarded.
+	TagWhitelist map[string][]string `yaml:"tagWhitelist"`
+
+	// Events with tags matching the blacklist are dropped.
+	TagBlacklist map[string][]string `yaml:"tagBlacklist"`
+}
@@ -1,9 +1,13 @@
-package configuration
+package configuration_test
 
 import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	// registers the sink configuration types
+	_ "github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sinks"
 )
 
 var sampleFile = `
@@ -26,6 +30,9 @@ sinks:
 
     tagInclude:
     - 'nodename'
+- type: prometheus
+  url: 'http://cortex.default.svc.cluster.local/api/prom/push'
+  batchSize: 100
 
 sources:
   kubernetes_source:
@@ -46,6 +53,17 @@ sources:
         interval: 1s
     - plugins: [mem]
 
+processors:
+- name: rate_calculator
+- name: pod_aggregator
+  enabled: false
+- name: cluster_aggregator
+  metrics: ['cpu/usage_rate']
+- name: point_converter
+- name: counter_converter
+  metrics: ['*.counter']
+  mode: delta
+
 discovery_configs:
   - type: telegraf/redis
     name: "redis"
@@ -63,7 +81,7 @@ discovery_configs:
 `
 
 func TestFromYAML(t *testing.T) {
-	cfg, err := FromYAML([]byte(sampleFile))
+	cfg, err := configuration.FromYAML([]byte(sampleFile))
 	if err != nil {
 		t.Errorf("error loading yaml: %q", err)
 		return
@@ -72,7 +90,102 @@ func TestFromYAML(t *testing.T) {
 		t.Errorf("invalid sinks")
 	}
 
+	assert.Equal(t, 2, len(cfg.Sinks))
+	assert.Equal(t, configuration.WavefrontSinkType, cfg.Sinks[0].Type)
+	wfCfg := cfg.Sinks[0].Config.(*configuration.WavefrontSinkConfig)
+	assert.Equal(t, "wavefront-proxy.default.svc.cluster.local:2878", wfCfg.ProxyAddress)
+	assert.Equal(t, "gcp-dev", wfCfg.Tags["env"])
+	assert.Equal(t, configuration.PrometheusSinkType, cfg.Sinks[1].Type)
+	promCfg := cfg.Sinks[1].Config.(*configuration.PrometheusSinkConfig)
+	assert.Equal(t, "http://cortex.default.svc.cluster.local/api/prom/push", promCfg.URL)
+	assert.Equal(t, 100, promCfg.BatchSize)
+
 	assert.True(t, len(cfg.Sources.PrometheusConfigs) > 0)
 	assert.Equal(t, "kubernetes.", cfg.Sources.SummaryConfig.Prefix)
 	assert.Equal(t, "kube.apiserver.", cfg.Sources.PrometheusConfigs[0].Prefix)
+
+	assert.Equal(t, 5, len(cfg.Processors))
+	assert.Equal(t, configuration.RateCalculatorProcessor, cfg.Processors[0].Name)
+	assert.True(t, cfg.Processors[0].Enabled)
+	assert.Nil(t, cfg.Processors[0].Config)
+	assert.False(t, cfg.Processors[1].Enabled)
+	assert.Equal(t, []string{"cpu/usage_rate"}, cfg.Processors[2].Config.(*configuration.AggregatorConfig).Metrics)
+	ccCfg := cfg.Processors[4].Config.(*configuration.CounterConverterConfig)
+	assert.Equal(t, "delta", ccCfg.Mode)
+}
+
+func TestInvalidSinks(t *testing.T) {
+	_, err := configuration.FromYAML([]byte("sinks:\n- type: unknown\n  url: 'http://localhost'\n"))
+	assert.Error(t, err)
+
+	_, err = configuration.FromYAML([]byte("sinks:\n- type: prometheus\n  proxyAddress: 'localhost:2878'\n"))
+	assert.Error(t, err)
+}
+
+func TestRelabelConfigs(t *testing.T) {
+	supported := []string{
+		"sinks:\n- proxyAddress: 'localhost:2878'\n  relabelConfigs:\n  - action: drop\n",
+		"sinks:\n- type: prometheus\n  url: 'http://localhost'\n  relabelConfigs:\n  - action: drop\n",
+		"sources:\n  prometheus_sources:\n  - url: 'http://localhost'\n    relabelConfigs:\n    - action: drop\n",
+		"sources:\n  control_plane_source:\n    relabelConfigs:\n    - action: drop\n",
+	}
+	for _, s := range supported {
+		_, err := configuration.FromYAML([]byte(s))
+		assert.NoError(t, err, s)
+	}
+
+	// rejected by the sources that would silently ignore the rules
+	unsupported := []string{
+		"sources:\n  kubernetes_source:\n    relabelConfigs:\n    - action: drop\n",
+		"sources:\n  kubernetes_state_source:\n    relabelConfigs:\n    - action: drop\n",
+		"sources:\n  systemd_source:\n    relabelConfigs:\n    - action: drop\n",
+		"sources:\n  internal_stats_source:\n    relabelConfigs:\n    - action: drop\n",
+		"sources:\n  telegraf_sources:\n  - plugins: [cpu]\n    relabelConfigs:\n    - action: drop\n",
+	}
+	for _, s := range unsupported {
+		_, err := configuration.FromYAML([]byte(s))
+		assert.Error(t, err, s)
+	}
+}
+
+func TestInvalidProcessors(t *testing.T) {
+	invalid := []string{
+		"processors:\n- name: unknown\n",
+		"processors:\n- enabled: true\n",
+		"processors:\n- name: rate_calculator\n  metrics: ['a']\n",
+		"processors:\n- name: node_aggregator\n  foo: bar\n",
+		"processors:\n- name: pod_aggregator\n  enabled: maybe\n",
+	}
+	for _, s := range invalid {
+		_, err := configuration.FromYAML([]byte(s))
+		assert.Error(t, err, s)
+	}
+}
+
+func TestLabelAggregatorConfig(t *testing.T) {
+	cfg, err := configuration.FromYAML([]byte(`
+processors:
+- name: label_aggregator
+  groupBy: ['label.team', 'label.env']
+  metrics: ['cpu/usage_rate']
+  aggregations:
+  - metric: 'cpu/usage_rate'
+    functions: ['max', 'p95']
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(cfg.Processors))
+	laCfg := cfg.Processors[0].Config.(*configuration.LabelAggregatorConfig)
+	assert.Equal(t, []string{"label.team", "label.env"}, laCfg.GroupBy)
+	assert.Equal(t, []string{"cpu/usage_rate"}, laCfg.Metrics)
+	assert.Equal(t, []string{"max", "p95"}, laCfg.Aggregations[0].Functions)
+}
+
+func TestDefaultProcessors(t *testing.T) {
+	processors := configuration.DefaultProcessors()
+	assert.Equal(t, configuration.RateCalculatorProcessor, processors[0].Name)
+	assert.Equal(t, configuration.VolumeEnricherProcessor, processors[2].Name)
+	assert.Equal(t, configuration.PointConverterProcessor, processors[len(processors)-1].Name)
+	for _, pc := range processors {
+		assert.True(t, pc.Enabled)
+	}
 }
@@ -0,0 +1,212 @@
+package configuration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/relabel"
+)
+
+const (
+	RateCalculatorProcessor          = "rate_calculator"
+	PodBasedEnricherProcessor        = "pod_based_enricher"
+	NamespaceBasedEnricherProcessor  = "namespace_based_enricher"
+	PodAggregatorProcessor           = "pod_aggregator"
+	NamespaceAggregatorProcessor     = "namespace_aggregator"
+	NodeAggregatorProcessor          = "node_aggregator"
+	ClusterAggregatorProcessor       = "cluster_aggregator"
+	WorkloadAggregatorProcessor      = "workload_aggregator"
+	LabelAggregatorProcessor         = "label_aggregator"
+	RightsizingProcessor             = "rightsizing"
+	CostProcessor                    = "cost"
+	VolumeEnricherProcessor          = "volume_enricher"
+	NodeAutoscalingEnricherProcessor = "node_autoscaling_enricher"
+	PointConverterProcessor          = "point_converter"
+	CounterConverterProcessor        = "counter_converter"
+	RelabelProcessor                 = "relabel"
+)
+
+var processorTypes = newTypeRegistry("processor", map[string]func() interface{}{
+	RateCalculatorProcessor:          nil,
+	PodBasedEnricherProcessor:        nil,
+	NamespaceBasedEnricherProcessor:  nil,
+	PodAggregatorProcessor:           nil,
+	NamespaceAggregatorProcessor:     func() interface{} { return &AggregatorConfig{} },
+	NodeAggregatorProcessor:          func() interface{} { return &AggregatorConfig{} },
+	ClusterAggregatorProcessor:       func() interface{} { return &AggregatorConfig{} },
+	WorkloadAggregatorProcessor:      func() interface{} { return &AggregatorConfig{} },
+	LabelAggregatorProcessor:         func() interface{} { return &LabelAggregatorConfig{} },
+	RightsizingProcessor:             func() interface{} { return &RightsizingConfig{} },
+	CostProcessor:                    func() interface{} { return &CostConfig{} },
+	VolumeEnricherProcessor:          nil,
+	NodeAutoscalingEnricherProcessor: nil,
+	PointConverterProcessor:          nil,
+	CounterConverterProcessor:        func() interface{} { return &CounterConverterConfig{} },
+	RelabelProcessor:                 func() interface{} { return &RelabelProcessorConfig{} },
+})
+
+// RegisterProcessorType registers the options type for a given processor name.
+// newConfig should return a pointer to a new zero value of the options or nil if the processor has no options.
+func RegisterProcessorType(name string, newConfig func() interface{}) {
+	processorTypes.register(name, newConfig)
+}
+
+// ProcessorConfig is a single entry in the processor pipeline. Processors run in the order they are listed.
+// The remaining properties are decoded into the options registered for the processor.
+type ProcessorConfig struct {
+	// The name of the processor.
+	Name string
+
+	// Whether the processor is enabled. Defaults to true.
+	Enabled bool
+
+	// Whether the processor also runs against batches without metric sets, such as the batches
+	// produced by the prometheus and telegraf sources. Defaults to false.
+	Points bool
+
+	// Pointer to the processor specific options or nil. For example *AggregatorConfig.
+	Config interface{}
+}
+
+// Options for the namespace, node, cluster and workload aggregators
+type AggregatorConfig struct {
+	// List of metrics to aggregate. Defaults vary by aggregator.
+	Metrics []string `yaml:"metrics"`
+
+	// List of functions applied to the pod values of a metric, in addition to summing the metrics above.
+	Aggregations []AggregationConfig `yaml:"aggregations"`
+}
+
+// AggregationConfig selects the functions applied to a metric by an aggregator
+type AggregationConfig struct {
+	// The name of the metric. For example cpu/usage_rate.
+	Metric string `yaml:"metric"`
+
+	// List of sum, min, max, avg, count or percentiles such as p95.
+	// The results are emitted with the function as suffix, for example cpu/usage_rate.p95.
+	Functions []string `yaml:"functions"`
+}
+
+// Options for the label aggregator
+type LabelAggregatorConfig struct {
+	AggregatorConfig `yaml:",inline"`
+
+	// Required: list of the labels to group by. Pod labels are referenced as label.<name>, such as label.team,
+	// the remaining names refer to the labels of the metric sets, such as namespace_name or nodename.
+	GroupBy []string `yaml:"groupBy"`
+
+	// The type of the metric sets to aggregate. Either pod or pod_container. Defaults to pod.
+	Type string `yaml:"type"`
+}
+
+// Options for the rightsizing processor
+type RightsizingConfig struct {
+	// The window of container usage the recommendations are based on. Defaults to 1 hour.
+	Window time.Duration `yaml:"window"`
+
+	// The percentile of the usage within the window recommended as request. Defaults to 95.
+	Percentile float64 `yaml:"percentile"`
+}
+
+// Options for the cost processor
+type CostConfig struct {
+	// The node label selecting the prices of a node. Defaults to beta.kubernetes.io/instance-type.
+	NodeLabel string `yaml:"nodeLabel"`
+
+	// Prices keyed by the value of the node label, for example the instance type.
+	Prices map[string]CostPrices `yaml:"prices"`
+
+	// Prices of the nodes without a matching entry in prices.
+	DefaultPrices CostPrices `yaml:"defaultPrices"`
+}
+
+// Hourly prices of the node resources
+type CostPrices struct {
+	// Price per cpu core hour.
+	Cpu float64 `yaml:"cpu"`
+
+	// Price per GiB hour of memory.
+	Memory float64 `yaml:"memory"`
+
+	// Price per GiB hour of ephemeral storage.
+	Storage float64 `yaml:"storage"`
+}
+
+// Options for converting cumulative counters into per second rates or Wavefront delta counters
+type CounterConverterConfig struct {
+	// List of glob patterns. Metrics with matching names are treated as cumulative counters.
+	Metrics []string `yaml:"metrics"`
+
+	// Either rate or delta. Defaults to rate.
+	Mode string `yaml:"mode"`
+
+	// Series that have not been reported for this long are evicted. Defaults to 10 minutes.
+	StaleAge time.Duration `yaml:"staleAge"`
+
+	// Whether to also send the original cumulative values. Defaults to false.
+	KeepOriginal bool `yaml:"keepOriginal"`
+}
+
+// Options for the relabel processor
+type RelabelProcessorConfig struct {
+	// Prometheus style relabeling rules applied to all metric points.
+	Relabel []relabel.Config `yaml:"relabelConfigs"`
+}
+
+// DefaultProcessors returns the processor pipeline used when none is configured.
+func DefaultProcessors() []*ProcessorConfig {
+	names := []string{
+		RateCalculatorProcessor,
+		PodBasedEnricherProcessor,
+		VolumeEnricherProcessor,
+		NamespaceBasedEnricherProcessor,
+		PodAggregatorProcessor,
+		NamespaceAggregatorProcessor,
+		NodeAggregatorProcessor,
+		ClusterAggregatorProcessor,
+		NodeAutoscalingEnricherProcessor,
+		PointConverterProcessor,
+	}
+	result := make([]*ProcessorConfig, len(names))
+	for i, name := range names {
+		config, _ := processorTypes.newConfig(name)
+		result[i] = &ProcessorConfig{Name: name, Enabled: true, Config: config}
+	}
+	return result
+}
+
+func (pc *ProcessorConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var props map[string]interface{}
+	if err := unmarshal(&props); err != nil {
+		return err
+	}
+
+	name, ok := props["name"]
+	if !ok {
+		return fmt.Errorf("processor name is missing")
+	}
+	pc.Name = fmt.Sprint(name)
+	delete(props, "name")
+
+	pc.Enabled = true
+	if enabled, ok := props["enabled"]; ok {
+		if pc.Enabled, ok = enabled.(bool); !ok {
+			return fmt.Errorf("invalid enabled value for processor %s: %v", pc.Name, enabled)
+		}
+		delete(props, "enabled")
+	}
+	if points, ok := props["points"]; ok {
+		if pc.Points, ok = points.(bool); !ok {
+			return fmt.Errorf("invalid points value for processor %s: %v", pc.Name, points)
+		}
+		delete(props, "points")
+	}
+
+	// decode the remaining properties into the processor specific options
+	config, err := processorTypes.decode(pc.Name, props)
+	if err != nil {
+		return err
+	}
+	pc.Config = config
+	return nil
+}
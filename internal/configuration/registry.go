@@ -0,0 +1,65 @@
+package configuration
+
+import (
+	"fmt"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// typeRegistry maps names, such as sink types or processor names, to the configuration types decoded for them.
+type typeRegistry struct {
+	kind  string
+	mtx   sync.RWMutex
+	types map[string]func() interface{}
+}
+
+func newTypeRegistry(kind string, types map[string]func() interface{}) *typeRegistry {
+	return &typeRegistry{kind: kind, types: types}
+}
+
+// register registers the configuration type for a given name.
+// newConfig should return a pointer to a new zero value of the configuration or nil if there are no options.
+func (r *typeRegistry) register(name string, newConfig func() interface{}) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.types[name] = newConfig
+}
+
+// newConfig returns a new configuration for the given name or nil if there are no options.
+func (r *typeRegistry) newConfig(name string) (interface{}, error) {
+	r.mtx.RLock()
+	newConfig, found := r.types[name]
+	r.mtx.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("unknown %s: %s", r.kind, name)
+	}
+	if newConfig == nil {
+		return nil, nil
+	}
+	return newConfig(), nil
+}
+
+// decode decodes the given properties into a new configuration for the given name.
+// Returns nil if there are no options.
+func (r *typeRegistry) decode(name string, props map[string]interface{}) (interface{}, error) {
+	cfg, err := r.newConfig(name)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		if len(props) > 0 {
+			return nil, fmt.Errorf("%s %s does not support options", r.kind, name)
+		}
+		return nil, nil
+	}
+
+	contents, err := yaml.Marshal(props)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(contents, cfg); err != nil {
+		return nil, fmt.Errorf("invalid %s %s configuration: %v", name, r.kind, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,69 @@
+package configuration
+
+import (
+	"fmt"
+)
+
+const (
+	WavefrontSinkType  = "wavefront"
+	PrometheusSinkType = "prometheus"
+)
+
+var sinkTypes = newTypeRegistry("sink", make(map[string]func() interface{}))
+
+// RegisterSinkType registers the configuration type for a given sink type. Called when registering
+// the factory of a sink, see sinks.Register. newConfig should return a pointer to a new zero value of the configuration.
+func RegisterSinkType(sinkType string, newConfig func() interface{}) {
+	sinkTypes.register(sinkType, newConfig)
+}
+
+// SinkConfig is a single entry in the list of sinks. The type property selects the kind of sink
+// and the remaining properties are decoded into the configuration registered for that type.
+type SinkConfig struct {
+	// The type of the sink. Defaults to wavefront.
+	Type string
+
+	// Pointer to the configuration specific to the sink type. For example *WavefrontSinkConfig.
+	Config interface{}
+}
+
+// ClusterNameSetter is implemented by sink configurations that include the top level cluster name.
+type ClusterNameSetter interface {
+	SetClusterName(name string)
+}
+
+// SetClusterName sets the cluster name on the underlying sink configuration if supported.
+func (sc *SinkConfig) SetClusterName(name string) {
+	if setter, ok := sc.Config.(ClusterNameSetter); ok {
+		setter.SetClusterName(name)
+	}
+}
+
+func (sc *SinkConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var props map[string]interface{}
+	if err := unmarshal(&props); err != nil {
+		return err
+	}
+
+	sc.Type = WavefrontSinkType
+	if t, ok := props["type"]; ok {
+		sc.Type = fmt.Sprint(t)
+		delete(props, "type")
+	}
+
+	// decode the remaining properties into the type specific configuration
+	config, err := sinkTypes.decode(sc.Type, props)
+	if err != nil {
+		return err
+	}
+	sc.Config = config
+	return nil
+}
+
+func (cfg *WavefrontSinkConfig) SetClusterName(name string) {
+	cfg.ClusterName = name
+}
+
+func (cfg *PrometheusSinkConfig) SetClusterName(name string) {
+	cfg.ClusterName = name
+}
@@ -3,6 +3,7 @@ package discovery
 import (
 	"time"
 
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/cardinality"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
 )
 
@@ -62,6 +63,9 @@ type PluginConfig struct {
 
 	Filters    filter.Config    `yaml:"filters"`
 	Collection CollectionConfig `yaml:"collection"`
+
+	// optional limit on the number of unique series reported per discovered target. Only supported by prometheus rules.
+	Cardinality cardinality.Config `yaml:"cardinality"`
 }
 
 type CollectionConfig struct {
@@ -0,0 +1,26 @@
+// Package events contains the types shared by the Kubernetes event router and the sinks
+package events
+
+import "time"
+
+const (
+	SeverityInfo   = "info"
+	SeverityWarn   = "warn"
+	SeveritySevere = "severe"
+)
+
+// Event represents a single Kubernetes event in Wavefront event format.
+type Event struct {
+	Name      string
+	Message   string
+	Timestamp time.Time
+	Source    string
+	Severity  string
+	Type      string
+	Tags      map[string]string
+}
+
+// EventSink is implemented by sinks that can export events.
+type EventSink interface {
+	ExportEvent(*Event)
+}
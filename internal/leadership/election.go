@@ -23,7 +23,7 @@ var (
 	leadingGauge  metrics.Gauge
 
 	// leadership state
-	subscribers []chan<- bool
+	subscribers []chan bool
 	lock        sync.RWMutex
 	started     bool
 	isLeader    bool
@@ -54,15 +54,32 @@ func Subscribe(client v1.CoreV1Interface) (<-chan bool, error) {
 	return ch, nil
 }
 
-func Unsubscribe() {
+// Unsubscribe stops notifying the given subscriber channel of election results
+func Unsubscribe(ch <-chan bool) {
 	lock.Lock()
 	defer lock.Unlock()
 
-	// assumes a single subscriber at this time
-	subscribers = subscribers[:0]
+	for i := range subscribers {
+		if subscribers[i] == ch {
+			subscribers = append(subscribers[:i], subscribers[i+1:]...)
+			break
+		}
+	}
 	log.Infof("unsubscribed from leader-election: %d", len(subscribers))
 }
 
+// notify sends the election result without blocking, replacing a previous result the subscriber has not received yet
+func notify(ch chan bool, leading bool) {
+	select {
+	case <-ch:
+	default:
+	}
+	select {
+	case ch <- leading:
+	default:
+	}
+}
+
 // startLeaderElection starts the election process if not already started
 // this will only be done once per collector instance
 func startLeaderElection(client v1.CoreV1Interface) error {
@@ -120,13 +137,14 @@ func getLeaderElector(client v1.CoreV1Interface) (*leaderelection.LeaderElector,
 				leaderId = identity
 				if identity == nodeName && !isLeader {
 					for i := range subscribers {
-						subscribers[i] <- true
+						notify(subscribers[i], true)
 					}
 					isLeader = true
 				} else if identity != nodeName && isLeader {
 					for i := range subscribers {
-						subscribers[i] <- false
+						notify(subscribers[i], false)
 					}
+					isLeader = false
 				}
 			},
 		},
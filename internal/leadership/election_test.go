@@ -0,0 +1,27 @@
+package leadership
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifyDoesNotBlock(t *testing.T) {
+	ch := make(chan bool, 1)
+	notify(ch, true)
+	notify(ch, false)
+	assert.False(t, <-ch, "latest result not received")
+	assert.Empty(t, ch)
+}
+
+func TestUnsubscribe(t *testing.T) {
+	ch1 := make(chan bool, 1)
+	ch2 := make(chan bool, 1)
+	subscribers = []chan bool{ch1, ch2}
+	defer func() { subscribers = nil }()
+
+	Unsubscribe(ch1)
+	assert.Equal(t, []chan bool{ch2}, subscribers)
+	Unsubscribe(ch1)
+	assert.Equal(t, []chan bool{ch2}, subscribers)
+}
@@ -19,7 +19,7 @@ package metrics
 var (
 	LabelMetricSetType = LabelDescriptor{
 		Key:         "type",
-		Description: "Type of the metrics set (container, pod, namespace, node, cluster)",
+		Description: "Type of the metrics set (container, pod, namespace, node, cluster, workload, group)",
 	}
 	MetricSetTypeSystemContainer = "sys_container"
 	MetricSetTypePodContainer    = "pod_container"
@@ -27,6 +27,8 @@ var (
 	MetricSetTypeNamespace       = "ns"
 	MetricSetTypeNode            = "node"
 	MetricSetTypeCluster         = "cluster"
+	MetricSetTypeWorkload        = "workload"
+	MetricSetTypeGroup           = "group"
 
 	LabelPodId = LabelDescriptor{
 		Key:         "pod_id",
@@ -93,6 +95,18 @@ var (
 		Key:         "volume_name",
 		Description: "The name of the volume.",
 	}
+	LabelPVCName = LabelDescriptor{
+		Key:         "pvc_name",
+		Description: "The name of the persistent volume claim backing the volume.",
+	}
+	LabelPVName = LabelDescriptor{
+		Key:         "pv_name",
+		Description: "The name of the persistent volume bound to the claim.",
+	}
+	LabelStorageClass = LabelDescriptor{
+		Key:         "storage_class",
+		Description: "The storage class of the persistent volume.",
+	}
 	LabelAcceleratorMake = LabelDescriptor{
 		Key:         "make",
 		Description: "Make of the accelerator (nvidia, amd, google etc.)",
@@ -105,6 +119,22 @@ var (
 		Key:         "accelerator_id",
 		Description: "ID of the accelerator",
 	}
+	LabelWorkloadKind = LabelDescriptor{
+		Key:         "workload_kind",
+		Description: "The kind of the workload owning the pod (Deployment, StatefulSet, DaemonSet, Job, CronJob etc.)",
+	}
+	LabelWorkloadName = LabelDescriptor{
+		Key:         "workload_name",
+		Description: "The name of the workload owning the pod",
+	}
+	LabelProbeType = LabelDescriptor{
+		Key:         "probe_type",
+		Description: "Type of the container probe (liveness, readiness or startup)",
+	}
+	LabelProbeResult = LabelDescriptor{
+		Key:         "result",
+		Description: "Result of the container probe (successful, failed or unknown)",
+	}
 )
 
 type LabelDescriptor struct {
@@ -147,6 +177,11 @@ var acceleratorLabels = []LabelDescriptor{
 	LabelAcceleratorID,
 }
 
+var probeLabels = []LabelDescriptor{
+	LabelProbeType,
+	LabelProbeResult,
+}
+
 // Labels exported to GCM. The number of labels that can be exported to GCM is limited by 10.
 var gcmLabels = []LabelDescriptor{
 	LabelMetricSetType,
@@ -31,6 +31,9 @@ var StandardMetrics = []Metric{
 	MetricUptime,
 	MetricCpuUsage,
 	MetricCpuLoad,
+	MetricCpuCfsPeriods,
+	MetricCpuCfsThrottledPeriods,
+	MetricCpuCfsThrottledTime,
 	MetricEphemeralStorageUsage,
 	MetricMemoryUsage,
 	MetricMemoryRSS,
@@ -38,10 +41,14 @@ var StandardMetrics = []Metric{
 	MetricMemoryWorkingSet,
 	MetricMemoryPageFaults,
 	MetricMemoryMajorPageFaults,
+	MetricMemoryFailcnt,
 	MetricNetworkRx,
 	MetricNetworkRxErrors,
 	MetricNetworkTx,
-	MetricNetworkTxErrors}
+	MetricNetworkTxErrors,
+	MetricProcessCount,
+	MetricProcessRunning,
+	MetricProcessMaxPid}
 
 // Metrics computed based on cluster state using Kubernetes API.
 var AdditionalMetrics = []Metric{
@@ -88,6 +95,9 @@ var LabeledMetrics = []Metric{
 	MetricAcceleratorMemoryTotal,
 	MetricAcceleratorMemoryUsed,
 	MetricAcceleratorDutyCycle,
+	MetricProbeTotal,
+	MetricProbeDurationSum,
+	MetricProbeDurationCount,
 }
 
 var NodeAutoscalingMetrics = []Metric{
@@ -105,16 +115,42 @@ var NodeAutoscalingMetrics = []Metric{
 	MetricNodeEphemeralStorageReservation,
 }
 
+var RightsizingMetrics = []Metric{
+	MetricCpuRequestUtilization,
+	MetricCpuLimitUtilization,
+	MetricCpuRecommendedRequest,
+	MetricMemoryRequestUtilization,
+	MetricMemoryLimitUtilization,
+	MetricMemoryRecommendedRequest,
+	MetricRequestsMissing,
+	MetricLimitsMissing,
+}
+
+var CostMetrics = []Metric{
+	MetricCostCpu,
+	MetricCostMemory,
+	MetricCostStorage,
+	MetricCostTotal,
+	MetricCostNodeTotal,
+	MetricCostNodeIdle,
+}
+
 var CpuMetrics = []Metric{
 	MetricCpuLimit,
 	MetricCpuRequest,
 	MetricCpuUsage,
 	MetricCpuLoad,
+	MetricCpuCfsPeriods,
+	MetricCpuCfsThrottledPeriods,
+	MetricCpuCfsThrottledTime,
 	MetricCpuUsageRate,
 	MetricNodeCpuAllocatable,
 	MetricNodeCpuCapacity,
 	MetricNodeCpuReservation,
 	MetricNodeCpuUtilization,
+	MetricCpuRequestUtilization,
+	MetricCpuLimitUtilization,
+	MetricCpuRecommendedRequest,
 }
 var FilesystemMetrics = []Metric{
 	MetricFilesystemAvailable,
@@ -125,6 +161,7 @@ var FilesystemMetrics = []Metric{
 }
 var MemoryMetrics = []Metric{
 	MetricMemoryLimit,
+	MetricMemoryFailcnt,
 	MetricMemoryMajorPageFaults,
 	MetricMemoryMajorPageFaultsRate,
 	MetricMemoryPageFaults,
@@ -138,6 +175,9 @@ var MemoryMetrics = []Metric{
 	MetricNodeMemoryCapacity,
 	MetricNodeMemoryUtilization,
 	MetricNodeMemoryReservation,
+	MetricMemoryRequestUtilization,
+	MetricMemoryLimitUtilization,
+	MetricMemoryRecommendedRequest,
 }
 var NetworkMetrics = []Metric{
 	MetricNetworkRx,
@@ -187,8 +227,8 @@ func MetricFamilyForName(metricName string) MetricFamily {
 	return MetricFamilyGeneral
 }
 
-var AllMetrics = append(append(append(append(StandardMetrics, AdditionalMetrics...), RateMetrics...), LabeledMetrics...),
-	NodeAutoscalingMetrics...)
+var AllMetrics = append(append(append(append(append(append(StandardMetrics, AdditionalMetrics...), RateMetrics...), LabeledMetrics...),
+	NodeAutoscalingMetrics...), RightsizingMetrics...), CostMetrics...)
 
 // Definition of Standard Metrics.
 var MetricUptime = Metric{
@@ -258,6 +298,63 @@ var MetricCpuUsage = Metric{
 	},
 }
 
+var MetricCpuCfsPeriods = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "cpu/cfs/periods",
+		Description: "Cumulative number of elapsed CFS enforcement periods",
+		Type:        MetricCumulative,
+		ValueType:   ValueInt64,
+		Units:       UnitsCount,
+	},
+	HasValue: func(spec *cadvisor.ContainerSpec) bool {
+		return spec.HasCpu
+	},
+	GetValue: func(spec *cadvisor.ContainerSpec, stat *cadvisor.ContainerStats) MetricValue {
+		return MetricValue{
+			ValueType:  ValueInt64,
+			MetricType: MetricCumulative,
+			IntValue:   int64(stat.Cpu.CFS.Periods)}
+	},
+}
+
+var MetricCpuCfsThrottledPeriods = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "cpu/cfs/throttled_periods",
+		Description: "Cumulative number of CFS enforcement periods in which the container was throttled",
+		Type:        MetricCumulative,
+		ValueType:   ValueInt64,
+		Units:       UnitsCount,
+	},
+	HasValue: func(spec *cadvisor.ContainerSpec) bool {
+		return spec.HasCpu
+	},
+	GetValue: func(spec *cadvisor.ContainerSpec, stat *cadvisor.ContainerStats) MetricValue {
+		return MetricValue{
+			ValueType:  ValueInt64,
+			MetricType: MetricCumulative,
+			IntValue:   int64(stat.Cpu.CFS.ThrottledPeriods)}
+	},
+}
+
+var MetricCpuCfsThrottledTime = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "cpu/cfs/throttled_time",
+		Description: "Cumulative time the container was throttled",
+		Type:        MetricCumulative,
+		ValueType:   ValueInt64,
+		Units:       UnitsNanoseconds,
+	},
+	HasValue: func(spec *cadvisor.ContainerSpec) bool {
+		return spec.HasCpu
+	},
+	GetValue: func(spec *cadvisor.ContainerSpec, stat *cadvisor.ContainerStats) MetricValue {
+		return MetricValue{
+			ValueType:  ValueInt64,
+			MetricType: MetricCumulative,
+			IntValue:   int64(stat.Cpu.CFS.ThrottledTime)}
+	},
+}
+
 var MetricEphemeralStorageUsage = Metric{
 	MetricDescriptor: MetricDescriptor{
 		Name:        "ephemeral_storage/usage",
@@ -267,6 +364,36 @@ var MetricEphemeralStorageUsage = Metric{
 		Units:       UnitsBytes,
 	},
 }
+var MetricProcessCount = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "process/count",
+		Description: "Number of processes of a pod",
+		Type:        MetricGauge,
+		ValueType:   ValueInt64,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricProcessRunning = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "process/running",
+		Description: "Number of running processes (threads on Linux) of a node",
+		Type:        MetricGauge,
+		ValueType:   ValueInt64,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricProcessMaxPid = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "process/max_pid",
+		Description: "Maximum number of processes (threads on Linux) of a node",
+		Type:        MetricGauge,
+		ValueType:   ValueInt64,
+		Units:       UnitsCount,
+	},
+}
+
 var MetricMemoryUsage = Metric{
 	MetricDescriptor: MetricDescriptor{
 		Name:        "memory/usage",
@@ -381,6 +508,25 @@ var MetricMemoryMajorPageFaults = Metric{
 	},
 }
 
+var MetricMemoryFailcnt = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "memory/failcnt",
+		Description: "Number of times the memory usage hit the limit",
+		Type:        MetricCumulative,
+		ValueType:   ValueInt64,
+		Units:       UnitsCount,
+	},
+	HasValue: func(spec *cadvisor.ContainerSpec) bool {
+		return spec.HasMemory
+	},
+	GetValue: func(spec *cadvisor.ContainerSpec, stat *cadvisor.ContainerStats) MetricValue {
+		return MetricValue{
+			ValueType:  ValueInt64,
+			MetricType: MetricCumulative,
+			IntValue:   int64(stat.Memory.Failcnt)}
+	},
+}
+
 var MetricNetworkRx = Metric{
 	MetricDescriptor: MetricDescriptor{
 		Name:        "network/rx",
@@ -1105,6 +1251,179 @@ var MetricDiskIOWriteRate = Metric{
 	},
 }
 
+var MetricProbeTotal = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "probe/total",
+		Description: "Cumulative number of container probes by probe type and result",
+		Type:        MetricCumulative,
+		ValueType:   ValueInt64,
+		Units:       UnitsCount,
+		Labels:      probeLabels,
+	},
+}
+
+var MetricProbeDurationSum = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "probe/duration_seconds_sum",
+		Description: "Cumulative duration of container probes by probe type in seconds",
+		Type:        MetricCumulative,
+		ValueType:   ValueFloat,
+		Units:       UnitsCount,
+		Labels:      probeLabels,
+	},
+}
+
+var MetricProbeDurationCount = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "probe/duration_seconds_count",
+		Description: "Cumulative number of timed container probes by probe type",
+		Type:        MetricCumulative,
+		ValueType:   ValueInt64,
+		Units:       UnitsCount,
+		Labels:      probeLabels,
+	},
+}
+
+var MetricCpuRequestUtilization = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "cpu/request_utilization",
+		Description: "Cpu usage as a share of the cpu request",
+		Type:        MetricGauge,
+		ValueType:   ValueFloat,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricCpuLimitUtilization = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "cpu/limit_utilization",
+		Description: "Cpu usage as a share of the cpu limit",
+		Type:        MetricGauge,
+		ValueType:   ValueFloat,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricCpuRecommendedRequest = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "cpu/recommended_request",
+		Description: "Recommended cpu request in millicores, a percentile of the cpu usage over the rightsizing window",
+		Type:        MetricGauge,
+		ValueType:   ValueInt64,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricMemoryRequestUtilization = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "memory/request_utilization",
+		Description: "Memory working set as a share of the memory request",
+		Type:        MetricGauge,
+		ValueType:   ValueFloat,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricMemoryLimitUtilization = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "memory/limit_utilization",
+		Description: "Memory working set as a share of the memory limit",
+		Type:        MetricGauge,
+		ValueType:   ValueFloat,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricMemoryRecommendedRequest = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "memory/recommended_request",
+		Description: "Recommended memory request in bytes, a percentile of the memory working set over the rightsizing window",
+		Type:        MetricGauge,
+		ValueType:   ValueInt64,
+		Units:       UnitsBytes,
+	},
+}
+
+var MetricRequestsMissing = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "resources/requests_missing",
+		Description: "1 if the container has no cpu or no memory request, 0 otherwise",
+		Type:        MetricGauge,
+		ValueType:   ValueInt64,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricLimitsMissing = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "resources/limits_missing",
+		Description: "1 if the container has no cpu or no memory limit, 0 otherwise",
+		Type:        MetricGauge,
+		ValueType:   ValueInt64,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricCostCpu = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "cost/cpu",
+		Description: "Hourly cost of the cpu allocated to the pod by the max of its request and usage",
+		Type:        MetricGauge,
+		ValueType:   ValueFloat,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricCostMemory = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "cost/memory",
+		Description: "Hourly cost of the memory allocated to the pod by the max of its request and working set",
+		Type:        MetricGauge,
+		ValueType:   ValueFloat,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricCostStorage = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "cost/storage",
+		Description: "Hourly cost of the ephemeral storage allocated to the pod by the max of its request and usage",
+		Type:        MetricGauge,
+		ValueType:   ValueFloat,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricCostTotal = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "cost/total",
+		Description: "Hourly cost of the cpu, memory and ephemeral storage allocated to the pod",
+		Type:        MetricGauge,
+		ValueType:   ValueFloat,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricCostNodeTotal = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "cost/node_total",
+		Description: "Hourly cost of the capacity of the node",
+		Type:        MetricGauge,
+		ValueType:   ValueFloat,
+		Units:       UnitsCount,
+	},
+}
+
+var MetricCostNodeIdle = Metric{
+	MetricDescriptor: MetricDescriptor{
+		Name:        "cost/node_idle",
+		Description: "Hourly cost of the node capacity not allocated to pods",
+		Type:        MetricGauge,
+		ValueType:   ValueFloat,
+		Units:       UnitsCount,
+	},
+}
+
 func IsNodeAutoscalingMetric(name string) bool {
 	for _, autoscalingMetric := range NodeAutoscalingMetrics {
 		if autoscalingMetric.MetricDescriptor.Name == name {
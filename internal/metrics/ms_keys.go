@@ -16,6 +16,7 @@ package metrics
 
 import (
 	"fmt"
+	"strings"
 )
 
 // MetricsSet keys are inside of DataBatch. The structure of the returned string is
@@ -35,6 +36,19 @@ func NamespaceKey(namespace string) string {
 	return fmt.Sprintf("namespace:%s", namespace)
 }
 
+func WorkloadKey(namespace, kind, name string) string {
+	return fmt.Sprintf("namespace:%s/%s:%s", namespace, kind, name)
+}
+
+// GroupKey returns the key of a label aggregation group. labels holds the group label values keyed by label name.
+func GroupKey(groupBy []string, labels map[string]string) string {
+	parts := make([]string, len(groupBy))
+	for i, name := range groupBy {
+		parts[i] = fmt.Sprintf("%s:%s", name, labels[name])
+	}
+	return "group:" + strings.Join(parts, "/")
+}
+
 func NodeKey(node string) string {
 	return fmt.Sprintf("node:%s", node)
 }
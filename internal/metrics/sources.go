@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"context"
+)
+
+// UncancellableMetricsSource is a source whose scrapes cannot be cancelled, such as a source
+// reporting in-memory state. Use NewMetricsSourceAdapter to register it as a MetricsSource.
+type UncancellableMetricsSource interface {
+	Name() string
+	ScrapeMetrics() (*DataBatch, error)
+}
+
+type metricsSourceAdapter struct {
+	src UncancellableMetricsSource
+}
+
+type scrapeResult struct {
+	dataBatch *DataBatch
+	err       error
+}
+
+// NewMetricsSourceAdapter adapts a source scraped without a context to the MetricsSource interface.
+// Once the context is done the pending scrape is abandoned rather than interrupted.
+func NewMetricsSourceAdapter(src UncancellableMetricsSource) MetricsSource {
+	return &metricsSourceAdapter{src: src}
+}
+
+func (a *metricsSourceAdapter) Name() string {
+	return a.src.Name()
+}
+
+func (a *metricsSourceAdapter) ScrapeMetrics(ctx context.Context) (*DataBatch, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// buffered so the scrape goroutine does not leak when the context is done first
+	results := make(chan scrapeResult, 1)
+	go func() {
+		dataBatch, err := a.src.ScrapeMetrics()
+		results <- scrapeResult{dataBatch: dataBatch, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		return result.dataBatch, result.err
+	}
+}
@@ -20,6 +20,8 @@
 package metrics
 
 import (
+	"context"
+	"strings"
 	"time"
 )
 
@@ -139,14 +141,16 @@ type MetricSet struct {
 type DataBatch struct {
 	Timestamp time.Time
 	// Should use key functions from ms_keys.go
-	MetricSets   map[string]*MetricSet
-	MetricPoints []*MetricPoint
+	MetricSets    map[string]*MetricSet
+	MetricPoints  []*MetricPoint
+	Distributions []*Distribution
 }
 
-// A place from where the metrics should be scraped.
+// A place from where the metrics should be scraped. The context is done once the scrape times out
+// or the collector shuts down, at which point the source should abort any pending work.
 type MetricsSource interface {
 	Name() string
-	ScrapeMetrics() (*DataBatch, error)
+	ScrapeMetrics(ctx context.Context) (*DataBatch, error)
 }
 
 // Provider of list of sources to be scraped.
@@ -172,10 +176,44 @@ type DataProcessor interface {
 	Process(*DataBatch) (*DataBatch, error)
 }
 
+// PointsProcessor is implemented by data processors that also process batches without metric sets.
+// By default processors only run against batches that contain metric sets.
+type PointsProcessor interface {
+	ProcessesPoints() bool
+}
+
 // Represents a single point in Wavefront metric format.
 type MetricPoint struct {
-	Metric    string
-	Value     float64
+	Metric string
+	Value  float64
+	// epoch milliseconds
+	Timestamp int64
+	Source    string
+	Tags      map[string]string
+	StrTags   string
+}
+
+// The time window over which a distribution is aggregated by Wavefront.
+type Granularity int
+
+const (
+	MinuteGranularity Granularity = iota
+	HourGranularity
+	DayGranularity
+)
+
+// A single centroid of a distribution.
+type Centroid struct {
+	Value float64
+	Count int
+}
+
+// Represents a single distribution in Wavefront histogram format.
+type Distribution struct {
+	Metric        string
+	Centroids     []Centroid
+	Granularities []Granularity
+	// epoch milliseconds
 	Timestamp int64
 	Source    string
 	Tags      map[string]string
@@ -193,14 +231,34 @@ type ProviderFactory interface {
 	Build(cfg interface{}) (MetricsSourceProvider, error)
 }
 
+// SinkFactory builds a DataSink from the configuration of a given sink type
+type SinkFactory interface {
+	Name() string
+	// NewConfig returns a pointer to a new zero value of the configuration of the sink type
+	NewConfig() interface{}
+	Build(cfg interface{}) (DataSink, error)
+}
+
 type ConfigurabeMetricsSourceProvider interface {
 	Configure(interval, timeout time.Duration)
 }
 
+// ConcurrentMetricsSourceProvider is implemented by providers supporting a custom number of sources scraped in parallel
+type ConcurrentMetricsSourceProvider interface {
+	Concurrency() int
+	SetConcurrency(concurrency int)
+}
+
+// StoppableMetricsSourceProvider is implemented by providers releasing resources once they are deleted
+type StoppableMetricsSourceProvider interface {
+	Stop()
+}
+
 //DefaultMetricsSourceProvider handle the common providers configuration
 type DefaultMetricsSourceProvider struct {
 	collectionInterval time.Duration
 	timeout            time.Duration
+	concurrency        int
 }
 
 // CollectionInterval return the provider collection interval configuration
@@ -221,3 +279,32 @@ func (dp *DefaultMetricsSourceProvider) Configure(interval, timeout time.Duratio
 		dp.timeout = 10 * time.Second
 	}
 }
+
+// Concurrency return the maximum number of sources scraped in parallel, zero if not configured
+func (dp *DefaultMetricsSourceProvider) Concurrency() int {
+	return dp.concurrency
+}
+
+// SetConcurrency sets the maximum number of sources scraped in parallel
+func (dp *DefaultMetricsSourceProvider) SetConcurrency(concurrency int) {
+	dp.concurrency = concurrency
+}
+
+// UnixMillis returns t as a Unix time in milliseconds, the unit of MetricPoint timestamps.
+func UnixMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// DecodeTags returns a copy of the tags merged with the space separated key=value pairs of the StrTags of a point or distribution.
+func DecodeTags(tags map[string]string, strTags string) map[string]string {
+	result := make(map[string]string, len(tags))
+	for k, v := range tags {
+		result[k] = v
+	}
+	for _, tag := range strings.Split(strTags, " ") {
+		if s := strings.SplitN(tag, "=", 2); len(s) == 2 {
+			result[s[0]] = s[1]
+		}
+	}
+	return result
+}
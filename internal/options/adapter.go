@@ -57,7 +57,10 @@ func extractSinkProperties(cfg *configuration.Config) {
 	if len(cfg.Sinks) == 0 {
 		log.Fatalf("no sink configured")
 	}
-	sink := cfg.Sinks[0]
+	sink, ok := cfg.Sinks[0].Config.(*configuration.WavefrontSinkConfig)
+	if !ok {
+		log.Fatalf("no wavefront sink configured")
+	}
 	prefix := sink.Prefix
 	if prefix != "" {
 		// remove it from the sink
@@ -169,7 +172,10 @@ func addWavefrontSink(cfg *configuration.Config, uri flags.Uri) {
 	sink.ClusterName = flags.DecodeValue(vals, "clusterName")
 	sink.Transforms = getTransforms(vals)
 
-	cfg.Sinks = append(cfg.Sinks, sink)
+	cfg.Sinks = append(cfg.Sinks, &configuration.SinkConfig{
+		Type:   configuration.WavefrontSinkType,
+		Config: sink,
+	})
 }
 
 func getTransforms(vals map[string][]string) configuration.Transforms {
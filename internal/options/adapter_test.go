@@ -122,7 +122,8 @@ func TestConvert(t *testing.T) {
 	assert.Equal(t, 1, len(cfg.Sinks))
 	assert.NotNil(t, cfg.Sources.SummaryConfig)
 	assert.NotNil(t, cfg.Sources.StatsConfig)
-	assert.Empty(t, cfg.Sinks[0].Prefix)
+	assert.Equal(t, configuration.WavefrontSinkType, cfg.Sinks[0].Type)
+	assert.Empty(t, cfg.Sinks[0].Config.(*configuration.WavefrontSinkConfig).Prefix)
 	assert.Equal(t, "staging.", cfg.Sources.SummaryConfig.Prefix)
 }
 
@@ -0,0 +1,53 @@
+package relabel
+
+const (
+	Replace   = "replace"
+	Keep      = "keep"
+	Drop      = "drop"
+	HashMod   = "hashmod"
+	LabelMap  = "labelmap"
+	LabelDrop = "labeldrop"
+	LabelKeep = "labelkeep"
+
+	// MetricNameLabel is the label holding the metric name while relabeling.
+	MetricNameLabel = "__name__"
+)
+
+// Configuration for a single relabeling rule. The semantics match the Prometheus relabel_config.
+// The metric name is available as the __name__ label.
+type Config struct {
+	// The labels whose values are concatenated and matched against the regex.
+	SourceLabels []string `yaml:"sourceLabels"`
+
+	// Separator placed between the concatenated source label values. Defaults to ;
+	Separator string `yaml:"separator"`
+
+	// Regular expression matched against the concatenated source label values. Defaults to (.*)
+	// The expression is anchored at both ends.
+	Regex string `yaml:"regex"`
+
+	// Modulus to take of the hash of the source label values. Required for the hashmod action.
+	Modulus uint64 `yaml:"modulus"`
+
+	// The label the result is written to. Required for the replace and hashmod actions.
+	TargetLabel string `yaml:"targetLabel"`
+
+	// Replacement value against which a regex replace is performed. Defaults to $1
+	Replacement string `yaml:"replacement"`
+
+	// One of replace, keep, drop, hashmod, labelmap, labeldrop or labelkeep. Defaults to replace.
+	Action string `yaml:"action"`
+}
+
+var defaultConfig = Config{
+	Separator:   ";",
+	Regex:       "(.*)",
+	Replacement: "$1",
+	Action:      Replace,
+}
+
+func (cfg *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	*cfg = defaultConfig
+	type plain Config
+	return unmarshal((*plain)(cfg))
+}
@@ -0,0 +1,189 @@
+package relabel
+
+import (
+	"crypto/md5"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+type rule struct {
+	Config
+	regex *regexp.Regexp
+}
+
+// Relabeler applies a list of relabeling rules to metric points.
+type Relabeler struct {
+	rules []rule
+}
+
+// FromConfig compiles the given rules. Returns nil if no rules are configured.
+func FromConfig(cfgs []Config) (*Relabeler, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+	r := &Relabeler{}
+	for _, cfg := range cfgs {
+		cfg = withDefaults(cfg)
+		regex, err := regexp.Compile("^(?:" + cfg.Regex + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid relabel regex %s: %v", cfg.Regex, err)
+		}
+		switch cfg.Action {
+		case Replace, HashMod:
+			if cfg.TargetLabel == "" {
+				return nil, fmt.Errorf("relabel action %s requires a targetLabel", cfg.Action)
+			}
+			if cfg.Action == HashMod && cfg.Modulus == 0 {
+				return nil, fmt.Errorf("relabel action hashmod requires a non-zero modulus")
+			}
+		case Keep, Drop, LabelMap, LabelDrop, LabelKeep:
+		default:
+			return nil, fmt.Errorf("invalid relabel action: %s", cfg.Action)
+		}
+		r.rules = append(r.rules, rule{Config: cfg, regex: regex})
+	}
+	return r, nil
+}
+
+// withDefaults fills in the defaults for rules that were not decoded from YAML.
+func withDefaults(cfg Config) Config {
+	if cfg.Separator == "" {
+		cfg.Separator = defaultConfig.Separator
+	}
+	if cfg.Regex == "" {
+		cfg.Regex = defaultConfig.Regex
+	}
+	if cfg.Action == "" {
+		cfg.Action = defaultConfig.Action
+	}
+	return cfg
+}
+
+// Process applies the rules in order to the given labels, which are modified in place.
+// Returns nil if the labels were dropped.
+func (r *Relabeler) Process(labels map[string]string) map[string]string {
+	for _, rule := range r.rules {
+		if labels = rule.apply(labels); labels == nil {
+			return nil
+		}
+	}
+	return labels
+}
+
+func (rule rule) apply(labels map[string]string) map[string]string {
+	values := make([]string, len(rule.SourceLabels))
+	for i, name := range rule.SourceLabels {
+		values[i] = labels[name]
+	}
+	val := strings.Join(values, rule.Separator)
+
+	switch rule.Action {
+	case Drop:
+		if rule.regex.MatchString(val) {
+			return nil
+		}
+	case Keep:
+		if !rule.regex.MatchString(val) {
+			return nil
+		}
+	case Replace:
+		indexes := rule.regex.FindStringSubmatchIndex(val)
+		if indexes == nil {
+			break
+		}
+		target := string(rule.regex.ExpandString(nil, rule.TargetLabel, val, indexes))
+		if target == "" {
+			break
+		}
+		res := rule.regex.ExpandString(nil, rule.Replacement, val, indexes)
+		if len(res) == 0 {
+			delete(labels, target)
+			break
+		}
+		labels[target] = string(res)
+	case HashMod:
+		mod := sum64(md5.Sum([]byte(val))) % rule.Modulus
+		labels[rule.TargetLabel] = fmt.Sprintf("%d", mod)
+	case LabelMap:
+		mapped := make(map[string]string)
+		for name, value := range labels {
+			if rule.regex.MatchString(name) {
+				mapped[rule.regex.ReplaceAllString(name, rule.Replacement)] = value
+			}
+		}
+		for name, value := range mapped {
+			labels[name] = value
+		}
+	case LabelDrop:
+		for name := range labels {
+			if rule.regex.MatchString(name) {
+				delete(labels, name)
+			}
+		}
+	case LabelKeep:
+		for name := range labels {
+			if !rule.regex.MatchString(name) {
+				delete(labels, name)
+			}
+		}
+	}
+	return labels
+}
+
+// sum64 sums the md5 hash to an uint64, matching the Prometheus hashmod implementation.
+func sum64(hash [md5.Size]byte) uint64 {
+	var s uint64
+	for i, b := range hash {
+		shift := uint64((md5.Size - 1 - i) * 8)
+		s |= uint64(b) << shift
+	}
+	return s
+}
+
+// Relabel relabels the given metric name and tags. The tags are modified in place and tags with empty values removed.
+// Returns false if the metric should be dropped.
+func (r *Relabeler) Relabel(name string, tags map[string]string) (string, map[string]string, bool) {
+	tags[MetricNameLabel] = name
+	labels := r.Process(tags)
+	if labels == nil || labels[MetricNameLabel] == "" {
+		return "", nil, false
+	}
+
+	name = labels[MetricNameLabel]
+	delete(labels, MetricNameLabel)
+	for k, v := range labels {
+		if v == "" {
+			delete(labels, k)
+		}
+	}
+	return name, labels, true
+}
+
+// Apply relabels the metric name and tags of the given point. The string encoded tags are merged into the tags.
+// Returns false if the point should be dropped.
+func (r *Relabeler) Apply(point *metrics.MetricPoint) bool {
+	name, tags, ok := r.Relabel(point.Metric, metrics.DecodeTags(point.Tags, point.StrTags))
+	if !ok {
+		return false
+	}
+	point.Metric = name
+	point.Tags = tags
+	point.StrTags = ""
+	return true
+}
+
+// ApplyDistribution relabels the metric name and tags of the given distribution.
+// Returns false if the distribution should be dropped.
+func (r *Relabeler) ApplyDistribution(dist *metrics.Distribution) bool {
+	name, tags, ok := r.Relabel(dist.Metric, metrics.DecodeTags(dist.Tags, dist.StrTags))
+	if !ok {
+		return false
+	}
+	dist.Metric = name
+	dist.Tags = tags
+	dist.StrTags = ""
+	return true
+}
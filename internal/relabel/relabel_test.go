@@ -0,0 +1,147 @@
+package relabel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+func relabeler(t *testing.T, rules string) *Relabeler {
+	var cfgs []Config
+	require.NoError(t, yaml.UnmarshalStrict([]byte(rules), &cfgs))
+	r, err := FromConfig(cfgs)
+	require.NoError(t, err)
+	return r
+}
+
+func TestDefaults(t *testing.T) {
+	var cfgs []Config
+	require.NoError(t, yaml.UnmarshalStrict([]byte("- targetLabel: foo\n"), &cfgs))
+	assert.Equal(t, Config{
+		Separator:   ";",
+		Regex:       "(.*)",
+		Replacement: "$1",
+		Action:      Replace,
+		TargetLabel: "foo",
+	}, cfgs[0])
+}
+
+func TestReplace(t *testing.T) {
+	r := relabeler(t, `
+- sourceLabels: [__name__]
+  regex: 'http\.(.*)\.count'
+  targetLabel: __name__
+  replacement: 'web.$1'
+- sourceLabels: [code, method]
+  separator: '_'
+  regex: '(5..)_(.*)'
+  targetLabel: error
+  replacement: '$2'
+- sourceLabels: [missing]
+  targetLabel: path
+`)
+	labels := r.Process(map[string]string{MetricNameLabel: "http.requests.count", "code": "500", "method": "GET", "path": "/"})
+	assert.Equal(t, map[string]string{MetricNameLabel: "web.requests", "code": "500", "method": "GET", "error": "GET"}, labels)
+
+	// non matching regex leaves the labels unchanged
+	labels = r.Process(map[string]string{MetricNameLabel: "cpu", "code": "200", "method": "GET"})
+	assert.Equal(t, map[string]string{MetricNameLabel: "cpu", "code": "200", "method": "GET"}, labels)
+}
+
+func TestKeepDrop(t *testing.T) {
+	r := relabeler(t, `
+- sourceLabels: [namespace]
+  regex: 'kube-.*'
+  action: keep
+- sourceLabels: [__name__]
+  regex: '.*\.bucket'
+  action: drop
+`)
+	assert.Nil(t, r.Process(map[string]string{MetricNameLabel: "cpu", "namespace": "default"}))
+	assert.Nil(t, r.Process(map[string]string{MetricNameLabel: "latency.bucket", "namespace": "kube-system"}))
+	assert.NotNil(t, r.Process(map[string]string{MetricNameLabel: "cpu", "namespace": "kube-system"}))
+}
+
+func TestHashMod(t *testing.T) {
+	r := relabeler(t, `
+- sourceLabels: [pod]
+  modulus: 4
+  targetLabel: shard
+  action: hashmod
+- sourceLabels: [shard]
+  regex: '1'
+  action: keep
+`)
+	kept := 0
+	for _, pod := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		labels := r.Process(map[string]string{MetricNameLabel: "cpu", "pod": pod})
+		if labels != nil {
+			assert.Equal(t, "1", labels["shard"])
+			kept++
+		}
+	}
+	assert.True(t, kept > 0 && kept < 8)
+
+	// the hash is stable across collector instances
+	labels := relabeler(t, "- sourceLabels: [a]\n  modulus: 1000\n  targetLabel: b\n  action: hashmod\n").
+		Process(map[string]string{"a": "foo"})
+	assert.Equal(t, "696", labels["b"])
+}
+
+func TestLabelActions(t *testing.T) {
+	r := relabeler(t, `
+- regex: 'label\.(.+)'
+  action: labelmap
+- regex: 'label\..+'
+  action: labeldrop
+- regex: '__name__|app|pod'
+  action: labelkeep
+`)
+	labels := r.Process(map[string]string{MetricNameLabel: "cpu", "label.app": "web", "pod": "p1", "node": "n1"})
+	assert.Equal(t, map[string]string{MetricNameLabel: "cpu", "app": "web", "pod": "p1"}, labels)
+}
+
+func TestApply(t *testing.T) {
+	r := relabeler(t, `
+- sourceLabels: [code]
+  targetLabel: status
+- regex: 'code'
+  action: labeldrop
+- sourceLabels: [env]
+  targetLabel: env
+  replacement: ''
+`)
+	point := &metrics.MetricPoint{
+		Metric:  "requests",
+		StrTags: " code=200",
+		Tags:    map[string]string{"env": "dev"},
+	}
+	assert.True(t, r.Apply(point))
+	assert.Equal(t, "requests", point.Metric)
+	assert.Equal(t, map[string]string{"status": "200"}, point.Tags)
+	assert.Equal(t, "", point.StrTags)
+
+	r = relabeler(t, "- targetLabel: __name__\n  replacement: ''\n")
+	assert.False(t, r.Apply(&metrics.MetricPoint{Metric: "requests"}))
+}
+
+func TestInvalidConfig(t *testing.T) {
+	invalid := [][]Config{
+		{{Action: "foo"}},
+		{{Regex: "("}},
+		{{Action: Replace}},
+		{{Action: HashMod, TargetLabel: "a"}},
+	}
+	for _, cfgs := range invalid {
+		_, err := FromConfig(cfgs)
+		assert.Error(t, err)
+	}
+
+	r, err := FromConfig(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+}
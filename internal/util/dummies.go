@@ -15,6 +15,7 @@
 package util
 
 import (
+	"context"
 	"strings"
 	"sync"
 	"time"
@@ -81,13 +82,19 @@ func (dummy *DummyMetricsSource) Name() string {
 	return dummy.name
 }
 
-func (dummy *DummyMetricsSource) ScrapeMetrics() (*DataBatch, error) {
-	time.Sleep(dummy.latency)
+func (dummy *DummyMetricsSource) ScrapeMetrics(ctx context.Context) (*DataBatch, error) {
+	if dummy.latency > 0 {
+		select {
+		case <-time.After(dummy.latency):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
 
 	point := &metrics.MetricPoint{
 		Metric:    strings.Replace(dummy.Name(), " ", ".", -1),
 		Value:     1,
-		Timestamp: time.Now().UnixNano() / 1000,
+		Timestamp: metrics.UnixMillis(time.Now()),
 		Source:    dummy.Name(),
 		Tags:      map[string]string{"tag": "tag"},
 	}
@@ -51,6 +51,22 @@ func (copier *LabelCopier) Copy(in map[string]string, out map[string]string) {
 	out[metrics.LabelLabels.Key] = strings.Join(labels, copier.labelSeparator)
 }
 
+// Labels returns the labels concatenated by Copy into the metrics.LabelLabels.Key label of the given metric labels.
+func (copier *LabelCopier) Labels(metricLabels map[string]string) map[string]string {
+	labels := make(map[string]string)
+	value, found := metricLabels[metrics.LabelLabels.Key]
+	if !found || value == "" {
+		return labels
+	}
+	for _, label := range strings.Split(value, copier.labelSeparator) {
+		parts := strings.SplitN(label, ":", 2)
+		if len(parts) == 2 {
+			labels[parts[0]] = parts[1]
+		}
+	}
+	return labels
+}
+
 // makeStoredLabels converts labels into a map for quicker retrieval.
 // Incoming labels, if desired, may contain mappings in format "newName=oldName"
 func makeStoredLabels(labels []string) map[string]string {
@@ -94,6 +94,22 @@ func TestAll(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestLabels(t *testing.T) {
+	lc, err := NewLabelCopier("-", []string{}, []string{"colour"})
+	if err != nil {
+		t.Fatalf("Could not create LabelCopier: %v", err)
+	}
+	out := map[string]string{}
+	lc.Copy(map[string]string{"name": "bike", "colour": "red", "price": "too_high"}, out)
+
+	expected := map[string]string{
+		"name":  "bike",
+		"price": "too_high",
+	}
+	assert.Equal(t, expected, lc.Labels(out))
+	assert.Empty(t, lc.Labels(map[string]string{}))
+}
+
 func initializeAndCopy(t *testing.T, separator string, storedLabels []string, ignoredLabels []string) map[string]string {
 	lc, err := NewLabelCopier(separator, storedLabels, ignoredLabels)
 	if err != nil {
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// Ticker delivers ticks at a fixed interval, optionally aligned to wall-clock multiples of the interval.
+type Ticker struct {
+	C <-chan time.Time
+
+	ticker   *time.Ticker
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewTicker returns a ticker delivering ticks every interval. Aligned tickers tick at wall-clock
+// multiples of the interval, so tickers with the same interval tick at the same time.
+func NewTicker(interval time.Duration, aligned bool) *Ticker {
+	if !aligned {
+		ticker := time.NewTicker(interval)
+		return &Ticker{C: ticker.C, ticker: ticker}
+	}
+
+	c := make(chan time.Time, 1)
+	t := &Ticker{C: c, stop: make(chan struct{})}
+	go func() {
+		for {
+			// the next boundary is computed for every tick so the ticker does not drift
+			now := time.Now()
+			timer := time.NewTimer(now.Truncate(interval).Add(interval).Sub(now))
+			select {
+			case tick := <-timer.C:
+				// drops the tick for slow receivers, same as time.Ticker
+				select {
+				case c <- tick:
+				default:
+				}
+			case <-t.stop:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+	return t
+}
+
+// Stop turns off the ticker. Like time.Ticker, it does not close the channel.
+func (t *Ticker) Stop() {
+	if t.ticker != nil {
+		t.ticker.Stop()
+		return
+	}
+	t.stopOnce.Do(func() { close(t.stop) })
+}
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlignedTicker(t *testing.T) {
+	interval := 50 * time.Millisecond
+	ticker := NewTicker(interval, true)
+	defer ticker.Stop()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case tick := <-ticker.C:
+			offset := tick.Sub(tick.Truncate(interval))
+			assert.True(t, offset < 10*time.Millisecond, "tick %v not aligned to %v: offset %v", tick, interval, offset)
+		case <-time.After(time.Second):
+			require.Fail(t, "no tick received")
+		}
+	}
+}
+
+func TestTickerStop(t *testing.T) {
+	for _, aligned := range []bool{false, true} {
+		ticker := NewTicker(10*time.Millisecond, aligned)
+		<-ticker.C
+		ticker.Stop()
+		ticker.Stop()
+
+		// a tick may already be pending when stopping
+		select {
+		case <-ticker.C:
+		default:
+		}
+		select {
+		case <-ticker.C:
+			assert.Fail(t, "tick received after stop", "aligned: %v", aligned)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
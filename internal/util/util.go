@@ -26,10 +26,14 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	kube_api "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
 	v1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 )
@@ -41,11 +45,12 @@ const (
 )
 
 var (
-	lock       sync.Mutex
-	nodeLister v1listers.NodeLister
-	reflector  *cache.Reflector
-	podLister  v1listers.PodLister
-	nsStore    cache.Store
+	lock         sync.Mutex
+	nodeLister   v1listers.NodeLister
+	reflector    *cache.Reflector
+	podLister    v1listers.PodLister
+	podReflector *cache.Reflector
+	nsStore      cache.Store
 )
 
 func GetNodeLister(kubeClient kubernetes.Interface) (v1listers.NodeLister, *cache.Reflector, error) {
@@ -66,22 +71,22 @@ func GetNodeLister(kubeClient kubernetes.Interface) (v1listers.NodeLister, *cach
 	return nodeLister, reflector, nil
 }
 
-func GetPodLister(kubeClient kubernetes.Interface) (v1listers.PodLister, error) {
+func GetPodLister(kubeClient kubernetes.Interface) (v1listers.PodLister, *cache.Reflector, error) {
 	lock.Lock()
 	defer lock.Unlock()
 
 	// init just one instance per collector agent
 	if podLister != nil {
-		return podLister, nil
+		return podLister, podReflector, nil
 	}
 
 	fieldSelector := GetFieldSelector("pods")
 	lw := cache.NewListWatchFromClient(kubeClient.CoreV1().RESTClient(), "pods", kube_api.NamespaceAll, fieldSelector)
 	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
 	podLister = v1listers.NewPodLister(store)
-	reflector := cache.NewReflector(lw, &kube_api.Pod{}, store, time.Hour)
-	go reflector.Run(wait.NeverStop)
-	return podLister, nil
+	podReflector = cache.NewReflector(lw, &kube_api.Pod{}, store, time.Hour)
+	go podReflector.Run(wait.NeverStop)
+	return podLister, podReflector, nil
 }
 
 func GetServiceLister(kubeClient kubernetes.Interface) (v1listers.ServiceLister, error) {
@@ -93,6 +98,42 @@ func GetServiceLister(kubeClient kubernetes.Interface) (v1listers.ServiceLister,
 	return serviceLister, nil
 }
 
+func GetReplicaSetLister(kubeClient kubernetes.Interface) (appslisters.ReplicaSetLister, error) {
+	lw := cache.NewListWatchFromClient(kubeClient.AppsV1().RESTClient(), "replicasets", kube_api.NamespaceAll, fields.Everything())
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	replicaSetLister := appslisters.NewReplicaSetLister(store)
+	reflector := cache.NewReflector(lw, &appsv1.ReplicaSet{}, store, time.Hour)
+	go reflector.Run(wait.NeverStop)
+	return replicaSetLister, nil
+}
+
+func GetJobLister(kubeClient kubernetes.Interface) (batchlisters.JobLister, error) {
+	lw := cache.NewListWatchFromClient(kubeClient.BatchV1().RESTClient(), "jobs", kube_api.NamespaceAll, fields.Everything())
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	jobLister := batchlisters.NewJobLister(store)
+	reflector := cache.NewReflector(lw, &batchv1.Job{}, store, time.Hour)
+	go reflector.Run(wait.NeverStop)
+	return jobLister, nil
+}
+
+func GetPersistentVolumeClaimLister(kubeClient kubernetes.Interface) (v1listers.PersistentVolumeClaimLister, error) {
+	lw := cache.NewListWatchFromClient(kubeClient.CoreV1().RESTClient(), "persistentvolumeclaims", kube_api.NamespaceAll, fields.Everything())
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	pvcLister := v1listers.NewPersistentVolumeClaimLister(store)
+	reflector := cache.NewReflector(lw, &kube_api.PersistentVolumeClaim{}, store, time.Hour)
+	go reflector.Run(wait.NeverStop)
+	return pvcLister, nil
+}
+
+func GetPersistentVolumeLister(kubeClient kubernetes.Interface) (v1listers.PersistentVolumeLister, error) {
+	lw := cache.NewListWatchFromClient(kubeClient.CoreV1().RESTClient(), "persistentvolumes", kube_api.NamespaceAll, fields.Everything())
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	pvLister := v1listers.NewPersistentVolumeLister(store)
+	reflector := cache.NewReflector(lw, &kube_api.PersistentVolume{}, store, time.Hour)
+	go reflector.Run(wait.NeverStop)
+	return pvLister, nil
+}
+
 func GetNamespaceStore(kubeClient kubernetes.Interface) cache.Store {
 	lock.Lock()
 	defer lock.Unlock()
@@ -39,6 +39,7 @@ type Manager struct {
 	ruleHandler     discovery.RuleHandler
 	podListener     *podHandler
 	serviceListener *serviceHandler
+	leadershipCh    <-chan bool
 	stopCh          chan struct{}
 }
 
@@ -74,6 +75,7 @@ func (dm *Manager) Start() {
 		if err != nil {
 			log.Errorf("discovery: leader election error: %q", err)
 		} else {
+			dm.leadershipCh = ch
 			go func() {
 				for {
 					select {
@@ -99,7 +101,10 @@ func (dm *Manager) Stop() {
 	log.Infof("Stopping discovery manager")
 	discoveryEnabled.Dec(1)
 
-	leadership.Unsubscribe()
+	if dm.leadershipCh != nil {
+		leadership.Unsubscribe(dm.leadershipCh)
+		dm.leadershipCh = nil
+	}
 	dm.podListener.stop()
 	dm.serviceListener.stop()
 	close(dm.stopCh)
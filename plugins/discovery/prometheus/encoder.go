@@ -2,6 +2,7 @@ package prometheus
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,6 +26,8 @@ const (
 	sourceAnnotation             = "prometheus.io/source"
 	collectionIntervalAnnotation = "prometheus.io/collectionInterval"
 	timeoutAnnotation            = "prometheus.io/timeout"
+	honorTimestampsAnnotation    = "prometheus.io/honorTimestamps"
+	maxSeriesAnnotation          = "prometheus.io/maxSeries"
 )
 
 // used as source for discovered resources
@@ -99,6 +102,19 @@ func (e prometheusEncoder) Encode(ip, kind string, meta metav1.ObjectMeta, cfg i
 		utils.EncodeTags(result.Tags, "label.", meta.Labels)
 	}
 	result.Filters = rule.Filters
+	result.HonorTimestamps = utils.Param(meta, honorTimestampsAnnotation, "", "false") == "true"
+	result.Cardinality = rule.Cardinality
+	result.Rule = rule.Name
+	if maxSeries := utils.Param(meta, maxSeriesAnnotation, "", ""); maxSeries != "" {
+		value, err := strconv.Atoi(maxSeries)
+		if err != nil {
+			log.Errorf("error parsing max series: %s %v", maxSeries, err)
+			return result, false
+		}
+		// the annotation sets a budget for the target alone
+		result.Cardinality.MaxSeries = value
+		result.Rule = ""
+	}
 
 	err := encodeConf(&result, rule.Conf)
 	if err != nil {
@@ -118,6 +118,19 @@ tls_config:
 	assert.Equal(t, "/var/run/secrets/kubernetes.io/serviceaccount/token", pcfg.HTTPClientConfig.BearerTokenFile)
 	assert.Equal(t, "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt", pcfg.HTTPClientConfig.TLSConfig.CAFile)
 	assert.True(t, pcfg.HTTPClientConfig.TLSConfig.InsecureSkipVerify)
+
+	// validate the series budget is picked up from the cfg and annotation
+	cfg.Cardinality.MaxSeries = 1000
+	promCfg, ok = encoder.Encode("10.2.3.4", "pod", pod.ObjectMeta, cfg)
+	assert.True(t, ok)
+	assert.Equal(t, 1000, promCfg.(configuration.PrometheusSourceConfig).Cardinality.MaxSeries)
+	assert.Equal(t, cfg.Name, promCfg.(configuration.PrometheusSourceConfig).Rule)
+
+	pod.Annotations[maxSeriesAnnotation] = "500"
+	promCfg, ok = encoder.Encode("10.2.3.4", "pod", pod.ObjectMeta, cfg)
+	assert.True(t, ok)
+	assert.Equal(t, 500, promCfg.(configuration.PrometheusSourceConfig).Cardinality.MaxSeries)
+	assert.Equal(t, "", promCfg.(configuration.PrometheusSourceConfig).Rule)
 }
 
 func checkTag(tags map[string]string, key, val string, t *testing.T) {
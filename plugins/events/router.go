@@ -0,0 +1,304 @@
+// Package events forwards Kubernetes events to the sinks that support exporting events
+package events
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/events"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/leadership"
+
+	gm "github.com/rcrowley/go-metrics"
+	log "github.com/sirupsen/logrus"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+const (
+	defaultDedupeInterval = 5 * time.Minute
+	defaultMaxPerMinute   = 60
+)
+
+var (
+	receivedEvents    gm.Counter
+	filteredEvents    gm.Counter
+	dedupedEvents     gm.Counter
+	rateLimitedEvents gm.Counter
+
+	// reasons reported with the severe severity regardless of the event type
+	severeReasons = map[string]bool{
+		"OOMKilling":   true,
+		"OOMKilled":    true,
+		"SystemOOM":    true,
+		"NodeNotReady": true,
+		"Evicted":      true,
+	}
+)
+
+func init() {
+	receivedEvents = gm.GetOrRegisterCounter("events.received.count", gm.DefaultRegistry)
+	filteredEvents = gm.GetOrRegisterCounter("events.filtered.count", gm.DefaultRegistry)
+	dedupedEvents = gm.GetOrRegisterCounter("events.deduped.count", gm.DefaultRegistry)
+	rateLimitedEvents = gm.GetOrRegisterCounter("events.ratelimited.count", gm.DefaultRegistry)
+}
+
+// RunConfig encapsulates the runtime configuration required for an event router
+type RunConfig struct {
+	KubeClient  kubernetes.Interface
+	Sink        events.EventSink
+	ClusterName string
+	Daemon      bool
+	Config      configuration.EventsConfig
+}
+
+// EventRouter watches Kubernetes events and forwards them to a sink.
+// Identical events are deduplicated and the number of forwarded events is rate limited.
+type EventRouter struct {
+	runConfig      RunConfig
+	filters        filter.Filter
+	limiter        flowcontrol.RateLimiter
+	dedupeInterval time.Duration
+
+	mtx         sync.Mutex
+	sent        map[string]time.Time
+	lastCleanup time.Time
+	startTime   time.Time
+	informerCh  chan struct{}
+	leaderCh    <-chan bool
+	stopCh      chan struct{}
+}
+
+// NewEventRouter creates a new event router based on the given configuration.
+func NewEventRouter(cfg RunConfig) *EventRouter {
+	dedupeInterval := cfg.Config.DedupeInterval
+	if dedupeInterval <= 0 {
+		dedupeInterval = defaultDedupeInterval
+	}
+	maxPerMinute := cfg.Config.MaxPerMinute
+	if maxPerMinute <= 0 {
+		maxPerMinute = defaultMaxPerMinute
+	}
+
+	var filters filter.Filter
+	if len(cfg.Config.TagWhitelist) > 0 || len(cfg.Config.TagBlacklist) > 0 {
+		filters = filter.NewGlobFilter(filter.Config{
+			MetricTagWhitelist: cfg.Config.TagWhitelist,
+			MetricTagBlacklist: cfg.Config.TagBlacklist,
+		})
+	}
+
+	return &EventRouter{
+		runConfig:      cfg,
+		filters:        filters,
+		limiter:        flowcontrol.NewTokenBucketRateLimiter(float32(maxPerMinute)/60, maxPerMinute),
+		dedupeInterval: dedupeInterval,
+		sent:           make(map[string]time.Time),
+		lastCleanup:    time.Now(),
+		startTime:      time.Now(),
+	}
+}
+
+func (er *EventRouter) Start() {
+	log.Infof("Starting event router")
+	er.stopCh = make(chan struct{})
+
+	if !er.runConfig.Daemon {
+		er.startInformer()
+		return
+	}
+
+	// in daemon mode, events are forwarded by only one collector agent in a cluster
+	ch, err := leadership.Subscribe(er.runConfig.KubeClient.CoreV1())
+	if err != nil {
+		log.Errorf("events: leader election error: %q", err)
+		return
+	}
+	er.leaderCh = ch
+	go func() {
+		for {
+			select {
+			case isLeader := <-ch:
+				if isLeader {
+					log.Infof("elected leader: %s starting event router", leadership.Leader())
+					er.startInformer()
+				} else {
+					log.Infof("stopping event router. new leader: %s", leadership.Leader())
+					er.stopInformer()
+				}
+			case <-er.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (er *EventRouter) Stop() {
+	log.Infof("Stopping event router")
+	if er.leaderCh != nil {
+		leadership.Unsubscribe(er.leaderCh)
+		er.leaderCh = nil
+	}
+	er.stopInformer()
+	if er.stopCh != nil {
+		close(er.stopCh)
+		er.stopCh = nil
+	}
+}
+
+func (er *EventRouter) startInformer() {
+	er.mtx.Lock()
+	defer er.mtx.Unlock()
+
+	if er.informerCh != nil {
+		return
+	}
+	// only events that occur after the informer is started are forwarded
+	er.startTime = time.Now()
+	er.informerCh = make(chan struct{})
+	go er.newInformer().Run(er.informerCh)
+}
+
+func (er *EventRouter) stopInformer() {
+	er.mtx.Lock()
+	defer er.mtx.Unlock()
+
+	if er.informerCh != nil {
+		close(er.informerCh)
+		er.informerCh = nil
+	}
+}
+
+func (er *EventRouter) newInformer() cache.SharedInformer {
+	e := er.runConfig.KubeClient.CoreV1().Events(v1.NamespaceAll)
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return e.List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return e.Watch(options)
+		},
+	}
+	inf := cache.NewSharedInformer(lw, &v1.Event{}, 0)
+	inf.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			er.addEvent(obj.(*v1.Event))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// events are updated with an incremented count when they reoccur
+			if oldObj.(*v1.Event).Count != newObj.(*v1.Event).Count {
+				er.addEvent(newObj.(*v1.Event))
+			}
+		},
+	})
+	return inf
+}
+
+func (er *EventRouter) addEvent(ev *v1.Event) {
+	ts := eventTime(ev)
+
+	er.mtx.Lock()
+	startTime := er.startTime
+	er.mtx.Unlock()
+	if ts.Before(startTime) || !leadership.Leading() {
+		return
+	}
+	receivedEvents.Inc(1)
+
+	e := er.buildEvent(ev, ts)
+	if er.filters != nil && !er.filters.Match(e.Name, e.Tags) {
+		filteredEvents.Inc(1)
+		return
+	}
+	if !er.firstOccurrence(ev, ts) {
+		dedupedEvents.Inc(1)
+		return
+	}
+	if !er.limiter.TryAccept() {
+		rateLimitedEvents.Inc(1)
+		log.WithField("reason", ev.Reason).Debug("Dropping rate limited event")
+		return
+	}
+	er.runConfig.Sink.ExportEvent(e)
+}
+
+// firstOccurrence returns false if an identical event was forwarded within the dedupe interval
+func (er *EventRouter) firstOccurrence(ev *v1.Event, ts time.Time) bool {
+	er.mtx.Lock()
+	defer er.mtx.Unlock()
+
+	now := time.Now()
+	if now.Sub(er.lastCleanup) > er.dedupeInterval {
+		for k, last := range er.sent {
+			if now.Sub(last) > er.dedupeInterval {
+				delete(er.sent, k)
+			}
+		}
+		er.lastCleanup = now
+	}
+
+	obj := ev.InvolvedObject
+	key := strings.Join([]string{obj.Kind, obj.Namespace, obj.Name, ev.Reason, ev.Message}, "|")
+	if last, found := er.sent[key]; found && ts.Sub(last) < er.dedupeInterval {
+		return false
+	}
+	er.sent[key] = ts
+	return true
+}
+
+func (er *EventRouter) buildEvent(ev *v1.Event, ts time.Time) *events.Event {
+	obj := ev.InvolvedObject
+	tags := map[string]string{
+		"namespace_name": obj.Namespace,
+		"kind":           obj.Kind,
+		"name":           obj.Name,
+		"reason":         ev.Reason,
+		"component":      ev.Source.Component,
+	}
+	source := ev.Source.Host
+	if source == "" {
+		source = er.runConfig.ClusterName
+	}
+	return &events.Event{
+		Name:      ev.Reason,
+		Message:   ev.Message,
+		Timestamp: ts,
+		Source:    source,
+		Severity:  severity(ev),
+		Type:      ev.Type,
+		Tags:      tags,
+	}
+}
+
+func severity(ev *v1.Event) string {
+	switch {
+	case severeReasons[ev.Reason]:
+		return events.SeveritySevere
+	case ev.Type == v1.EventTypeWarning:
+		return events.SeverityWarn
+	default:
+		return events.SeverityInfo
+	}
+}
+
+// eventTime returns the time an event last occurred
+func eventTime(ev *v1.Event) time.Time {
+	switch {
+	case !ev.LastTimestamp.IsZero():
+		return ev.LastTimestamp.Time
+	case !ev.EventTime.IsZero():
+		return ev.EventTime.Time
+	case !ev.FirstTimestamp.IsZero():
+		return ev.FirstTimestamp.Time
+	default:
+		return ev.CreationTimestamp.Time
+	}
+}
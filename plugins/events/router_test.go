@@ -0,0 +1,103 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/events"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeEventSink struct {
+	events []*events.Event
+}
+
+func (sink *fakeEventSink) ExportEvent(e *events.Event) {
+	sink.events = append(sink.events, e)
+}
+
+func newRouter(cfg configuration.EventsConfig) (*EventRouter, *fakeEventSink) {
+	sink := &fakeEventSink{}
+	router := NewEventRouter(RunConfig{
+		Sink:        sink,
+		ClusterName: "test-cluster",
+		Config:      cfg,
+	})
+	router.startTime = time.Now().Add(-time.Minute)
+	return router, sink
+}
+
+func newEvent(kind, name, reason, eventType string, ts time.Time) *v1.Event {
+	return &v1.Event{
+		InvolvedObject: v1.ObjectReference{Kind: kind, Namespace: "default", Name: name},
+		Reason:         reason,
+		Message:        reason + " " + name,
+		Type:           eventType,
+		LastTimestamp:  metav1.NewTime(ts),
+	}
+}
+
+func TestAddEvent(t *testing.T) {
+	router, sink := newRouter(configuration.EventsConfig{})
+	router.addEvent(newEvent("Pod", "web-1", "BackOff", v1.EventTypeWarning, time.Now()))
+
+	assert.Equal(t, 1, len(sink.events))
+	e := sink.events[0]
+	assert.Equal(t, "BackOff", e.Name)
+	assert.Equal(t, "BackOff web-1", e.Message)
+	assert.Equal(t, events.SeverityWarn, e.Severity)
+	assert.Equal(t, "test-cluster", e.Source)
+	assert.Equal(t, "Pod", e.Tags["kind"])
+	assert.Equal(t, "default", e.Tags["namespace_name"])
+	assert.Equal(t, "web-1", e.Tags["name"])
+}
+
+func TestSeverity(t *testing.T) {
+	assert.Equal(t, events.SeverityInfo, severity(newEvent("Pod", "web-1", "Scheduled", v1.EventTypeNormal, time.Now())))
+	assert.Equal(t, events.SeverityWarn, severity(newEvent("Pod", "web-1", "FailedScheduling", v1.EventTypeWarning, time.Now())))
+	assert.Equal(t, events.SeveritySevere, severity(newEvent("Node", "node-1", "NodeNotReady", v1.EventTypeNormal, time.Now())))
+	assert.Equal(t, events.SeveritySevere, severity(newEvent("Node", "node-1", "OOMKilling", v1.EventTypeWarning, time.Now())))
+}
+
+func TestOldEventsSkipped(t *testing.T) {
+	router, sink := newRouter(configuration.EventsConfig{})
+	router.addEvent(newEvent("Pod", "web-1", "BackOff", v1.EventTypeWarning, time.Now().Add(-time.Hour)))
+	assert.Equal(t, 0, len(sink.events))
+}
+
+func TestDedupe(t *testing.T) {
+	router, sink := newRouter(configuration.EventsConfig{DedupeInterval: time.Minute})
+	now := time.Now()
+	router.addEvent(newEvent("Pod", "web-1", "BackOff", v1.EventTypeWarning, now))
+	router.addEvent(newEvent("Pod", "web-1", "BackOff", v1.EventTypeWarning, now.Add(10*time.Second)))
+	router.addEvent(newEvent("Pod", "web-2", "BackOff", v1.EventTypeWarning, now.Add(10*time.Second)))
+	assert.Equal(t, 2, len(sink.events))
+
+	router.addEvent(newEvent("Pod", "web-1", "BackOff", v1.EventTypeWarning, now.Add(2*time.Minute)))
+	assert.Equal(t, 3, len(sink.events))
+}
+
+func TestRateLimit(t *testing.T) {
+	router, sink := newRouter(configuration.EventsConfig{MaxPerMinute: 2})
+	now := time.Now()
+	router.addEvent(newEvent("Pod", "web-1", "BackOff", v1.EventTypeWarning, now))
+	router.addEvent(newEvent("Pod", "web-2", "BackOff", v1.EventTypeWarning, now))
+	router.addEvent(newEvent("Pod", "web-3", "BackOff", v1.EventTypeWarning, now))
+	assert.Equal(t, 2, len(sink.events))
+}
+
+func TestFilters(t *testing.T) {
+	router, sink := newRouter(configuration.EventsConfig{
+		TagWhitelist: map[string][]string{"kind": {"Node"}},
+	})
+	now := time.Now()
+	router.addEvent(newEvent("Pod", "web-1", "BackOff", v1.EventTypeWarning, now))
+	router.addEvent(newEvent("Node", "node-1", "NodeNotReady", v1.EventTypeNormal, now))
+	assert.Equal(t, 1, len(sink.events))
+	assert.Equal(t, "NodeNotReady", sink.events[0].Name)
+}
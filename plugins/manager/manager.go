@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources"
 
 	log "github.com/sirupsen/logrus"
@@ -36,17 +37,20 @@ type flushManagerImpl struct {
 	processors    []metrics.DataProcessor
 	sink          metrics.DataSink
 	flushInterval time.Duration
-	ticker        *time.Ticker
+	alignFlush    bool
+	ticker        *util.Ticker
 	stopChan      chan struct{}
 }
 
-// NewFlushManager crates a new PushManager
+// NewFlushManager crates a new PushManager. If alignFlush is set the data is pushed at wall-clock
+// multiples of the flush interval, matching the aligned collection intervals of the sources.
 func NewFlushManager(processors []metrics.DataProcessor,
-	sink metrics.DataSink, flushInterval time.Duration) (FlushManager, error) {
+	sink metrics.DataSink, flushInterval time.Duration, alignFlush bool) (FlushManager, error) {
 	manager := flushManagerImpl{
 		processors:    processors,
 		sink:          sink,
 		flushInterval: flushInterval,
+		alignFlush:    alignFlush,
 		stopChan:      make(chan struct{}),
 	}
 
@@ -54,7 +58,7 @@ func NewFlushManager(processors []metrics.DataProcessor,
 }
 
 func (rm *flushManagerImpl) Start() {
-	rm.ticker = time.NewTicker(rm.flushInterval)
+	rm.ticker = util.NewTicker(rm.flushInterval, rm.alignFlush)
 	go rm.run()
 }
 
@@ -79,7 +83,7 @@ func (rm *flushManagerImpl) push() {
 	dataList := sources.Manager().GetPendingMetrics()
 	for _, data := range dataList {
 		for _, p := range rm.processors {
-			if len(data.MetricSets) > 0 {
+			if len(data.MetricSets) > 0 || processesPoints(p) {
 				newData, err := p.Process(data)
 				if err == nil {
 					data = newData
@@ -93,3 +97,8 @@ func (rm *flushManagerImpl) push() {
 		rm.sink.ExportData(data)
 	}
 }
+
+func processesPoints(p metrics.DataProcessor) bool {
+	pp, ok := p.(metrics.PointsProcessor)
+	return ok && pp.ProcessesPoints()
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/processors"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources"
 )
 
@@ -33,7 +34,7 @@ func TestFlow(t *testing.T) {
 
 	sources.Manager().AddProvider(provider)
 
-	manager, _ := NewFlushManager([]metrics.DataProcessor{processor}, sink, 100*time.Millisecond)
+	manager, _ := NewFlushManager([]metrics.DataProcessor{processor}, sink, 100*time.Millisecond, false)
 	manager.Start()
 
 	// 4-5 cycles
@@ -44,3 +45,13 @@ func TestFlow(t *testing.T) {
 		t.Fatalf("Wrong number of exports executed: %d", sink.GetExportCount())
 	}
 }
+
+func TestProcessesPoints(t *testing.T) {
+	processor := util.NewDummyDataProcessor(time.Millisecond)
+	if processesPoints(processor) {
+		t.Fatalf("processor should not process points by default")
+	}
+	if !processesPoints(processors.NewPointsProcessor(processor)) {
+		t.Fatalf("wrapped processor should process points")
+	}
+}
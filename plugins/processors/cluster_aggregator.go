@@ -20,6 +20,8 @@ import (
 
 type ClusterAggregator struct {
 	MetricsToAggregate []string
+	// Applied to the pod values rather than the namespace sums.
+	Aggregations []Aggregation
 }
 
 func (aggregator *ClusterAggregator) Name() string {
@@ -29,14 +31,22 @@ func (aggregator *ClusterAggregator) Name() string {
 func (aggregator *ClusterAggregator) Process(batch *metrics.DataBatch) (*metrics.DataBatch, error) {
 	clusterKey := metrics.ClusterKey()
 	cluster := clusterMetricSet()
+	values := newAggregationValues(aggregator.Aggregations)
 	for _, metricSet := range batch.MetricSets {
-		if metricSetType, found := metricSet.Labels[metrics.LabelMetricSetType.Key]; found &&
-			metricSetType == metrics.MetricSetTypeNamespace {
+		metricSetType, found := metricSet.Labels[metrics.LabelMetricSetType.Key]
+		if !found {
+			continue
+		}
+		switch metricSetType {
+		case metrics.MetricSetTypeNamespace:
 			if err := aggregate(metricSet, cluster, aggregator.MetricsToAggregate); err != nil {
 				return nil, err
 			}
+		case metrics.MetricSetTypePod:
+			values.collect(metricSet, cluster)
 		}
 	}
+	values.apply()
 
 	batch.MetricSets[clusterKey] = cluster
 	return batch, nil
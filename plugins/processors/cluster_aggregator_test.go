@@ -82,3 +82,58 @@ func TestClusterAggregate(t *testing.T) {
 	assert.True(t, found)
 	assert.Equal(t, int64(30), m3.IntValue)
 }
+
+func TestClusterAggregations(t *testing.T) {
+	podMetricSet := func(namespace string, value int64) *metrics.MetricSet {
+		return &metrics.MetricSet{
+			Labels: map[string]string{
+				metrics.LabelMetricSetType.Key: metrics.MetricSetTypePod,
+				metrics.LabelNamespaceName.Key: namespace,
+			},
+			MetricValues: map[string]metrics.MetricValue{
+				"m1": {
+					ValueType:  metrics.ValueInt64,
+					MetricType: metrics.MetricGauge,
+					IntValue:   value,
+				},
+			},
+		}
+	}
+	batch := metrics.DataBatch{
+		Timestamp: time.Now(),
+		MetricSets: map[string]*metrics.MetricSet{
+			metrics.PodKey("ns1", "pod1"): podMetricSet("ns1", 10),
+			metrics.PodKey("ns1", "pod2"): podMetricSet("ns1", 20),
+			metrics.PodKey("ns2", "pod3"): podMetricSet("ns2", 60),
+			metrics.NamespaceKey("ns1"): {
+				Labels: map[string]string{
+					metrics.LabelMetricSetType.Key: metrics.MetricSetTypeNamespace,
+					metrics.LabelNamespaceName.Key: "ns1",
+				},
+				MetricValues: map[string]metrics.MetricValue{
+					"m1": {
+						ValueType:  metrics.ValueInt64,
+						MetricType: metrics.MetricGauge,
+						IntValue:   30,
+					},
+				},
+			},
+		},
+	}
+	processor := ClusterAggregator{
+		MetricsToAggregate: []string{"m1"},
+		Aggregations: []Aggregation{
+			{Metric: "m1", Function: AggregationAvg},
+			{Metric: "m1", Function: AggregationMin},
+		},
+	}
+	result, err := processor.Process(&batch)
+	assert.NoError(t, err)
+	cluster, found := result.MetricSets[metrics.ClusterKey()]
+	assert.True(t, found)
+
+	// the sum is aggregated from the namespaces, the functions from the pods
+	assert.Equal(t, int64(30), cluster.MetricValues["m1"].IntValue)
+	assert.Equal(t, float64(30), cluster.MetricValues["m1.avg"].FloatValue)
+	assert.Equal(t, float64(10), cluster.MetricValues["m1.min"].FloatValue)
+}
@@ -0,0 +1,126 @@
+package processors
+
+import (
+	"math"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
+
+	kube_api "k8s.io/api/core/v1"
+	kube_client "k8s.io/client-go/kubernetes"
+	v1listers "k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	defaultCostNodeLabel = "beta.kubernetes.io/instance-type"
+	bytesPerGiB          = 1 << 30
+)
+
+// CostProcessor reports the hourly cost of the pods and nodes based on the prices of the node resources.
+// The cost of a pod is allocated by the max of its request and usage of each resource. Needs to run after
+// the rate_calculator and pod_aggregator, and before the aggregators rolling the pod costs up.
+type CostProcessor struct {
+	nodeLister    v1listers.NodeLister
+	nodeLabel     string
+	prices        map[string]configuration.CostPrices
+	defaultPrices configuration.CostPrices
+}
+
+func (cp *CostProcessor) Name() string {
+	return "cost"
+}
+
+func (cp *CostProcessor) Process(batch *metrics.DataBatch) (*metrics.DataBatch, error) {
+	nodes := make(map[string]*kube_api.Node)
+	allocated := make(map[string]float64)
+	for key, metricSet := range batch.MetricSets {
+		if metricSetType, found := metricSet.Labels[metrics.LabelMetricSetType.Key]; !found || metricSetType != metrics.MetricSetTypePod {
+			continue
+		}
+		nodeName := metricSet.Labels[metrics.LabelNodename.Key]
+		if nodeName == "" {
+			log.Debugf("Skipping cost of pod %s: no node info", key)
+			continue
+		}
+		node := cp.node(nodes, nodeName)
+		if node == nil {
+			continue
+		}
+		prices := cp.nodePrices(node)
+
+		cpu := allocation(metricSet, &metrics.MetricCpuRequest, &metrics.MetricCpuUsageRate) / 1000 * prices.Cpu
+		memory := allocation(metricSet, &metrics.MetricMemoryRequest, &metrics.MetricMemoryWorkingSet) / bytesPerGiB * prices.Memory
+		storage := allocation(metricSet, &metrics.MetricEphemeralStorageRequest, &metrics.MetricEphemeralStorageUsage) / bytesPerGiB * prices.Storage
+		total := cpu + memory + storage
+
+		setFloat(metricSet, &metrics.MetricCostCpu, cpu)
+		setFloat(metricSet, &metrics.MetricCostMemory, memory)
+		setFloat(metricSet, &metrics.MetricCostStorage, storage)
+		setFloat(metricSet, &metrics.MetricCostTotal, total)
+		allocated[nodeName] += total
+	}
+
+	for _, metricSet := range batch.MetricSets {
+		if metricSetType, found := metricSet.Labels[metrics.LabelMetricSetType.Key]; !found || metricSetType != metrics.MetricSetTypeNode {
+			continue
+		}
+		nodeName := metricSet.Labels[metrics.LabelNodename.Key]
+		node := cp.node(nodes, nodeName)
+		if node == nil {
+			continue
+		}
+		prices := cp.nodePrices(node)
+		cpu := node.Status.Capacity[kube_api.ResourceCPU]
+		memory := node.Status.Capacity[kube_api.ResourceMemory]
+		storage := node.Status.Capacity[kube_api.ResourceEphemeralStorage]
+
+		total := float64(cpu.MilliValue())/1000*prices.Cpu +
+			float64(memory.Value())/bytesPerGiB*prices.Memory +
+			float64(storage.Value())/bytesPerGiB*prices.Storage
+		setFloat(metricSet, &metrics.MetricCostNodeTotal, total)
+		setFloat(metricSet, &metrics.MetricCostNodeIdle, math.Max(total-allocated[nodeName], 0))
+	}
+	return batch, nil
+}
+
+// node returns the node from the lister, caching the lookups of a batch in nodes.
+func (cp *CostProcessor) node(nodes map[string]*kube_api.Node, nodeName string) *kube_api.Node {
+	if node, found := nodes[nodeName]; found {
+		return node
+	}
+	node, err := cp.nodeLister.Get(nodeName)
+	if err != nil {
+		log.Debugf("Failed to get node %s from cache: %v", nodeName, err)
+		node = nil
+	}
+	nodes[nodeName] = node
+	return node
+}
+
+func (cp *CostProcessor) nodePrices(node *kube_api.Node) configuration.CostPrices {
+	if prices, found := cp.prices[node.Labels[cp.nodeLabel]]; found {
+		return prices
+	}
+	return cp.defaultPrices
+}
+
+// allocation returns the max of the requested and used amount of a resource.
+func allocation(metricSet *metrics.MetricSet, request, usage *metrics.Metric) float64 {
+	return math.Max(float64(getInt(metricSet, request)), float64(getInt(metricSet, usage)))
+}
+
+func NewCostProcessor(kubeClient *kube_client.Clientset, cfg configuration.CostConfig) (*CostProcessor, error) {
+	nodeLister, _, err := util.GetNodeLister(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	return &CostProcessor{
+		nodeLister:    nodeLister,
+		nodeLabel:     configuration.GetStringValue(cfg.NodeLabel, defaultCostNodeLabel),
+		prices:        cfg.Prices,
+		defaultPrices: cfg.DefaultPrices,
+	}, nil
+}
@@ -0,0 +1,103 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+
+	kube_api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func costNode(name, instanceType string) *kube_api.Node {
+	return &kube_api.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{defaultCostNodeLabel: instanceType},
+		},
+		Status: kube_api.NodeStatus{
+			Capacity: kube_api.ResourceList{
+				kube_api.ResourceCPU:    resource.MustParse("4"),
+				kube_api.ResourceMemory: resource.MustParse("16Gi"),
+			},
+		},
+	}
+}
+
+func costPodMetricSet(node string, cpuRequest, cpuUsage, memoryRequest, memoryUsage int64) *metrics.MetricSet {
+	value := func(v int64) metrics.MetricValue {
+		return metrics.MetricValue{ValueType: metrics.ValueInt64, MetricType: metrics.MetricGauge, IntValue: v}
+	}
+	return &metrics.MetricSet{
+		Labels: map[string]string{
+			metrics.LabelMetricSetType.Key: metrics.MetricSetTypePod,
+			metrics.LabelNodename.Key:      node,
+		},
+		MetricValues: map[string]metrics.MetricValue{
+			metrics.MetricCpuRequest.Name:       value(cpuRequest),
+			metrics.MetricCpuUsageRate.Name:     value(cpuUsage),
+			metrics.MetricMemoryRequest.Name:    value(memoryRequest),
+			metrics.MetricMemoryWorkingSet.Name: value(memoryUsage),
+		},
+	}
+}
+
+func TestCostProcessor(t *testing.T) {
+	nodeStore := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, nodeStore.Add(costNode("node1", "m5.xlarge")))
+	require.NoError(t, nodeStore.Add(costNode("node2", "unknown")))
+
+	processor := &CostProcessor{
+		nodeLister: v1listers.NewNodeLister(nodeStore),
+		nodeLabel:  defaultCostNodeLabel,
+		prices: map[string]configuration.CostPrices{
+			"m5.xlarge": {Cpu: 0.04, Memory: 0.005},
+		},
+		defaultPrices: configuration.CostPrices{Cpu: 0.02, Memory: 0.0025},
+	}
+
+	batch := &metrics.DataBatch{
+		Timestamp: time.Now(),
+		MetricSets: map[string]*metrics.MetricSet{
+			// cpu allocated by usage, memory by request
+			metrics.PodKey("ns1", "pod1"): costPodMetricSet("node1", 500, 1000, 2*bytesPerGiB, bytesPerGiB),
+			metrics.PodKey("ns1", "pod2"): costPodMetricSet("node2", 2000, 100, 0, 4*bytesPerGiB),
+			metrics.PodKey("ns1", "pod3"): costPodMetricSet("node3", 1000, 1000, 0, 0),
+			metrics.NodeKey("node1"): {
+				Labels: map[string]string{
+					metrics.LabelMetricSetType.Key: metrics.MetricSetTypeNode,
+					metrics.LabelNodename.Key:      "node1",
+				},
+				MetricValues: map[string]metrics.MetricValue{},
+			},
+		},
+	}
+	_, err := processor.Process(batch)
+	require.NoError(t, err)
+
+	pod1 := batch.MetricSets[metrics.PodKey("ns1", "pod1")]
+	assert.InDelta(t, 0.04, pod1.MetricValues[metrics.MetricCostCpu.Name].FloatValue, 1e-9)
+	assert.InDelta(t, 0.01, pod1.MetricValues[metrics.MetricCostMemory.Name].FloatValue, 1e-9)
+	assert.InDelta(t, 0.0, pod1.MetricValues[metrics.MetricCostStorage.Name].FloatValue, 1e-9)
+	assert.InDelta(t, 0.05, pod1.MetricValues[metrics.MetricCostTotal.Name].FloatValue, 1e-9)
+
+	pod2 := batch.MetricSets[metrics.PodKey("ns1", "pod2")]
+	assert.InDelta(t, 0.04, pod2.MetricValues[metrics.MetricCostCpu.Name].FloatValue, 1e-9)
+	assert.InDelta(t, 0.01, pod2.MetricValues[metrics.MetricCostMemory.Name].FloatValue, 1e-9)
+
+	// unknown node
+	_, found := batch.MetricSets[metrics.PodKey("ns1", "pod3")].MetricValues[metrics.MetricCostTotal.Name]
+	assert.False(t, found)
+
+	node1 := batch.MetricSets[metrics.NodeKey("node1")]
+	assert.InDelta(t, 0.24, node1.MetricValues[metrics.MetricCostNodeTotal.Name].FloatValue, 1e-9)
+	assert.InDelta(t, 0.19, node1.MetricValues[metrics.MetricCostNodeIdle.Name].FloatValue, 1e-9)
+}
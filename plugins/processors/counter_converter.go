@@ -0,0 +1,165 @@
+package processors
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gobwas/glob"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	RateMode  = "rate"
+	DeltaMode = "delta"
+
+	// Wavefront treats metrics prefixed with this character as delta counters
+	deltaPrefix     = "∆"
+	rateSuffix      = ".rate"
+	defaultStaleAge = 10 * time.Minute
+)
+
+type counterValue struct {
+	value     float64
+	timestamp int64
+	lastSeen  time.Time
+}
+
+// CounterConverter converts cumulative counter points into per second rates or Wavefront delta counters.
+// The previous value of every series is tracked across batches. Series are identified by their metric name,
+// source and tags and are evicted once they have not been reported for the configured stale age.
+type CounterConverter struct {
+	metrics      glob.Glob
+	mode         string
+	staleAge     time.Duration
+	keepOriginal bool
+	previous     map[string]counterValue
+}
+
+func (cc *CounterConverter) Name() string {
+	return "counter converter"
+}
+
+func (cc *CounterConverter) Process(batch *metrics.DataBatch) (*metrics.DataBatch, error) {
+	now := batch.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	result := make([]*metrics.MetricPoint, 0, len(batch.MetricPoints))
+	for _, point := range batch.MetricPoints {
+		if !cc.metrics.Match(point.Metric) {
+			result = append(result, point)
+			continue
+		}
+		if cc.keepOriginal {
+			result = append(result, point)
+		}
+		if converted := cc.convert(point, now); converted != nil {
+			result = append(result, converted)
+		}
+	}
+	batch.MetricPoints = result
+
+	cc.evict(now)
+	return batch, nil
+}
+
+// ProcessesPoints signals that the converter operates on batches that only contain metric points.
+func (cc *CounterConverter) ProcessesPoints() bool {
+	return true
+}
+
+func (cc *CounterConverter) convert(point *metrics.MetricPoint, now time.Time) *metrics.MetricPoint {
+	key := seriesKey(point)
+	prev, found := cc.previous[key]
+	if found && point.Timestamp <= prev.timestamp {
+		log.Debugf("Skipping conversion of '%s' - point is not newer than the previous point", point.Metric)
+		return nil
+	}
+	cc.previous[key] = counterValue{value: point.Value, timestamp: point.Timestamp, lastSeen: now}
+	if !found {
+		return nil
+	}
+
+	delta := point.Value - prev.value
+	if delta < 0 {
+		// counter reset: the current value accumulated since the restart
+		delta = point.Value
+	}
+
+	converted := &metrics.MetricPoint{
+		Timestamp: point.Timestamp,
+		Source:    point.Source,
+		Tags:      point.Tags,
+		StrTags:   point.StrTags,
+	}
+	if cc.mode == DeltaMode {
+		if delta <= 0 {
+			// delta counters only accept positive increments
+			return nil
+		}
+		converted.Metric = deltaPrefix + point.Metric
+		converted.Value = delta
+	} else {
+		converted.Metric = point.Metric + rateSuffix
+		converted.Value = delta * 1000 / float64(point.Timestamp-prev.timestamp)
+	}
+	return converted
+}
+
+func (cc *CounterConverter) evict(now time.Time) {
+	for key, prev := range cc.previous {
+		if now.Sub(prev.lastSeen) > cc.staleAge {
+			delete(cc.previous, key)
+		}
+	}
+}
+
+func seriesKey(point *metrics.MetricPoint) string {
+	buf := bytes.NewBufferString(point.Metric)
+	buf.WriteString("|")
+	buf.WriteString(point.Source)
+	buf.WriteString("|")
+	buf.WriteString(point.StrTags)
+	keys := make([]string, 0, len(point.Tags))
+	for k := range point.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf.WriteString(" ")
+		buf.WriteString(k)
+		buf.WriteString("=")
+		buf.WriteString(point.Tags[k])
+	}
+	return buf.String()
+}
+
+func NewCounterConverter(cfg configuration.CounterConverterConfig) (*CounterConverter, error) {
+	if len(cfg.Metrics) == 0 {
+		return nil, fmt.Errorf("counter converter requires at least one metric pattern")
+	}
+	for _, pattern := range cfg.Metrics {
+		if _, err := glob.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid metric pattern %s: %v", pattern, err)
+		}
+	}
+	mode := configuration.GetStringValue(cfg.Mode, RateMode)
+	if mode != RateMode && mode != DeltaMode {
+		return nil, fmt.Errorf("invalid counter converter mode: %s", mode)
+	}
+	return &CounterConverter{
+		metrics:      filter.Compile(cfg.Metrics),
+		mode:         mode,
+		staleAge:     configuration.GetDurationValue(cfg.StaleAge, defaultStaleAge),
+		keepOriginal: cfg.KeepOriginal,
+		previous:     make(map[string]counterValue),
+	}, nil
+}
@@ -0,0 +1,105 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+func counterBatch(now time.Time, values ...float64) *metrics.DataBatch {
+	batch := &metrics.DataBatch{Timestamp: now}
+	for i, v := range values {
+		batch.MetricPoints = append(batch.MetricPoints, &metrics.MetricPoint{
+			Metric:    "requests.total.counter",
+			Value:     v,
+			Timestamp: metrics.UnixMillis(now),
+			Source:    "node1",
+			StrTags:   []string{" code=200", " code=500"}[i],
+		})
+	}
+	batch.MetricPoints = append(batch.MetricPoints, &metrics.MetricPoint{
+		Metric:    "temperature.gauge",
+		Value:     21,
+		Timestamp: metrics.UnixMillis(now),
+		Source:    "node1",
+	})
+	return batch
+}
+
+func TestCounterConverterRate(t *testing.T) {
+	cc, err := NewCounterConverter(configuration.CounterConverterConfig{Metrics: []string{"*.counter"}})
+	require.NoError(t, err)
+	now := time.Now()
+
+	batch, err := cc.Process(counterBatch(now, 100, 10))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(batch.MetricPoints))
+	assert.Equal(t, "temperature.gauge", batch.MetricPoints[0].Metric)
+
+	batch, err = cc.Process(counterBatch(now.Add(10*time.Second), 160, 5))
+	require.NoError(t, err)
+	require.Equal(t, 3, len(batch.MetricPoints))
+	assert.Equal(t, "requests.total.counter.rate", batch.MetricPoints[0].Metric)
+	assert.Equal(t, 6.0, batch.MetricPoints[0].Value)
+	assert.Equal(t, " code=200", batch.MetricPoints[0].StrTags)
+	// counter reset
+	assert.Equal(t, 0.5, batch.MetricPoints[1].Value)
+	assert.Equal(t, " code=500", batch.MetricPoints[1].StrTags)
+}
+
+func TestCounterConverterDelta(t *testing.T) {
+	cc, err := NewCounterConverter(configuration.CounterConverterConfig{
+		Metrics:      []string{"requests.*"},
+		Mode:         DeltaMode,
+		KeepOriginal: true,
+	})
+	require.NoError(t, err)
+	now := time.Now()
+
+	batch, err := cc.Process(counterBatch(now, 100, 10))
+	require.NoError(t, err)
+	assert.Equal(t, 3, len(batch.MetricPoints))
+
+	batch, err = cc.Process(counterBatch(now.Add(time.Minute), 160, 10))
+	require.NoError(t, err)
+	require.Equal(t, 4, len(batch.MetricPoints))
+	assert.Equal(t, "requests.total.counter", batch.MetricPoints[0].Metric)
+	assert.Equal(t, "∆requests.total.counter", batch.MetricPoints[1].Metric)
+	assert.Equal(t, 60.0, batch.MetricPoints[1].Value)
+	// unchanged counters are not sent as deltas
+	assert.Equal(t, "requests.total.counter", batch.MetricPoints[2].Metric)
+}
+
+func TestCounterConverterEviction(t *testing.T) {
+	cc, err := NewCounterConverter(configuration.CounterConverterConfig{
+		Metrics:  []string{"*.counter"},
+		StaleAge: time.Minute,
+	})
+	require.NoError(t, err)
+	now := time.Now()
+
+	_, err = cc.Process(counterBatch(now, 100, 10))
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(cc.previous))
+
+	_, err = cc.Process(counterBatch(now.Add(30*time.Second), 110))
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(cc.previous))
+
+	_, err = cc.Process(counterBatch(now.Add(90 * time.Second)))
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(cc.previous))
+}
+
+func TestCounterConverterInvalidConfig(t *testing.T) {
+	_, err := NewCounterConverter(configuration.CounterConverterConfig{})
+	assert.Error(t, err)
+
+	_, err = NewCounterConverter(configuration.CounterConverterConfig{Metrics: []string{"*"}, Mode: "foo"})
+	assert.Error(t, err)
+}
@@ -16,7 +16,12 @@ package processors
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
 )
 
@@ -46,3 +51,162 @@ func aggregate(src, dst *metrics.MetricSet, metricsToAggregate []string) error {
 	}
 	return nil
 }
+
+// Functions supported by the aggregators in addition to percentiles of the form p<number>, such as p95.
+const (
+	AggregationSum   = "sum"
+	AggregationMin   = "min"
+	AggregationMax   = "max"
+	AggregationAvg   = "avg"
+	AggregationCount = "count"
+)
+
+// Aggregation applies a function to the values of a metric across the child metric sets,
+// for example the 95th percentile of cpu/usage_rate across the pods of a namespace.
+type Aggregation struct {
+	Metric     string
+	Function   string
+	percentile float64
+}
+
+// Name returns the name of the aggregated metric, the metric name suffixed with the function.
+func (a Aggregation) Name() string {
+	return a.Metric + "." + a.Function
+}
+
+// NewAggregations validates the configured aggregations and returns one Aggregation per metric and function.
+func NewAggregations(cfgs []configuration.AggregationConfig) ([]Aggregation, error) {
+	var result []Aggregation
+	for _, cfg := range cfgs {
+		if cfg.Metric == "" {
+			return nil, fmt.Errorf("aggregation metric is missing")
+		}
+		for _, function := range cfg.Functions {
+			aggregation := Aggregation{Metric: cfg.Metric, Function: function}
+			switch function {
+			case AggregationSum, AggregationMin, AggregationMax, AggregationAvg, AggregationCount:
+			default:
+				if !strings.HasPrefix(function, "p") {
+					return nil, fmt.Errorf("unknown aggregation function %s for metric %s", function, cfg.Metric)
+				}
+				percentile, err := strconv.ParseFloat(function[1:], 64)
+				if err != nil || percentile <= 0 || percentile > 100 {
+					return nil, fmt.Errorf("invalid percentile %s for metric %s", function, cfg.Metric)
+				}
+				aggregation.percentile = percentile
+			}
+			result = append(result, aggregation)
+		}
+	}
+	return result, nil
+}
+
+// aggregationValues collects the values of the metrics of the child metric sets per aggregated
+// metric set, so that the aggregation functions can be applied once all the values are known.
+type aggregationValues struct {
+	aggregations []Aggregation
+	metrics      []string
+	values       map[*metrics.MetricSet]map[string][]float64
+}
+
+func newAggregationValues(aggregations []Aggregation) *aggregationValues {
+	// the same metric may be listed for several functions, its values are only collected once
+	var names []string
+	seen := make(map[string]bool)
+	for _, aggregation := range aggregations {
+		if !seen[aggregation.Metric] {
+			seen[aggregation.Metric] = true
+			names = append(names, aggregation.Metric)
+		}
+	}
+	return &aggregationValues{
+		aggregations: aggregations,
+		metrics:      names,
+		values:       make(map[*metrics.MetricSet]map[string][]float64),
+	}
+}
+
+func (av *aggregationValues) collect(src, dst *metrics.MetricSet) {
+	for _, metricName := range av.metrics {
+		metricValue, found := src.MetricValues[metricName]
+		if !found {
+			continue
+		}
+		values, found := av.values[dst]
+		if !found {
+			values = make(map[string][]float64)
+			av.values[dst] = values
+		}
+		values[metricName] = append(values[metricName], floatValue(metricValue))
+	}
+}
+
+// apply computes the aggregation functions and adds the results to the aggregated metric sets.
+func (av *aggregationValues) apply() {
+	for dst, values := range av.values {
+		for _, aggregation := range av.aggregations {
+			metricValues, found := values[aggregation.Metric]
+			if !found || len(metricValues) == 0 {
+				continue
+			}
+			dst.MetricValues[aggregation.Name()] = aggregation.apply(metricValues)
+		}
+	}
+}
+
+func (a Aggregation) apply(values []float64) metrics.MetricValue {
+	if a.Function == AggregationCount {
+		return metrics.MetricValue{
+			ValueType:  metrics.ValueInt64,
+			MetricType: metrics.MetricGauge,
+			IntValue:   int64(len(values)),
+		}
+	}
+
+	var result float64
+	switch a.Function {
+	case AggregationSum, AggregationAvg:
+		for _, value := range values {
+			result += value
+		}
+		if a.Function == AggregationAvg {
+			result /= float64(len(values))
+		}
+	case AggregationMin:
+		result = values[0]
+		for _, value := range values[1:] {
+			result = math.Min(result, value)
+		}
+	case AggregationMax:
+		result = values[0]
+		for _, value := range values[1:] {
+			result = math.Max(result, value)
+		}
+	default:
+		result = percentile(values, a.percentile)
+	}
+	return metrics.MetricValue{
+		ValueType:  metrics.ValueFloat,
+		MetricType: metrics.MetricGauge,
+		FloatValue: result,
+	}
+}
+
+// percentile returns the nearest rank percentile of the values.
+func percentile(values []float64, p float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func floatValue(value metrics.MetricValue) float64 {
+	if value.ValueType == metrics.ValueInt64 {
+		return float64(value.IntValue)
+	}
+	return value.FloatValue
+}
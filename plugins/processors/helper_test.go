@@ -0,0 +1,69 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+func TestNewAggregations(t *testing.T) {
+	aggregations, err := NewAggregations([]configuration.AggregationConfig{
+		{Metric: "cpu/usage_rate", Functions: []string{"max", "p95"}},
+		{Metric: "memory/usage", Functions: []string{"avg"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, aggregations, 3)
+	assert.Equal(t, "cpu/usage_rate.max", aggregations[0].Name())
+	assert.Equal(t, "cpu/usage_rate.p95", aggregations[1].Name())
+	assert.Equal(t, float64(95), aggregations[1].percentile)
+	assert.Equal(t, "memory/usage.avg", aggregations[2].Name())
+
+	for _, function := range []string{"median", "p0", "p101", "pxx"} {
+		_, err = NewAggregations([]configuration.AggregationConfig{{Metric: "m1", Functions: []string{function}}})
+		assert.Error(t, err, function)
+	}
+	_, err = NewAggregations([]configuration.AggregationConfig{{Functions: []string{"max"}}})
+	assert.Error(t, err)
+}
+
+func TestAggregationFunctions(t *testing.T) {
+	aggregations, err := NewAggregations([]configuration.AggregationConfig{
+		{Metric: "m1", Functions: []string{"sum", "min", "max", "avg", "count", "p50", "p95", "p99"}},
+	})
+	require.NoError(t, err)
+
+	dst := &metrics.MetricSet{MetricValues: map[string]metrics.MetricValue{}}
+	values := newAggregationValues(aggregations)
+	for i := 1; i <= 20; i++ {
+		src := &metrics.MetricSet{MetricValues: map[string]metrics.MetricValue{
+			"m1": {ValueType: metrics.ValueInt64, MetricType: metrics.MetricGauge, IntValue: int64(i)},
+		}}
+		values.collect(src, dst)
+	}
+	// sets without the metric are ignored
+	values.collect(&metrics.MetricSet{MetricValues: map[string]metrics.MetricValue{}}, dst)
+	values.apply()
+
+	expected := map[string]float64{
+		"m1.sum": 210,
+		"m1.min": 1,
+		"m1.max": 20,
+		"m1.avg": 10.5,
+		"m1.p50": 10,
+		"m1.p95": 19,
+		"m1.p99": 20,
+	}
+	for name, value := range expected {
+		metricValue, found := dst.MetricValues[name]
+		require.True(t, found, name)
+		assert.Equal(t, metrics.ValueFloat, metricValue.ValueType, name)
+		assert.Equal(t, value, metricValue.FloatValue, name)
+	}
+	count := dst.MetricValues["m1.count"]
+	assert.Equal(t, metrics.ValueInt64, count.ValueType)
+	assert.Equal(t, int64(20), count.IntValue)
+}
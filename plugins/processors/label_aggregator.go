@@ -0,0 +1,124 @@
+package processors
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
+)
+
+// prefix of the group by names referring to pod labels, matching the tags added by the point converter
+const podLabelPrefix = "label."
+
+// LabelAggregator rolls up the pod or pod container metrics to groups of metric sets that share the
+// values of the configured labels, for example all the pods labeled with the same team and env.
+type LabelAggregator struct {
+	MetricsToAggregate []string
+	Aggregations       []Aggregation
+	groupBy            []string
+	metricSetType      string
+	labelCopier        *util.LabelCopier
+}
+
+func (aggregator *LabelAggregator) Name() string {
+	return "label_aggregator"
+}
+
+func (aggregator *LabelAggregator) Process(batch *metrics.DataBatch) (*metrics.DataBatch, error) {
+	groups := make(map[string]*metrics.MetricSet)
+	values := newAggregationValues(aggregator.Aggregations)
+	for key, metricSet := range batch.MetricSets {
+		if metricSetType, found := metricSet.Labels[metrics.LabelMetricSetType.Key]; !found || metricSetType != aggregator.metricSetType {
+			continue
+		}
+
+		groupLabels, found := aggregator.groupLabels(metricSet)
+		if !found {
+			log.Tracef("Skipping %s: missing group labels %v", key, aggregator.groupBy)
+			continue
+		}
+
+		groupKey := metrics.GroupKey(aggregator.groupBy, groupLabels)
+		group, found := groups[groupKey]
+		if !found {
+			group = aggregator.groupMetricSet(groupLabels)
+			groups[groupKey] = group
+		}
+
+		if err := aggregate(metricSet, group, aggregator.MetricsToAggregate); err != nil {
+			return nil, err
+		}
+		values.collect(metricSet, group)
+	}
+	values.apply()
+	for key, val := range groups {
+		batch.MetricSets[key] = val
+	}
+	return batch, nil
+}
+
+// groupLabels returns the values of the group by labels of the metric set. Returns false if any of them is missing.
+func (aggregator *LabelAggregator) groupLabels(metricSet *metrics.MetricSet) (map[string]string, bool) {
+	var podLabels map[string]string
+	result := make(map[string]string, len(aggregator.groupBy))
+	for _, name := range aggregator.groupBy {
+		var value string
+		if strings.HasPrefix(name, podLabelPrefix) {
+			if podLabels == nil {
+				podLabels = aggregator.labelCopier.Labels(metricSet.Labels)
+			}
+			value = podLabels[strings.TrimPrefix(name, podLabelPrefix)]
+		} else {
+			value = metricSet.Labels[name]
+		}
+		if value == "" {
+			return nil, false
+		}
+		result[name] = value
+	}
+	return result, true
+}
+
+func (aggregator *LabelAggregator) groupMetricSet(groupLabels map[string]string) *metrics.MetricSet {
+	group := &metrics.MetricSet{
+		MetricValues: make(map[string]metrics.MetricValue),
+		Labels: map[string]string{
+			metrics.LabelMetricSetType.Key: metrics.MetricSetTypeGroup,
+		},
+	}
+	podLabels := make(map[string]string)
+	for name, value := range groupLabels {
+		if strings.HasPrefix(name, podLabelPrefix) {
+			podLabels[strings.TrimPrefix(name, podLabelPrefix)] = value
+		} else {
+			group.Labels[name] = value
+		}
+	}
+	if len(podLabels) > 0 {
+		aggregator.labelCopier.Copy(podLabels, group.Labels)
+	}
+	return group
+}
+
+func NewLabelAggregator(cfg configuration.LabelAggregatorConfig, metricsToAggregate []string, aggregations []Aggregation,
+	labelCopier *util.LabelCopier) (*LabelAggregator, error) {
+
+	if len(cfg.GroupBy) == 0 {
+		return nil, fmt.Errorf("label aggregator requires at least one group by label")
+	}
+	metricSetType := configuration.GetStringValue(cfg.Type, metrics.MetricSetTypePod)
+	if metricSetType != metrics.MetricSetTypePod && metricSetType != metrics.MetricSetTypePodContainer {
+		return nil, fmt.Errorf("invalid label aggregator type: %s", metricSetType)
+	}
+	return &LabelAggregator{
+		MetricsToAggregate: metricsToAggregate,
+		Aggregations:       aggregations,
+		groupBy:            cfg.GroupBy,
+		metricSetType:      metricSetType,
+		labelCopier:        labelCopier,
+	}, nil
+}
@@ -0,0 +1,104 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
+)
+
+func labeledPodMetricSet(labelCopier *util.LabelCopier, namespace string, labels map[string]string, cpu int64) *metrics.MetricSet {
+	ms := &metrics.MetricSet{
+		Labels: map[string]string{
+			metrics.LabelMetricSetType.Key: metrics.MetricSetTypePod,
+			metrics.LabelNamespaceName.Key: namespace,
+		},
+		MetricValues: map[string]metrics.MetricValue{
+			metrics.MetricCpuUsageRate.Name: {ValueType: metrics.ValueInt64, MetricType: metrics.MetricGauge, IntValue: cpu},
+		},
+	}
+	labelCopier.Copy(labels, ms.Labels)
+	return ms
+}
+
+func TestLabelAggregator(t *testing.T) {
+	labelCopier, err := util.NewLabelCopier(",", []string{}, []string{})
+	require.NoError(t, err)
+
+	batch := metrics.DataBatch{
+		Timestamp: time.Now(),
+		MetricSets: map[string]*metrics.MetricSet{
+			metrics.PodKey("ns1", "pod1"): labeledPodMetricSet(labelCopier, "ns1", map[string]string{"team": "a", "env": "prod"}, 100),
+			metrics.PodKey("ns2", "pod2"): labeledPodMetricSet(labelCopier, "ns2", map[string]string{"team": "a", "env": "prod", "app": "web"}, 300),
+			metrics.PodKey("ns1", "pod3"): labeledPodMetricSet(labelCopier, "ns1", map[string]string{"team": "a", "env": "dev"}, 50),
+			metrics.PodKey("ns1", "pod4"): labeledPodMetricSet(labelCopier, "ns1", map[string]string{"env": "prod"}, 1000),
+		},
+	}
+
+	aggregator, err := NewLabelAggregator(configuration.LabelAggregatorConfig{GroupBy: []string{"label.team", "label.env"}},
+		[]string{metrics.MetricCpuUsageRate.Name},
+		[]Aggregation{{Metric: metrics.MetricCpuUsageRate.Name, Function: AggregationMax}},
+		labelCopier)
+	require.NoError(t, err)
+
+	processed, err := aggregator.Process(&batch)
+	require.NoError(t, err)
+
+	groups := 0
+	for _, ms := range processed.MetricSets {
+		if ms.Labels[metrics.LabelMetricSetType.Key] == metrics.MetricSetTypeGroup {
+			groups++
+		}
+	}
+	// pod4 has no team label and is not aggregated
+	assert.Equal(t, 2, groups)
+
+	prod, found := processed.MetricSets[metrics.GroupKey(aggregator.groupBy, map[string]string{"label.team": "a", "label.env": "prod"})]
+	require.True(t, found)
+	assert.Equal(t, "env:prod,team:a", prod.Labels[metrics.LabelLabels.Key])
+	assert.Equal(t, int64(400), prod.MetricValues[metrics.MetricCpuUsageRate.Name].IntValue)
+	assert.Equal(t, float64(300), prod.MetricValues[metrics.MetricCpuUsageRate.Name+".max"].FloatValue)
+
+	dev, found := processed.MetricSets[metrics.GroupKey(aggregator.groupBy, map[string]string{"label.team": "a", "label.env": "dev"})]
+	require.True(t, found)
+	assert.Equal(t, int64(50), dev.MetricValues[metrics.MetricCpuUsageRate.Name].IntValue)
+}
+
+func TestLabelAggregatorMetricSetLabels(t *testing.T) {
+	labelCopier, err := util.NewLabelCopier(",", []string{}, []string{})
+	require.NoError(t, err)
+
+	batch := metrics.DataBatch{
+		Timestamp: time.Now(),
+		MetricSets: map[string]*metrics.MetricSet{
+			metrics.PodKey("ns1", "pod1"): labeledPodMetricSet(labelCopier, "ns1", map[string]string{"team": "a"}, 100),
+			metrics.PodKey("ns2", "pod2"): labeledPodMetricSet(labelCopier, "ns2", map[string]string{"team": "a"}, 300),
+		},
+	}
+
+	aggregator, err := NewLabelAggregator(configuration.LabelAggregatorConfig{GroupBy: []string{"namespace_name", "label.team"}},
+		[]string{metrics.MetricCpuUsageRate.Name}, nil, labelCopier)
+	require.NoError(t, err)
+
+	processed, err := aggregator.Process(&batch)
+	require.NoError(t, err)
+
+	group, found := processed.MetricSets[metrics.GroupKey(aggregator.groupBy, map[string]string{"namespace_name": "ns2", "label.team": "a"})]
+	require.True(t, found)
+	assert.Equal(t, "ns2", group.Labels[metrics.LabelNamespaceName.Key])
+	assert.Equal(t, "team:a", group.Labels[metrics.LabelLabels.Key])
+	assert.Equal(t, int64(300), group.MetricValues[metrics.MetricCpuUsageRate.Name].IntValue)
+}
+
+func TestNewLabelAggregatorInvalid(t *testing.T) {
+	_, err := NewLabelAggregator(configuration.LabelAggregatorConfig{}, nil, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewLabelAggregator(configuration.LabelAggregatorConfig{GroupBy: []string{"label.team"}, Type: "node"}, nil, nil, nil)
+	assert.Error(t, err)
+}
@@ -22,6 +22,7 @@ import (
 
 type NamespaceAggregator struct {
 	MetricsToAggregate []string
+	Aggregations       []Aggregation
 }
 
 func (aggregator *NamespaceAggregator) Name() string {
@@ -30,6 +31,7 @@ func (aggregator *NamespaceAggregator) Name() string {
 
 func (aggregator *NamespaceAggregator) Process(batch *metrics.DataBatch) (*metrics.DataBatch, error) {
 	namespaces := make(map[string]*metrics.MetricSet)
+	values := newAggregationValues(aggregator.Aggregations)
 	for key, metricSet := range batch.MetricSets {
 		if metricSetType, found := metricSet.Labels[metrics.LabelMetricSetType.Key]; !found || metricSetType != metrics.MetricSetTypePod {
 			continue
@@ -55,8 +57,9 @@ func (aggregator *NamespaceAggregator) Process(batch *metrics.DataBatch) (*metri
 		if err := aggregate(metricSet, namespace, aggregator.MetricsToAggregate); err != nil {
 			return nil, err
 		}
-
+		values.collect(metricSet, namespace)
 	}
+	values.apply()
 	for key, val := range namespaces {
 		batch.MetricSets[key] = val
 	}
@@ -68,6 +68,11 @@ func TestNamespaceAggregate(t *testing.T) {
 	}
 	processor := NamespaceAggregator{
 		MetricsToAggregate: []string{"m1", "m3"},
+		Aggregations: []Aggregation{
+			{Metric: "m1", Function: AggregationMax},
+			{Metric: "m1", Function: "p50", percentile: 50},
+			{Metric: "m2", Function: AggregationCount},
+		},
 	}
 	result, err := processor.Process(&batch)
 	assert.NoError(t, err)
@@ -81,4 +86,16 @@ func TestNamespaceAggregate(t *testing.T) {
 	m3, found := namespace.MetricValues["m3"]
 	assert.True(t, found)
 	assert.Equal(t, int64(30), m3.IntValue)
+
+	m1Max, found := namespace.MetricValues["m1.max"]
+	assert.True(t, found)
+	assert.Equal(t, float64(100), m1Max.FloatValue)
+
+	p50, found := namespace.MetricValues["m1.p50"]
+	assert.True(t, found)
+	assert.Equal(t, float64(10), p50.FloatValue)
+
+	count, found := namespace.MetricValues["m2.count"]
+	assert.True(t, found)
+	assert.Equal(t, int64(1), count.IntValue)
 }
@@ -23,6 +23,7 @@ import (
 // Does not add any nodes.
 type NodeAggregator struct {
 	MetricsToAggregate []string
+	Aggregations       []Aggregation
 }
 
 func (aggregator *NodeAggregator) Name() string {
@@ -30,6 +31,7 @@ func (aggregator *NodeAggregator) Name() string {
 }
 
 func (aggregator *NodeAggregator) Process(batch *metrics.DataBatch) (*metrics.DataBatch, error) {
+	values := newAggregationValues(aggregator.Aggregations)
 	for key, metricSet := range batch.MetricSets {
 		if metricSetType, found := metricSet.Labels[metrics.LabelMetricSetType.Key]; !found || metricSetType != metrics.MetricSetTypePod {
 			continue
@@ -48,10 +50,13 @@ func (aggregator *NodeAggregator) Process(batch *metrics.DataBatch) (*metrics.Da
 		node, found := batch.MetricSets[nodeKey]
 		if !found {
 			log.Infof("No metric for node %s, cannot perform node level aggregation.", nodeKey)
-		} else if err := aggregate(metricSet, node, aggregator.MetricsToAggregate); err != nil {
+			continue
+		}
+		if err := aggregate(metricSet, node, aggregator.MetricsToAggregate); err != nil {
 			return nil, err
 		}
-
+		values.collect(metricSet, node)
 	}
+	values.apply()
 	return batch, nil
 }
@@ -0,0 +1,19 @@
+package processors
+
+import (
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+// pointsProcessor wraps a data processor so that it also runs against batches without metric sets.
+type pointsProcessor struct {
+	metrics.DataProcessor
+}
+
+// NewPointsProcessor returns a processor that opts in to batches containing only metric points.
+func NewPointsProcessor(processor metrics.DataProcessor) metrics.DataProcessor {
+	return pointsProcessor{DataProcessor: processor}
+}
+
+func (p pointsProcessor) ProcessesPoints() bool {
+	return true
+}
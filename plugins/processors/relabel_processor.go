@@ -0,0 +1,53 @@
+package processors
+
+import (
+	"fmt"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/relabel"
+)
+
+// RelabelProcessor applies Prometheus style relabeling rules to the metric points and distributions of a batch.
+type RelabelProcessor struct {
+	relabeler *relabel.Relabeler
+}
+
+func (rp *RelabelProcessor) Name() string {
+	return "relabel processor"
+}
+
+func (rp *RelabelProcessor) Process(batch *metrics.DataBatch) (*metrics.DataBatch, error) {
+	result := batch.MetricPoints[:0]
+	for _, point := range batch.MetricPoints {
+		if rp.relabeler.Apply(point) {
+			result = append(result, point)
+		}
+	}
+	batch.MetricPoints = result
+
+	distributions := batch.Distributions[:0]
+	for _, dist := range batch.Distributions {
+		if rp.relabeler.ApplyDistribution(dist) {
+			distributions = append(distributions, dist)
+		}
+	}
+	batch.Distributions = distributions
+	return batch, nil
+}
+
+// ProcessesPoints signals that the relabel processor operates on batches that only contain metric points.
+func (rp *RelabelProcessor) ProcessesPoints() bool {
+	return true
+}
+
+func NewRelabelProcessor(cfg configuration.RelabelProcessorConfig) (*RelabelProcessor, error) {
+	relabeler, err := relabel.FromConfig(cfg.Relabel)
+	if err != nil {
+		return nil, err
+	}
+	if relabeler == nil {
+		return nil, fmt.Errorf("relabel processor requires at least one relabel config")
+	}
+	return &RelabelProcessor{relabeler: relabeler}, nil
+}
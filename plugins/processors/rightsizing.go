@@ -0,0 +1,150 @@
+package processors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+const (
+	defaultRightsizingWindow     = time.Hour
+	defaultRightsizingPercentile = 95
+)
+
+type usageSample struct {
+	timestamp time.Time
+	value     float64
+}
+
+// containerUsage holds the cpu and memory usage samples of a container within the rightsizing window.
+type containerUsage struct {
+	cpu      []usageSample
+	memory   []usageSample
+	lastSeen time.Time
+}
+
+// RightsizingProcessor relates the usage of the pod containers to their requests and limits. It keeps a rolling
+// window of the cpu usage rate and memory working set of each container to recommend requests from a percentile
+// of the observed usage. Needs to run after the rate_calculator and pod_based_enricher.
+type RightsizingProcessor struct {
+	window     time.Duration
+	percentile float64
+	usage      map[string]*containerUsage
+}
+
+func (rp *RightsizingProcessor) Name() string {
+	return "rightsizing"
+}
+
+func (rp *RightsizingProcessor) Process(batch *metrics.DataBatch) (*metrics.DataBatch, error) {
+	cutoff := batch.Timestamp.Add(-rp.window)
+	for key, metricSet := range batch.MetricSets {
+		if metricSetType, found := metricSet.Labels[metrics.LabelMetricSetType.Key]; !found || metricSetType != metrics.MetricSetTypePodContainer {
+			continue
+		}
+
+		usage, found := rp.usage[key]
+		if !found {
+			usage = &containerUsage{}
+			rp.usage[key] = usage
+		}
+		usage.lastSeen = batch.Timestamp
+		if value, found := metricSet.MetricValues[metrics.MetricCpuUsageRate.Name]; found {
+			usage.cpu = appendSample(usage.cpu, batch.Timestamp, floatValue(value), cutoff)
+		}
+		if value, found := metricSet.MetricValues[metrics.MetricMemoryWorkingSet.Name]; found {
+			usage.memory = appendSample(usage.memory, batch.Timestamp, floatValue(value), cutoff)
+		}
+
+		rp.setUtilization(metricSet, &metrics.MetricCpuUsageRate, &metrics.MetricCpuRequest, &metrics.MetricCpuRequestUtilization)
+		rp.setUtilization(metricSet, &metrics.MetricCpuUsageRate, &metrics.MetricCpuLimit, &metrics.MetricCpuLimitUtilization)
+		rp.setUtilization(metricSet, &metrics.MetricMemoryWorkingSet, &metrics.MetricMemoryRequest, &metrics.MetricMemoryRequestUtilization)
+		rp.setUtilization(metricSet, &metrics.MetricMemoryWorkingSet, &metrics.MetricMemoryLimit, &metrics.MetricMemoryLimitUtilization)
+
+		rp.setRecommendation(metricSet, usage.cpu, &metrics.MetricCpuRecommendedRequest)
+		rp.setRecommendation(metricSet, usage.memory, &metrics.MetricMemoryRecommendedRequest)
+
+		setMissing(metricSet, &metrics.MetricCpuRequest, &metrics.MetricMemoryRequest, &metrics.MetricRequestsMissing)
+		setMissing(metricSet, &metrics.MetricCpuLimit, &metrics.MetricMemoryLimit, &metrics.MetricLimitsMissing)
+	}
+
+	// evict the containers that have not been reported within the window
+	for key, usage := range rp.usage {
+		if usage.lastSeen.Before(cutoff) {
+			delete(rp.usage, key)
+		}
+	}
+	return batch, nil
+}
+
+// setUtilization sets the usage as a share of the request or limit, if the container has one.
+func (rp *RightsizingProcessor) setUtilization(metricSet *metrics.MetricSet, usage, bound, target *metrics.Metric) {
+	usageValue, found := metricSet.MetricValues[usage.Name]
+	if !found {
+		return
+	}
+	if boundValue := getInt(metricSet, bound); boundValue > 0 {
+		setFloat(metricSet, target, floatValue(usageValue)/float64(boundValue))
+	}
+}
+
+func (rp *RightsizingProcessor) setRecommendation(metricSet *metrics.MetricSet, samples []usageSample, target *metrics.Metric) {
+	if len(samples) == 0 {
+		return
+	}
+	values := make([]float64, len(samples))
+	for i, sample := range samples {
+		values[i] = sample.value
+	}
+	metricSet.MetricValues[target.Name] = metrics.MetricValue{
+		MetricType: metrics.MetricGauge,
+		ValueType:  metrics.ValueInt64,
+		IntValue:   int64(percentile(values, rp.percentile)),
+	}
+}
+
+// setMissing flags the container if either the cpu or memory value is not set. The pod_based_enricher
+// sets unspecified requests and limits to zero, containers it did not enrich are not flagged.
+func setMissing(metricSet *metrics.MetricSet, cpu, memory, target *metrics.Metric) {
+	cpuValue, cpuFound := metricSet.MetricValues[cpu.Name]
+	memoryValue, memoryFound := metricSet.MetricValues[memory.Name]
+	if !cpuFound || !memoryFound {
+		return
+	}
+	var missing int64
+	if cpuValue.IntValue == 0 || memoryValue.IntValue == 0 {
+		missing = 1
+	}
+	metricSet.MetricValues[target.Name] = metrics.MetricValue{
+		MetricType: metrics.MetricGauge,
+		ValueType:  metrics.ValueInt64,
+		IntValue:   missing,
+	}
+}
+
+// appendSample adds a sample and drops the samples older than the cutoff.
+func appendSample(samples []usageSample, timestamp time.Time, value float64, cutoff time.Time) []usageSample {
+	samples = append(samples, usageSample{timestamp: timestamp, value: value})
+	i := 0
+	for i < len(samples) && samples[i].timestamp.Before(cutoff) {
+		i++
+	}
+	return samples[i:]
+}
+
+func NewRightsizingProcessor(cfg configuration.RightsizingConfig) (*RightsizingProcessor, error) {
+	p := cfg.Percentile
+	if p == 0 {
+		p = defaultRightsizingPercentile
+	}
+	if p < 0 || p > 100 {
+		return nil, fmt.Errorf("invalid rightsizing percentile: %v", cfg.Percentile)
+	}
+	return &RightsizingProcessor{
+		window:     configuration.GetDurationValue(cfg.Window, defaultRightsizingWindow),
+		percentile: p,
+		usage:      make(map[string]*containerUsage),
+	}, nil
+}
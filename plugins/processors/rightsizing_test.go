@@ -0,0 +1,88 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+func rightsizingContainerMetricSet(cpu, memory, cpuRequest, cpuLimit, memoryRequest, memoryLimit int64) *metrics.MetricSet {
+	value := func(v int64) metrics.MetricValue {
+		return metrics.MetricValue{ValueType: metrics.ValueInt64, MetricType: metrics.MetricGauge, IntValue: v}
+	}
+	return &metrics.MetricSet{
+		Labels: map[string]string{
+			metrics.LabelMetricSetType.Key: metrics.MetricSetTypePodContainer,
+		},
+		MetricValues: map[string]metrics.MetricValue{
+			metrics.MetricCpuUsageRate.Name:     value(cpu),
+			metrics.MetricMemoryWorkingSet.Name: value(memory),
+			metrics.MetricCpuRequest.Name:       value(cpuRequest),
+			metrics.MetricCpuLimit.Name:         value(cpuLimit),
+			metrics.MetricMemoryRequest.Name:    value(memoryRequest),
+			metrics.MetricMemoryLimit.Name:      value(memoryLimit),
+		},
+	}
+}
+
+func TestRightsizing(t *testing.T) {
+	processor, err := NewRightsizingProcessor(configuration.RightsizingConfig{Window: 10 * time.Minute, Percentile: 50})
+	require.NoError(t, err)
+
+	key := metrics.PodContainerKey("ns1", "pod1", "c1")
+	now := time.Now()
+	var ms *metrics.MetricSet
+	for i, cpu := range []int64{500, 100, 200, 300} {
+		ms = rightsizingContainerMetricSet(cpu, cpu*1000, 400, 800, 400000, 0)
+		batch := &metrics.DataBatch{
+			Timestamp:  now.Add(time.Duration(i) * 5 * time.Minute),
+			MetricSets: map[string]*metrics.MetricSet{key: ms},
+		}
+		_, err := processor.Process(batch)
+		require.NoError(t, err)
+	}
+
+	// the window only holds the last three samples: 100, 200 and 300
+	assert.Equal(t, int64(200), ms.MetricValues[metrics.MetricCpuRecommendedRequest.Name].IntValue)
+	assert.Equal(t, int64(200000), ms.MetricValues[metrics.MetricMemoryRecommendedRequest.Name].IntValue)
+	assert.Equal(t, 0.75, ms.MetricValues[metrics.MetricCpuRequestUtilization.Name].FloatValue)
+	assert.Equal(t, 0.375, ms.MetricValues[metrics.MetricCpuLimitUtilization.Name].FloatValue)
+	assert.Equal(t, 0.75, ms.MetricValues[metrics.MetricMemoryRequestUtilization.Name].FloatValue)
+	_, found := ms.MetricValues[metrics.MetricMemoryLimitUtilization.Name]
+	assert.False(t, found)
+
+	assert.Equal(t, int64(0), ms.MetricValues[metrics.MetricRequestsMissing.Name].IntValue)
+	assert.Equal(t, int64(1), ms.MetricValues[metrics.MetricLimitsMissing.Name].IntValue)
+}
+
+func TestRightsizingEviction(t *testing.T) {
+	processor, err := NewRightsizingProcessor(configuration.RightsizingConfig{Window: time.Minute})
+	require.NoError(t, err)
+
+	now := time.Now()
+	_, err = processor.Process(&metrics.DataBatch{
+		Timestamp: now,
+		MetricSets: map[string]*metrics.MetricSet{
+			metrics.PodContainerKey("ns1", "pod1", "c1"): rightsizingContainerMetricSet(100, 1000, 0, 0, 0, 0),
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, processor.usage, 1)
+
+	_, err = processor.Process(&metrics.DataBatch{
+		Timestamp:  now.Add(2 * time.Minute),
+		MetricSets: map[string]*metrics.MetricSet{},
+	})
+	require.NoError(t, err)
+	assert.Len(t, processor.usage, 0)
+}
+
+func TestNewRightsizingProcessorInvalid(t *testing.T) {
+	_, err := NewRightsizingProcessor(configuration.RightsizingConfig{Percentile: 101})
+	assert.Error(t, err)
+}
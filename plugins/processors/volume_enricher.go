@@ -0,0 +1,101 @@
+package processors
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
+
+	kube_client "k8s.io/client-go/kubernetes"
+	v1listers "k8s.io/client-go/listers/core/v1"
+)
+
+// VolumeEnricher adds the persistent volume and storage class to the volume metrics of the pods
+// that are labeled with the claim backing the volume.
+type VolumeEnricher struct {
+	pvcLister v1listers.PersistentVolumeClaimLister
+	pvLister  v1listers.PersistentVolumeLister
+}
+
+func (ve *VolumeEnricher) Name() string {
+	return "volume_enricher"
+}
+
+func (ve *VolumeEnricher) Process(batch *metrics.DataBatch) (*metrics.DataBatch, error) {
+	for _, metricSet := range batch.MetricSets {
+		if metricSetType, found := metricSet.Labels[metrics.LabelMetricSetType.Key]; !found || metricSetType != metrics.MetricSetTypePod {
+			continue
+		}
+		namespaceName := metricSet.Labels[metrics.LabelNamespaceName.Key]
+
+		// the metrics of a volume share their labels, resolve each claim once
+		claims := make(map[string]map[string]string)
+		for i := range metricSet.LabeledMetrics {
+			metric := &metricSet.LabeledMetrics[i]
+			claimName, found := metric.Labels[metrics.LabelPVCName.Key]
+			if !found {
+				continue
+			}
+			claimLabels, found := claims[claimName]
+			if !found {
+				claimLabels = ve.claimLabels(namespaceName, claimName)
+				claims[claimName] = claimLabels
+			}
+			if len(claimLabels) == 0 {
+				continue
+			}
+			labels := make(map[string]string, len(metric.Labels)+len(claimLabels))
+			for k, v := range metric.Labels {
+				labels[k] = v
+			}
+			for k, v := range claimLabels {
+				labels[k] = v
+			}
+			metric.Labels = labels
+		}
+	}
+	return batch, nil
+}
+
+// claimLabels returns the persistent volume and storage class labels of a claim.
+func (ve *VolumeEnricher) claimLabels(namespaceName, claimName string) map[string]string {
+	result := make(map[string]string)
+	pvc, err := ve.pvcLister.PersistentVolumeClaims(namespaceName).Get(claimName)
+	if err != nil {
+		log.Debugf("Failed to get pvc %s/%s from cache: %v", namespaceName, claimName, err)
+		return result
+	}
+	if pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName != "" {
+		result[metrics.LabelStorageClass.Key] = *pvc.Spec.StorageClassName
+	}
+	if pvc.Spec.VolumeName == "" {
+		return result
+	}
+	result[metrics.LabelPVName.Key] = pvc.Spec.VolumeName
+
+	pv, err := ve.pvLister.Get(pvc.Spec.VolumeName)
+	if err != nil {
+		log.Debugf("Failed to get pv %s from cache: %v", pvc.Spec.VolumeName, err)
+		return result
+	}
+	// the class of the bound volume takes precedence, claims without a class may be bound to any volume
+	if pv.Spec.StorageClassName != "" {
+		result[metrics.LabelStorageClass.Key] = pv.Spec.StorageClassName
+	}
+	return result
+}
+
+func NewVolumeEnricher(kubeClient *kube_client.Clientset) (*VolumeEnricher, error) {
+	pvcLister, err := util.GetPersistentVolumeClaimLister(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	pvLister, err := util.GetPersistentVolumeLister(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	return &VolumeEnricher{
+		pvcLister: pvcLister,
+		pvLister:  pvLister,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+
+	kube_api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func volumeMetric(name, volume, claim string) metrics.LabeledMetric {
+	labels := map[string]string{
+		metrics.LabelResourceID.Key: "Volume:" + volume,
+		metrics.LabelVolumeName.Key: volume,
+	}
+	if claim != "" {
+		labels[metrics.LabelPVCName.Key] = claim
+	}
+	return metrics.LabeledMetric{
+		Name:   name,
+		Labels: labels,
+		MetricValue: metrics.MetricValue{
+			ValueType:  metrics.ValueInt64,
+			MetricType: metrics.MetricGauge,
+			IntValue:   100,
+		},
+	}
+}
+
+func TestVolumeEnricher(t *testing.T) {
+	fast, slow := "fast", "slow"
+	pvcStore := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	require.NoError(t, pvcStore.Add(&kube_api.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "data"},
+		Spec:       kube_api.PersistentVolumeClaimSpec{VolumeName: "pv-1", StorageClassName: &slow},
+	}))
+	require.NoError(t, pvcStore.Add(&kube_api.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pending"},
+		Spec:       kube_api.PersistentVolumeClaimSpec{StorageClassName: &fast},
+	}))
+	pvStore := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, pvStore.Add(&kube_api.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+		Spec:       kube_api.PersistentVolumeSpec{StorageClassName: fast},
+	}))
+
+	enricher := &VolumeEnricher{
+		pvcLister: v1listers.NewPersistentVolumeClaimLister(pvcStore),
+		pvLister:  v1listers.NewPersistentVolumeLister(pvStore),
+	}
+
+	pod := &metrics.MetricSet{
+		Labels: map[string]string{
+			metrics.LabelMetricSetType.Key: metrics.MetricSetTypePod,
+			metrics.LabelNamespaceName.Key: "ns1",
+		},
+		MetricValues: map[string]metrics.MetricValue{},
+		LabeledMetrics: []metrics.LabeledMetric{
+			volumeMetric(metrics.MetricFilesystemUsage.Name, "data", "data"),
+			volumeMetric(metrics.MetricFilesystemLimit.Name, "data", "data"),
+			volumeMetric(metrics.MetricFilesystemUsage.Name, "pending", "pending"),
+			volumeMetric(metrics.MetricFilesystemUsage.Name, "unknown", "unknown"),
+			volumeMetric(metrics.MetricFilesystemUsage.Name, "config", ""),
+		},
+	}
+	batch := &metrics.DataBatch{
+		Timestamp:  time.Now(),
+		MetricSets: map[string]*metrics.MetricSet{metrics.PodKey("ns1", "pod1"): pod},
+	}
+	_, err := enricher.Process(batch)
+	require.NoError(t, err)
+
+	for _, metric := range pod.LabeledMetrics[:2] {
+		assert.Equal(t, "pv-1", metric.Labels[metrics.LabelPVName.Key])
+		// the class of the bound volume takes precedence
+		assert.Equal(t, fast, metric.Labels[metrics.LabelStorageClass.Key])
+		assert.Equal(t, "data", metric.Labels[metrics.LabelPVCName.Key])
+	}
+
+	pending := pod.LabeledMetrics[2].Labels
+	assert.Equal(t, fast, pending[metrics.LabelStorageClass.Key])
+	_, found := pending[metrics.LabelPVName.Key]
+	assert.False(t, found)
+
+	for _, metric := range pod.LabeledMetrics[3:] {
+		_, found := metric.Labels[metrics.LabelStorageClass.Key]
+		assert.False(t, found)
+	}
+}
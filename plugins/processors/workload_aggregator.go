@@ -0,0 +1,137 @@
+package processors
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
+
+	kube_api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube_client "k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	v1listers "k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	kindReplicaSet = "ReplicaSet"
+	kindDeployment = "Deployment"
+	kindJob        = "Job"
+	kindCronJob    = "CronJob"
+)
+
+// WorkloadAggregator rolls up the pod metrics to the workload owning the pods. Pods owned by a
+// ReplicaSet or Job are resolved to their Deployment or CronJob when there is one.
+type WorkloadAggregator struct {
+	MetricsToAggregate []string
+	Aggregations       []Aggregation
+	podLister          v1listers.PodLister
+	replicaSetLister   appslisters.ReplicaSetLister
+	jobLister          batchlisters.JobLister
+}
+
+func (aggregator *WorkloadAggregator) Name() string {
+	return "workload_aggregator"
+}
+
+func (aggregator *WorkloadAggregator) Process(batch *metrics.DataBatch) (*metrics.DataBatch, error) {
+	workloads := make(map[string]*metrics.MetricSet)
+	values := newAggregationValues(aggregator.Aggregations)
+	for key, metricSet := range batch.MetricSets {
+		if metricSetType, found := metricSet.Labels[metrics.LabelMetricSetType.Key]; !found || metricSetType != metrics.MetricSetTypePod {
+			continue
+		}
+
+		namespaceName := metricSet.Labels[metrics.LabelNamespaceName.Key]
+		podName := metricSet.Labels[metrics.LabelPodName.Key]
+		pod, err := aggregator.podLister.Pods(namespaceName).Get(podName)
+		if err != nil {
+			log.Debugf("Failed to get pod %s from cache: %v", key, err)
+			continue
+		}
+
+		kind, name := aggregator.workload(pod)
+		if kind == "" {
+			continue
+		}
+
+		workloadKey := metrics.WorkloadKey(namespaceName, kind, name)
+		workload, found := workloads[workloadKey]
+		if !found {
+			workload = workloadMetricSet(namespaceName, metricSet.Labels[metrics.LabelPodNamespaceUID.Key], kind, name)
+			workloads[workloadKey] = workload
+		}
+
+		if err := aggregate(metricSet, workload, aggregator.MetricsToAggregate); err != nil {
+			return nil, err
+		}
+		values.collect(metricSet, workload)
+	}
+	values.apply()
+	for key, val := range workloads {
+		batch.MetricSets[key] = val
+	}
+	return batch, nil
+}
+
+// workload returns the kind and name of the workload owning the pod or empty strings if the pod has no controller.
+func (aggregator *WorkloadAggregator) workload(pod *kube_api.Pod) (string, string) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "", ""
+	}
+
+	switch owner.Kind {
+	case kindReplicaSet:
+		rs, err := aggregator.replicaSetLister.ReplicaSets(pod.Namespace).Get(owner.Name)
+		if err != nil {
+			log.Debugf("Failed to get replicaset %s/%s from cache: %v", pod.Namespace, owner.Name, err)
+			break
+		}
+		if rsOwner := metav1.GetControllerOf(rs); rsOwner != nil && rsOwner.Kind == kindDeployment {
+			return rsOwner.Kind, rsOwner.Name
+		}
+	case kindJob:
+		job, err := aggregator.jobLister.Jobs(pod.Namespace).Get(owner.Name)
+		if err != nil {
+			log.Debugf("Failed to get job %s/%s from cache: %v", pod.Namespace, owner.Name, err)
+			break
+		}
+		if jobOwner := metav1.GetControllerOf(job); jobOwner != nil && jobOwner.Kind == kindCronJob {
+			return jobOwner.Kind, jobOwner.Name
+		}
+	}
+	return owner.Kind, owner.Name
+}
+
+func workloadMetricSet(namespaceName, uid, kind, name string) *metrics.MetricSet {
+	return &metrics.MetricSet{
+		MetricValues: make(map[string]metrics.MetricValue),
+		Labels: map[string]string{
+			metrics.LabelMetricSetType.Key:   metrics.MetricSetTypeWorkload,
+			metrics.LabelNamespaceName.Key:   namespaceName,
+			metrics.LabelPodNamespaceUID.Key: uid,
+			metrics.LabelWorkloadKind.Key:    kind,
+			metrics.LabelWorkloadName.Key:    name,
+		},
+	}
+}
+
+func NewWorkloadAggregator(kubeClient *kube_client.Clientset, podLister v1listers.PodLister, metricsToAggregate []string, aggregations []Aggregation) (*WorkloadAggregator, error) {
+	replicaSetLister, err := util.GetReplicaSetLister(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	jobLister, err := util.GetJobLister(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	return &WorkloadAggregator{
+		MetricsToAggregate: metricsToAggregate,
+		Aggregations:       aggregations,
+		podLister:          podLister,
+		replicaSetLister:   replicaSetLister,
+		jobLister:          jobLister,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	kube_api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	v1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+}
+
+func testObjectMeta(name string, owners []metav1.OwnerReference) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Namespace: "ns1", Name: name, OwnerReferences: owners}
+}
+
+func workloadPodMetricSet(name string, cpu, memory int64) *metrics.MetricSet {
+	return &metrics.MetricSet{
+		Labels: map[string]string{
+			metrics.LabelMetricSetType.Key:   metrics.MetricSetTypePod,
+			metrics.LabelNamespaceName.Key:   "ns1",
+			metrics.LabelPodNamespaceUID.Key: "ns1-uid",
+			metrics.LabelPodName.Key:         name,
+		},
+		MetricValues: map[string]metrics.MetricValue{
+			metrics.MetricCpuUsageRate.Name: {ValueType: metrics.ValueInt64, MetricType: metrics.MetricGauge, IntValue: cpu},
+			metrics.MetricMemoryUsage.Name:  {ValueType: metrics.ValueInt64, MetricType: metrics.MetricGauge, IntValue: memory},
+		},
+	}
+}
+
+func testWorkloadAggregator(t *testing.T) *WorkloadAggregator {
+	podStore := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	pods := []*kube_api.Pod{
+		{ObjectMeta: testObjectMeta("web-1", controllerRef("ReplicaSet", "web-5d8f"))},
+		{ObjectMeta: testObjectMeta("web-2", controllerRef("ReplicaSet", "web-5d8f"))},
+		{ObjectMeta: testObjectMeta("bare-rs-1", controllerRef("ReplicaSet", "bare-rs"))},
+		{ObjectMeta: testObjectMeta("db-0", controllerRef("StatefulSet", "db"))},
+		{ObjectMeta: testObjectMeta("report-1-abc", controllerRef("Job", "report-1"))},
+		{ObjectMeta: testObjectMeta("migrate-abc", controllerRef("Job", "migrate"))},
+		{ObjectMeta: testObjectMeta("standalone", nil)},
+	}
+	for _, pod := range pods {
+		require.NoError(t, podStore.Add(pod))
+	}
+
+	rsStore := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	require.NoError(t, rsStore.Add(&appsv1.ReplicaSet{ObjectMeta: testObjectMeta("web-5d8f", controllerRef("Deployment", "web"))}))
+	require.NoError(t, rsStore.Add(&appsv1.ReplicaSet{ObjectMeta: testObjectMeta("bare-rs", nil)}))
+
+	jobStore := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	require.NoError(t, jobStore.Add(&batchv1.Job{ObjectMeta: testObjectMeta("report-1", controllerRef("CronJob", "report"))}))
+	require.NoError(t, jobStore.Add(&batchv1.Job{ObjectMeta: testObjectMeta("migrate", nil)}))
+
+	return &WorkloadAggregator{
+		MetricsToAggregate: []string{metrics.MetricCpuUsageRate.Name, metrics.MetricMemoryUsage.Name},
+		podLister:          v1listers.NewPodLister(podStore),
+		replicaSetLister:   appslisters.NewReplicaSetLister(rsStore),
+		jobLister:          batchlisters.NewJobLister(jobStore),
+	}
+}
+
+func TestWorkloadAggregator(t *testing.T) {
+	batch := metrics.DataBatch{
+		Timestamp: time.Now(),
+		MetricSets: map[string]*metrics.MetricSet{
+			metrics.PodKey("ns1", "web-1"):        workloadPodMetricSet("web-1", 100, 1000),
+			metrics.PodKey("ns1", "web-2"):        workloadPodMetricSet("web-2", 200, 2000),
+			metrics.PodKey("ns1", "bare-rs-1"):    workloadPodMetricSet("bare-rs-1", 1, 10),
+			metrics.PodKey("ns1", "db-0"):         workloadPodMetricSet("db-0", 300, 3000),
+			metrics.PodKey("ns1", "report-1-abc"): workloadPodMetricSet("report-1-abc", 400, 4000),
+			metrics.PodKey("ns1", "migrate-abc"):  workloadPodMetricSet("migrate-abc", 500, 5000),
+			metrics.PodKey("ns1", "standalone"):   workloadPodMetricSet("standalone", 600, 6000),
+			metrics.PodKey("ns1", "unknown"):      workloadPodMetricSet("unknown", 700, 7000),
+		},
+	}
+
+	processed, err := testWorkloadAggregator(t).Process(&batch)
+	require.NoError(t, err)
+
+	expected := []struct {
+		kind   string
+		name   string
+		cpu    int64
+		memory int64
+	}{
+		{"Deployment", "web", 300, 3000},
+		{"ReplicaSet", "bare-rs", 1, 10},
+		{"StatefulSet", "db", 300, 3000},
+		{"CronJob", "report", 400, 4000},
+		{"Job", "migrate", 500, 5000},
+	}
+	workloads := 0
+	for _, ms := range processed.MetricSets {
+		if ms.Labels[metrics.LabelMetricSetType.Key] == metrics.MetricSetTypeWorkload {
+			workloads++
+		}
+	}
+	assert.Equal(t, len(expected), workloads)
+
+	for _, e := range expected {
+		workload, found := processed.MetricSets[metrics.WorkloadKey("ns1", e.kind, e.name)]
+		require.True(t, found, "missing workload %s/%s", e.kind, e.name)
+		assert.Equal(t, metrics.MetricSetTypeWorkload, workload.Labels[metrics.LabelMetricSetType.Key])
+		assert.Equal(t, "ns1", workload.Labels[metrics.LabelNamespaceName.Key])
+		assert.Equal(t, "ns1-uid", workload.Labels[metrics.LabelPodNamespaceUID.Key])
+		assert.Equal(t, e.kind, workload.Labels[metrics.LabelWorkloadKind.Key])
+		assert.Equal(t, e.name, workload.Labels[metrics.LabelWorkloadName.Key])
+		assert.Equal(t, e.cpu, workload.MetricValues[metrics.MetricCpuUsageRate.Name].IntValue)
+		assert.Equal(t, e.memory, workload.MetricValues[metrics.MetricMemoryUsage.Name].IntValue)
+	}
+}
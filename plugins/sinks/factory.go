@@ -15,21 +15,51 @@
 package sinks
 
 import (
+	"fmt"
+	"sync"
+
 	log "github.com/sirupsen/logrus"
 
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sinks/prometheus"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sinks/wavefront"
 )
 
+var (
+	factoriesMtx sync.RWMutex
+	factories    = make(map[string]metrics.SinkFactory)
+)
+
+func init() {
+	Register(wavefront.NewFactory())
+	Register(prometheus.NewFactory())
+}
+
+// Register adds a factory for the sink type returned by its Name() and registers the configuration type
+// of the sink, so sinks of that type can be parsed and built.
+func Register(f metrics.SinkFactory) {
+	factoriesMtx.Lock()
+	defer factoriesMtx.Unlock()
+	factories[f.Name()] = f
+	configuration.RegisterSinkType(f.Name(), f.NewConfig)
+}
+
+// SinkFactory builds sinks using the factory registered for the type of each sink configuration.
 type SinkFactory struct {
 }
 
-func (factory *SinkFactory) Build(cfg configuration.WavefrontSinkConfig) (metrics.DataSink, error) {
-	return wavefront.NewWavefrontSink(cfg)
+func (factory *SinkFactory) Build(cfg configuration.SinkConfig) (metrics.DataSink, error) {
+	factoriesMtx.RLock()
+	f, found := factories[cfg.Type]
+	factoriesMtx.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("no factory registered for sink type: %s", cfg.Type)
+	}
+	return f.Build(cfg.Config)
 }
 
-func (factory *SinkFactory) BuildAll(cfgs []*configuration.WavefrontSinkConfig) []metrics.DataSink {
+func (factory *SinkFactory) BuildAll(cfgs []*configuration.SinkConfig) []metrics.DataSink {
 	result := make([]metrics.DataSink, 0, len(cfgs))
 
 	for _, cfg := range cfgs {
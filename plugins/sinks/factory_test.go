@@ -0,0 +1,34 @@
+package sinks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+)
+
+func TestRegisteredSinkTypes(t *testing.T) {
+	cfg, err := configuration.FromYAML([]byte("sinks:\n- proxyAddress: 'localhost:2878'\n- type: prometheus\n  url: 'http://localhost'\n"))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(cfg.Sinks))
+	assert.IsType(t, &configuration.WavefrontSinkConfig{}, cfg.Sinks[0].Config)
+	assert.IsType(t, &configuration.PrometheusSinkConfig{}, cfg.Sinks[1].Config)
+}
+
+func TestBuildInvalidConfig(t *testing.T) {
+	factory := NewSinkFactory()
+
+	_, err := factory.Build(configuration.SinkConfig{Type: "unknown"})
+	assert.Error(t, err)
+
+	_, err = factory.Build(configuration.SinkConfig{
+		Type:   configuration.PrometheusSinkType,
+		Config: &configuration.WavefrontSinkConfig{},
+	})
+	assert.Error(t, err)
+
+	_, err = factory.Build(configuration.SinkConfig{Type: configuration.WavefrontSinkType})
+	assert.Error(t, err)
+}
@@ -21,6 +21,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/events"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
 
 	gm "github.com/rcrowley/go-metrics"
@@ -29,25 +30,47 @@ import (
 
 const (
 	DefaultSinkStopTimeout = 60 * time.Second
+
+	// maximum number of timed out batches waiting to be buffered by a sink
+	maxPendingBufferedBatches = 16
+
+	// maximum number of events waiting to be exported by a sink
+	maxPendingEvents = 1000
 )
 
 var (
-	sinkTimeouts gm.Counter
+	sinkTimeouts      gm.Counter
+	sinkBufferedOps   gm.Counter
+	sinkDroppedEvents gm.Counter
 )
 
 func init() {
 	sinkTimeouts = gm.GetOrRegisterCounter("sink.manager.timeouts", gm.DefaultRegistry)
+	sinkBufferedOps = gm.GetOrRegisterCounter("sink.manager.buffered", gm.DefaultRegistry)
+	sinkDroppedEvents = gm.GetOrRegisterCounter("sink.manager.events.dropped", gm.DefaultRegistry)
+}
+
+// BufferingDataSink is implemented by sinks that can durably buffer data they could not export in time.
+type BufferingDataSink interface {
+	metrics.DataSink
+
+	// BufferData stores the given batch for a later retry. Returns false if the batch was not buffered.
+	BufferData(*metrics.DataBatch) bool
 }
 
 type sinkHolder struct {
 	sink             metrics.DataSink
 	dataBatchChannel chan *metrics.DataBatch
+	bufferChannel    chan *metrics.DataBatch
+	eventChannel     chan *events.Event
 	stopChannel      chan bool
 }
 
 // Sink Manager - a special sink that distributes data to other sinks. It pushes data
 // only to these sinks that completed their previous exports. Data that could not be
-// pushed in the defined time is dropped and not retried.
+// pushed in the defined time is handed to the sink's buffer once its in-flight export
+// completes if it implements BufferingDataSink, and is otherwise dropped and not retried.
+// Events are queued for the sinks that implement EventSink and dropped once the queue is full.
 type sinkManager struct {
 	sinkHolders       []sinkHolder
 	exportDataTimeout time.Duration
@@ -62,12 +85,23 @@ func NewDataSinkManager(sinks []metrics.DataSink, exportDataTimeout, stopTimeout
 			dataBatchChannel: make(chan *metrics.DataBatch),
 			stopChannel:      make(chan bool),
 		}
+		if _, ok := sink.(BufferingDataSink); ok {
+			sh.bufferChannel = make(chan *metrics.DataBatch, maxPendingBufferedBatches)
+		}
+		if _, ok := sink.(events.EventSink); ok {
+			sh.eventChannel = make(chan *events.Event, maxPendingEvents)
+		}
 		sinkHolders = append(sinkHolders, sh)
 		go func(sh sinkHolder) {
 			for {
 				select {
 				case data := <-sh.dataBatchChannel:
 					export(sh.sink, data)
+				case data := <-sh.bufferChannel:
+					// buffered by the goroutine exporting to the sink so it never overlaps with an export
+					buffer(sh.sink.(BufferingDataSink), data)
+				case event := <-sh.eventChannel:
+					sh.sink.(events.EventSink).ExportEvent(event)
 				case isStop := <-sh.stopChannel:
 					log.WithField("name", sh.sink.Name()).Info("Sink stop received")
 					if isStop {
@@ -99,6 +133,14 @@ func (this *sinkManager) ExportData(data *metrics.DataBatch) {
 				// everything ok
 			case <-time.After(this.exportDataTimeout):
 				sinkTimeouts.Inc(1)
+				if sh.bufferChannel != nil {
+					select {
+					case sh.bufferChannel <- data:
+						log.WithField("name", sh.sink.Name()).Info("Data push timed out, data queued for buffering")
+						return
+					default:
+					}
+				}
 				log.WithField("name", sh.sink.Name()).Info("Data push failed")
 			}
 		}(sh, &wg)
@@ -107,6 +149,22 @@ func (this *sinkManager) ExportData(data *metrics.DataBatch) {
 	wg.Wait()
 }
 
+// ExportEvent queues the event for the sinks that support exporting events. Never blocks.
+func (this *sinkManager) ExportEvent(event *events.Event) {
+	for _, sh := range this.sinkHolders {
+		if sh.eventChannel == nil {
+			continue
+		}
+		select {
+		case sh.eventChannel <- event:
+			// everything ok
+		default:
+			sinkDroppedEvents.Inc(1)
+			log.WithField("name", sh.sink.Name()).Debug("Event queue full, event dropped")
+		}
+	}
+}
+
 func (this *sinkManager) Name() string {
 	return "Manager"
 }
@@ -132,3 +190,11 @@ func (this *sinkManager) Stop() {
 func export(s metrics.DataSink, data *metrics.DataBatch) {
 	s.ExportData(data)
 }
+
+func buffer(s BufferingDataSink, data *metrics.DataBatch) {
+	if s.BufferData(data) {
+		sinkBufferedOps.Inc(1)
+		return
+	}
+	log.WithField("name", s.Name()).Info("Data push failed")
+}
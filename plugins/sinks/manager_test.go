@@ -15,11 +15,13 @@
 package sinks
 
 import (
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/events"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
 )
@@ -108,6 +110,92 @@ func TestNoExportInTime(t *testing.T) {
 	assert.Equal(t, 1, sink2.GetExportCount())
 }
 
+type bufferingSink struct {
+	*util.DummySink
+	mtx       sync.Mutex
+	exporting bool
+	overlaps  int
+	buffered  int
+}
+
+func (s *bufferingSink) ExportData(data *metrics.DataBatch) {
+	s.mtx.Lock()
+	s.exporting = true
+	s.mtx.Unlock()
+	s.DummySink.ExportData(data)
+	s.mtx.Lock()
+	s.exporting = false
+	s.mtx.Unlock()
+}
+
+func (s *bufferingSink) BufferData(*metrics.DataBatch) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.exporting {
+		s.overlaps++
+	}
+	s.buffered++
+	return true
+}
+
+func TestBufferAfterExport(t *testing.T) {
+	timeout := 100 * time.Millisecond
+
+	sink := &bufferingSink{DummySink: util.NewDummySink("s1", time.Second)}
+	manager, _ := NewDataSinkManager([]metrics.DataSink{sink}, timeout, timeout)
+
+	batch := metrics.DataBatch{
+		Timestamp: time.Now(),
+	}
+
+	manager.ExportData(&batch)
+	manager.ExportData(&batch)
+	manager.ExportData(&batch)
+
+	time.Sleep(1500 * time.Millisecond)
+	sink.mtx.Lock()
+	defer sink.mtx.Unlock()
+	assert.Equal(t, 1, sink.GetExportCount())
+	assert.Equal(t, 2, sink.buffered)
+	assert.Equal(t, 0, sink.overlaps)
+}
+
+type eventSink struct {
+	bufferingSink
+	events int
+}
+
+func (s *eventSink) ExportEvent(*events.Event) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.exporting {
+		s.overlaps++
+	}
+	s.events++
+}
+
+func TestExportEventQueued(t *testing.T) {
+	timeout := 100 * time.Millisecond
+
+	sink := &eventSink{bufferingSink: bufferingSink{DummySink: util.NewDummySink("s1", time.Second)}}
+	manager, _ := NewDataSinkManager([]metrics.DataSink{sink}, timeout, timeout)
+
+	manager.ExportData(&metrics.DataBatch{Timestamp: time.Now()})
+
+	// the event is queued while the sink is exporting data
+	now := time.Now()
+	manager.(*sinkManager).ExportEvent(&events.Event{Name: "test"})
+	if elapsed := time.Now().Sub(now); elapsed > timeout {
+		t.Fatalf("ExportEvent took too long: %s", elapsed)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	sink.mtx.Lock()
+	defer sink.mtx.Unlock()
+	assert.Equal(t, 1, sink.events)
+	assert.Equal(t, 0, sink.overlaps)
+}
+
 func TestStop(t *testing.T) {
 	timeout := 3 * time.Second
 
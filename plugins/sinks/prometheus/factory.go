@@ -0,0 +1,32 @@
+package prometheus
+
+import (
+	"fmt"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+type factory struct{}
+
+// Returns a new prometheus sink factory
+func NewFactory() metrics.SinkFactory {
+	return factory{}
+}
+
+func (f factory) Build(cfg interface{}) (metrics.DataSink, error) {
+	c, ok := cfg.(*configuration.PrometheusSinkConfig)
+	if !ok || c == nil {
+		return nil, fmt.Errorf("invalid prometheus sink configuration: %T", cfg)
+	}
+	return NewPrometheusSink(*c)
+}
+
+// NewConfig returns a new configuration of the prometheus sink
+func (f factory) NewConfig() interface{} {
+	return &configuration.PrometheusSinkConfig{}
+}
+
+func (f factory) Name() string {
+	return configuration.PrometheusSinkType
+}
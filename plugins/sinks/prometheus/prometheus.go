@@ -0,0 +1,270 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/httputil"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/relabel"
+
+	gm "github.com/rcrowley/go-metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultBatchSize = 500
+	defaultTimeout   = 30 * time.Second
+	metricNameLabel  = "__name__"
+)
+
+var (
+	sentPoints     gm.Counter
+	errPoints      gm.Counter
+	filteredPoints gm.Counter
+	requestErrors  gm.Counter
+	droppedDists   gm.Counter
+	droppedLabels  gm.Counter
+)
+
+func init() {
+	sentPoints = gm.GetOrRegisterCounter("prometheus.sink.points.sent.count", gm.DefaultRegistry)
+	errPoints = gm.GetOrRegisterCounter("prometheus.sink.points.errors.count", gm.DefaultRegistry)
+	filteredPoints = gm.GetOrRegisterCounter("prometheus.sink.points.filtered.count", gm.DefaultRegistry)
+	requestErrors = gm.GetOrRegisterCounter("prometheus.sink.requests.errors.count", gm.DefaultRegistry)
+	droppedDists = gm.GetOrRegisterCounter("prometheus.sink.distributions.dropped.count", gm.DefaultRegistry)
+	droppedLabels = gm.GetOrRegisterCounter("prometheus.sink.labels.dropped.count", gm.DefaultRegistry)
+}
+
+type prometheusSink struct {
+	url         string
+	client      *http.Client
+	clusterName string
+	prefix      string
+	globalTags  map[string]string
+	filters     filter.Filter
+	relabeler   *relabel.Relabeler
+	batchSize   int
+}
+
+func (sink *prometheusSink) Name() string {
+	return "prometheus_sink"
+}
+
+func (sink *prometheusSink) Stop() {
+	// nothing to do
+}
+
+func (sink *prometheusSink) ExportData(batch *metrics.DataBatch) {
+	log.Debugf("received metric points: %d", len(batch.MetricPoints))
+	if len(batch.Distributions) > 0 {
+		// remote write has no representation for Wavefront distributions
+		droppedDists.Inc(int64(len(batch.Distributions)))
+		log.Debugf("dropping distributions: %d", len(batch.Distributions))
+	}
+
+	series := sink.buildTimeSeries(batch.MetricPoints)
+	for start := 0; start < len(series); start += sink.batchSize {
+		end := start + sink.batchSize
+		if end > len(series) {
+			end = len(series)
+		}
+		if err := sink.write(series[start:end]); err != nil {
+			requestErrors.Inc(1)
+			errPoints.Inc(int64(end - start))
+			log.WithFields(log.Fields{
+				"url":   sink.url,
+				"error": err,
+			}).Warning("error sending points")
+			continue
+		}
+		sentPoints.Inc(int64(end - start))
+	}
+}
+
+// buildTimeSeries converts the given points into remote write time series, applying the configured relabeling and filters.
+func (sink *prometheusSink) buildTimeSeries(points []*metrics.MetricPoint) []*TimeSeries {
+	series := make([]*TimeSeries, 0, len(points))
+	for _, point := range points {
+		name := point.Metric
+		if sink.prefix != "" {
+			name = sink.prefix + name
+		}
+		tags := pointTags(point)
+		if sink.relabeler != nil {
+			var ok bool
+			if name, tags, ok = sink.relabeler.Relabel(name, tags); !ok {
+				filteredPoints.Inc(1)
+				continue
+			}
+		}
+		if sink.filters != nil && !sink.filters.Match(name, tags) {
+			filteredPoints.Inc(1)
+			log.WithField("name", name).Trace("Dropping metric")
+			continue
+		}
+		for k, v := range sink.globalTags {
+			if _, exists := tags[k]; !exists {
+				tags[k] = v
+			}
+		}
+		tags["cluster"] = sink.clusterName
+		if point.Source != "" {
+			tags["source"] = point.Source
+		}
+		series = append(series, &TimeSeries{
+			Labels: toLabels(name, tags),
+			Samples: []*Sample{{
+				Value:     point.Value,
+				Timestamp: toMillis(point.Timestamp),
+			}},
+		})
+	}
+	return series
+}
+
+func (sink *prometheusSink) write(series []*TimeSeries) error {
+	data, err := proto.Marshal(&WriteRequest{Timeseries: series})
+	if err != nil {
+		return fmt.Errorf("error encoding write request: %v", err)
+	}
+	req, err := http.NewRequest("POST", sink.url, bytes.NewReader(snappy.Encode(nil, data)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "wavefront-collector")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := sink.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned HTTP status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func pointTags(point *metrics.MetricPoint) map[string]string {
+	tags := metrics.DecodeTags(point.Tags, point.StrTags)
+	for k, v := range tags {
+		if len(v) == 0 {
+			delete(tags, k)
+		}
+	}
+	return tags
+}
+
+// toLabels returns the sorted labels for a series. Names are sanitized to match the Prometheus data model.
+// Tags sanitized to the name of another label are dropped, tags whose names are already valid take precedence.
+func toLabels(name string, tags map[string]string) []*Label {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	labels := make([]*Label, 0, len(tags)+1)
+	labels = append(labels, &Label{Name: metricNameLabel, Value: sanitize(name, true)})
+	seen := map[string]bool{metricNameLabel: true}
+	for _, valid := range []bool{true, false} {
+		for _, k := range keys {
+			labelName := sanitize(k, false)
+			if (labelName == k) != valid {
+				continue
+			}
+			if labelName == "" || seen[labelName] {
+				droppedLabels.Inc(1)
+				log.WithFields(log.Fields{"metric": name, "tag": k}).Trace("Dropping duplicate label")
+				continue
+			}
+			seen[labelName] = true
+			labels = append(labels, &Label{Name: labelName, Value: tags[k]})
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+	return labels
+}
+
+// sanitize replaces characters not allowed in Prometheus metric or label names with underscores.
+func sanitize(name string, allowColons bool) string {
+	var b strings.Builder
+	b.Grow(len(name))
+	for i, c := range name {
+		valid := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(c >= '0' && c <= '9' && i > 0) || (c == ':' && allowColons)
+		if valid {
+			b.WriteRune(c)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// toMillis converts a timestamp in seconds, milliseconds, microseconds or nanoseconds into milliseconds.
+func toMillis(ts int64) int64 {
+	switch {
+	case ts == 0:
+		return time.Now().UnixNano() / int64(time.Millisecond)
+	case ts < 1e11:
+		return ts * 1000
+	case ts < 1e14:
+		return ts
+	case ts < 1e17:
+		return ts / 1000
+	default:
+		return ts / int64(time.Millisecond)
+	}
+}
+
+func NewPrometheusSink(cfg configuration.PrometheusSinkConfig) (metrics.DataSink, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("url property required for Prometheus sink")
+	}
+	client, err := httputil.NewClient(cfg.HTTPClientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating http client: %v", err)
+	}
+	client.Timeout = configuration.GetDurationValue(cfg.Timeout, defaultTimeout)
+
+	relabeler, err := relabel.FromConfig(cfg.Relabel)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return &prometheusSink{
+		url:         cfg.URL,
+		client:      client,
+		clusterName: configuration.GetStringValue(cfg.ClusterName, "k8s-cluster"),
+		prefix:      cfg.Prefix,
+		globalTags:  cfg.Tags,
+		filters:     filter.FromConfig(cfg.Filters),
+		relabeler:   relabeler,
+		batchSize:   batchSize,
+	}, nil
+}
@@ -0,0 +1,210 @@
+package prometheus
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+type remoteWriteServer struct {
+	mtx      sync.Mutex
+	requests []*WriteRequest
+	status   int
+}
+
+func (s *remoteWriteServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	compressed, _ := ioutil.ReadAll(r.Body)
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	req := &WriteRequest{}
+	if err := proto.Unmarshal(data, req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.requests = append(s.requests, req)
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+	}
+}
+
+func labelMap(series *TimeSeries) map[string]string {
+	labels := make(map[string]string)
+	for _, l := range series.Labels {
+		labels[l.Name] = l.Value
+	}
+	return labels
+}
+
+func newTestSink(t *testing.T, url string, cfg configuration.PrometheusSinkConfig) *prometheusSink {
+	cfg.URL = url
+	cfg.ClusterName = "test-cluster"
+	sink, err := NewPrometheusSink(cfg)
+	require.NoError(t, err)
+	return sink.(*prometheusSink)
+}
+
+func TestExportData(t *testing.T) {
+	rw := &remoteWriteServer{}
+	server := httptest.NewServer(rw)
+	defer server.Close()
+
+	sink := newTestSink(t, server.URL, configuration.PrometheusSinkConfig{
+		Transforms: configuration.Transforms{
+			Tags: map[string]string{"env": "dev"},
+		},
+	})
+	sink.ExportData(&metrics.DataBatch{
+		MetricPoints: []*metrics.MetricPoint{
+			{
+				Metric:    "kubernetes.pod.cpu.usage_rate",
+				Value:     1.5,
+				Timestamp: 1560000000,
+				Source:    "node-1",
+				Tags:      map[string]string{"pod-name": "nginx", "empty": ""},
+			},
+			{
+				Metric:    "http.requests.total",
+				Value:     10,
+				Timestamp: 1560000000000,
+				StrTags:   "code=200 method=GET",
+			},
+		},
+	})
+
+	require.Equal(t, 1, len(rw.requests))
+	series := rw.requests[0].Timeseries
+	require.Equal(t, 2, len(series))
+
+	labels := labelMap(series[0])
+	assert.Equal(t, "kubernetes_pod_cpu_usage_rate", labels["__name__"])
+	assert.Equal(t, "nginx", labels["pod_name"])
+	assert.Equal(t, "node-1", labels["source"])
+	assert.Equal(t, "test-cluster", labels["cluster"])
+	assert.Equal(t, "dev", labels["env"])
+	_, found := labels["empty"]
+	assert.False(t, found)
+	assert.Equal(t, "__name__", series[0].Labels[0].Name)
+	assert.Equal(t, 1.5, series[0].Samples[0].Value)
+	assert.Equal(t, int64(1560000000000), series[0].Samples[0].Timestamp)
+
+	labels = labelMap(series[1])
+	assert.Equal(t, "http_requests_total", labels["__name__"])
+	assert.Equal(t, "200", labels["code"])
+	assert.Equal(t, "GET", labels["method"])
+	assert.Equal(t, int64(1560000000000), series[1].Samples[0].Timestamp)
+}
+
+func TestBatching(t *testing.T) {
+	rw := &remoteWriteServer{}
+	server := httptest.NewServer(rw)
+	defer server.Close()
+
+	sink := newTestSink(t, server.URL, configuration.PrometheusSinkConfig{BatchSize: 2})
+	points := make([]*metrics.MetricPoint, 5)
+	for i := range points {
+		points[i] = &metrics.MetricPoint{Metric: "test.metric", Value: float64(i), Timestamp: 1560000000}
+	}
+	sink.ExportData(&metrics.DataBatch{MetricPoints: points})
+
+	require.Equal(t, 3, len(rw.requests))
+	assert.Equal(t, 2, len(rw.requests[0].Timeseries))
+	assert.Equal(t, 1, len(rw.requests[2].Timeseries))
+}
+
+func TestFilters(t *testing.T) {
+	rw := &remoteWriteServer{}
+	server := httptest.NewServer(rw)
+	defer server.Close()
+
+	sink := newTestSink(t, server.URL, configuration.PrometheusSinkConfig{
+		Transforms: configuration.Transforms{
+			Prefix: "k8s.",
+			Filters: filter.Config{
+				MetricWhitelist: []string{"k8s.kubernetes.*"},
+			},
+		},
+	})
+	sink.ExportData(&metrics.DataBatch{
+		MetricPoints: []*metrics.MetricPoint{
+			{Metric: "kubernetes.node.cpu.usage_rate", Value: 1, Timestamp: 1560000000},
+			{Metric: "http.requests.total", Value: 1, Timestamp: 1560000000},
+		},
+	})
+
+	require.Equal(t, 1, len(rw.requests))
+	require.Equal(t, 1, len(rw.requests[0].Timeseries))
+	assert.Equal(t, "k8s_kubernetes_node_cpu_usage_rate", labelMap(rw.requests[0].Timeseries[0])["__name__"])
+}
+
+func TestServerError(t *testing.T) {
+	rw := &remoteWriteServer{status: http.StatusInternalServerError}
+	server := httptest.NewServer(rw)
+	defer server.Close()
+
+	sink := newTestSink(t, server.URL, configuration.PrometheusSinkConfig{})
+	points := []*metrics.MetricPoint{{Metric: "test.metric", Value: 1, Timestamp: 1560000000}}
+	err := sink.write(sink.buildTimeSeries(points))
+	assert.Error(t, err)
+}
+
+func TestSanitize(t *testing.T) {
+	assert.Equal(t, "kubernetes_pod_cpu_usage_rate", sanitize("kubernetes.pod.cpu.usage_rate", true))
+	assert.Equal(t, "_abc:def", sanitize("9abc:def", true))
+	assert.Equal(t, "label_app_kubernetes_io_name", sanitize("label.app.kubernetes.io/name", false))
+	assert.Equal(t, "a_b", sanitize("a:b", false))
+}
+
+func TestToLabelsDuplicates(t *testing.T) {
+	labels := toLabels("test.metric", map[string]string{
+		"__name__": "other",
+		"pod.name": "sanitized",
+		"pod_name": "valid",
+		"a/b":      "slash",
+		"a.b":      "dot",
+	})
+	require.Equal(t, 3, len(labels))
+	assert.Equal(t, &Label{Name: "__name__", Value: "test_metric"}, labels[0])
+	assert.Equal(t, &Label{Name: "a_b", Value: "dot"}, labels[1])
+	assert.Equal(t, &Label{Name: "pod_name", Value: "valid"}, labels[2])
+}
+
+func TestDistributionsDropped(t *testing.T) {
+	rw := &remoteWriteServer{}
+	server := httptest.NewServer(rw)
+	defer server.Close()
+
+	sink := newTestSink(t, server.URL, configuration.PrometheusSinkConfig{})
+	before := droppedDists.Count()
+	sink.ExportData(&metrics.DataBatch{
+		Distributions: []*metrics.Distribution{{Metric: "test.distribution"}},
+	})
+	assert.Equal(t, int64(1), droppedDists.Count()-before)
+	assert.Equal(t, 0, len(rw.requests))
+}
+
+func TestToMillis(t *testing.T) {
+	assert.Equal(t, int64(1560000000000), toMillis(1560000000))
+	assert.Equal(t, int64(1560000000123), toMillis(1560000000123))
+	assert.Equal(t, int64(1560000000123), toMillis(1560000000123456))
+	assert.Equal(t, int64(1560000000123), toMillis(1560000000123456789))
+}
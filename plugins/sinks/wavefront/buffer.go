@@ -0,0 +1,413 @@
+package wavefront
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gm "github.com/rcrowley/go-metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	segmentSuffix  = ".wal"
+	minSegmentSize = 64 * 1024
+	maxSegmentSize = 8 * 1024 * 1024
+)
+
+var (
+	bufferedPoints  gm.Counter
+	replayedPoints  gm.Counter
+	droppedPoints   gm.Counter
+	bufferErrors    gm.Counter
+	bufferDepth     gm.Gauge
+	bufferSize      gm.Gauge
+	bufferOldestAge gm.Gauge
+)
+
+func init() {
+	bufferedPoints = gm.GetOrRegisterCounter("wavefront.buffer.points.buffered.count", gm.DefaultRegistry)
+	replayedPoints = gm.GetOrRegisterCounter("wavefront.buffer.points.replayed.count", gm.DefaultRegistry)
+	droppedPoints = gm.GetOrRegisterCounter("wavefront.buffer.points.dropped.count", gm.DefaultRegistry)
+	bufferErrors = gm.GetOrRegisterCounter("wavefront.buffer.errors.count", gm.DefaultRegistry)
+	bufferDepth = gm.GetOrRegisterGauge("wavefront.buffer.depth", gm.DefaultRegistry)
+	bufferSize = gm.GetOrRegisterGauge("wavefront.buffer.size.bytes", gm.DefaultRegistry)
+	bufferOldestAge = gm.GetOrRegisterGauge("wavefront.buffer.oldest.age.seconds", gm.DefaultRegistry)
+}
+
+// bufferedPoint is a single point persisted in the disk buffer.
+type bufferedPoint struct {
+	Metric    string            `json:"metric"`
+	Value     float64           `json:"value"`
+	Timestamp int64             `json:"timestamp"`
+	Source    string            `json:"source"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	// unix time in seconds at which the point was buffered
+	Enqueued int64 `json:"enqueued"`
+}
+
+// segment is an append-only file of newline delimited points.
+type segment struct {
+	seq       uint64
+	path      string
+	size      int64
+	count     int64
+	oldest    int64
+	replaying bool
+}
+
+// diskQueue is a bounded write-ahead queue of points that could not be sent.
+// Points are appended to numbered segment files and replayed oldest first.
+// Once the queue exceeds maxBytes the oldest segments are dropped, and points
+// older than maxAge are dropped on replay.
+type diskQueue struct {
+	dir         string
+	maxBytes    int64
+	maxAge      time.Duration
+	segmentSize int64
+
+	mtx      sync.Mutex
+	segments []*segment
+	head     *os.File
+	nextSeq  uint64
+}
+
+func newDiskQueue(dir string, maxBytes int64, maxAge time.Duration) (*diskQueue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating buffer directory: %v", err)
+	}
+	segmentSize := maxBytes / 16
+	if segmentSize < minSegmentSize {
+		segmentSize = minSegmentSize
+	}
+	if segmentSize > maxSegmentSize {
+		segmentSize = maxSegmentSize
+	}
+	q := &diskQueue{
+		dir:         dir,
+		maxBytes:    maxBytes,
+		maxAge:      maxAge,
+		segmentSize: segmentSize,
+	}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	q.updateMetrics()
+	return q, nil
+}
+
+// load recovers the segments left behind by a previous run.
+func (q *diskQueue) load() error {
+	files, err := ioutil.ReadDir(q.dir)
+	if err != nil {
+		return fmt.Errorf("error reading buffer directory: %v", err)
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), segmentSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(file.Name(), segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		seg := &segment{
+			seq:  seq,
+			path: filepath.Join(q.dir, file.Name()),
+			size: file.Size(),
+		}
+		points, err := readSegment(seg.path)
+		if err != nil {
+			log.WithField("file", seg.path).Errorf("error reading buffer segment: %v", err)
+			continue
+		}
+		if len(points) == 0 {
+			os.Remove(seg.path)
+			continue
+		}
+		seg.count = int64(len(points))
+		seg.oldest = points[0].Enqueued
+		q.segments = append(q.segments, seg)
+		if seq >= q.nextSeq {
+			q.nextSeq = seq + 1
+		}
+	}
+	sort.Slice(q.segments, func(i, j int) bool {
+		return q.segments[i].seq < q.segments[j].seq
+	})
+	if len(q.segments) > 0 {
+		log.WithFields(log.Fields{
+			"points":   q.depth(),
+			"segments": len(q.segments),
+		}).Info("recovered buffered points")
+	}
+	return nil
+}
+
+// enqueue durably appends the given points to the queue.
+func (q *diskQueue) enqueue(points []bufferedPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	defer q.updateMetrics()
+
+	now := time.Now().Unix()
+	var buf []byte
+	for i := range points {
+		points[i].Enqueued = now
+		b, err := json.Marshal(&points[i])
+		if err != nil {
+			bufferErrors.Inc(1)
+			continue
+		}
+		buf = append(buf, b...)
+		buf = append(buf, '\n')
+	}
+
+	seg, err := q.writableSegment()
+	if err != nil {
+		bufferErrors.Inc(1)
+		return err
+	}
+	if _, err := q.head.Write(buf); err != nil {
+		bufferErrors.Inc(1)
+		return fmt.Errorf("error writing to buffer: %v", err)
+	}
+	if err := q.head.Sync(); err != nil {
+		bufferErrors.Inc(1)
+		return fmt.Errorf("error syncing buffer: %v", err)
+	}
+	if seg.count == 0 {
+		seg.oldest = now
+	}
+	seg.size += int64(len(buf))
+	seg.count += int64(len(points))
+	bufferedPoints.Inc(int64(len(points)))
+
+	q.enforceSize()
+	return nil
+}
+
+// writableSegment returns the segment currently open for appends, rotating it if full.
+func (q *diskQueue) writableSegment() (*segment, error) {
+	if q.head != nil {
+		seg := q.segments[len(q.segments)-1]
+		if seg.size < q.segmentSize {
+			return seg, nil
+		}
+		q.seal()
+	}
+	seg := &segment{
+		seq:  q.nextSeq,
+		path: filepath.Join(q.dir, fmt.Sprintf("%020d%s", q.nextSeq, segmentSuffix)),
+	}
+	f, err := os.OpenFile(seg.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error creating buffer segment: %v", err)
+	}
+	q.nextSeq++
+	q.head = f
+	q.segments = append(q.segments, seg)
+	return seg, nil
+}
+
+// seal closes the segment open for appends. Must be called with the lock held.
+func (q *diskQueue) seal() {
+	if q.head != nil {
+		q.head.Close()
+		q.head = nil
+	}
+}
+
+// enforceSize drops the oldest segments while the queue exceeds its maximum size.
+// Must be called with the lock held.
+func (q *diskQueue) enforceSize() {
+	for q.size() > q.maxBytes && len(q.segments) > 1 {
+		idx := 0
+		if q.segments[0].replaying {
+			idx = 1
+		}
+		if idx == len(q.segments)-1 {
+			// never drop the segment currently open for appends
+			return
+		}
+		seg := q.segments[idx]
+		q.segments = append(q.segments[:idx], q.segments[idx+1:]...)
+		os.Remove(seg.path)
+		droppedPoints.Inc(seg.count)
+		log.WithFields(log.Fields{
+			"points": seg.count,
+			"file":   seg.path,
+		}).Warning("buffer full, dropping oldest points")
+	}
+}
+
+// replay sends the points in the oldest segment until the segment is exhausted or send fails.
+// Points that could not be sent remain in the queue.
+func (q *diskQueue) replay(send func(point bufferedPoint) error) error {
+	q.mtx.Lock()
+	if len(q.segments) == 0 {
+		q.mtx.Unlock()
+		return nil
+	}
+	seg := q.segments[0]
+	if len(q.segments) == 1 {
+		// stop appending to the segment being replayed
+		q.seal()
+	}
+	seg.replaying = true
+	q.mtx.Unlock()
+
+	points, err := readSegment(seg.path)
+	if err != nil {
+		// keep the segment so a transient error does not lose the buffered points
+		bufferErrors.Inc(1)
+		q.mtx.Lock()
+		seg.replaying = false
+		q.mtx.Unlock()
+		return fmt.Errorf("error reading buffer segment %s: %v", seg.path, err)
+	}
+
+	cutoff := time.Now().Add(-q.maxAge).Unix()
+	var sendErr error
+	sent, expired := 0, 0
+	for _, point := range points {
+		if q.maxAge > 0 && point.Enqueued < cutoff {
+			expired++
+			sent++
+			continue
+		}
+		if sendErr = send(point); sendErr != nil {
+			break
+		}
+		sent++
+	}
+	droppedPoints.Inc(int64(expired))
+	replayedPoints.Inc(int64(sent - expired))
+
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	defer q.updateMetrics()
+	seg.replaying = false
+
+	if sent < len(points) {
+		if err := q.rewrite(seg, points[sent:]); err != nil {
+			bufferErrors.Inc(1)
+			log.WithField("file", seg.path).Errorf("error rewriting buffer segment: %v", err)
+		}
+		return sendErr
+	}
+	q.remove(seg)
+	return nil
+}
+
+// rewrite replaces the contents of a sealed segment with the given points.
+// Must be called with the lock held.
+func (q *diskQueue) rewrite(seg *segment, points []bufferedPoint) error {
+	var buf []byte
+	for i := range points {
+		b, err := json.Marshal(&points[i])
+		if err != nil {
+			bufferErrors.Inc(1)
+			continue
+		}
+		buf = append(buf, b...)
+		buf = append(buf, '\n')
+	}
+	tmp := seg.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, seg.path); err != nil {
+		return err
+	}
+	seg.size = int64(len(buf))
+	seg.count = int64(len(points))
+	seg.oldest = points[0].Enqueued
+	return nil
+}
+
+// remove deletes a fully replayed segment. Must be called with the lock held.
+func (q *diskQueue) remove(seg *segment) {
+	for i, s := range q.segments {
+		if s == seg {
+			q.segments = append(q.segments[:i], q.segments[i+1:]...)
+			break
+		}
+	}
+	os.Remove(seg.path)
+}
+
+func (q *diskQueue) empty() bool {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return len(q.segments) == 0
+}
+
+func (q *diskQueue) close() {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	q.seal()
+}
+
+func (q *diskQueue) depth() int64 {
+	var count int64
+	for _, seg := range q.segments {
+		count += seg.count
+	}
+	return count
+}
+
+func (q *diskQueue) size() int64 {
+	var size int64
+	for _, seg := range q.segments {
+		size += seg.size
+	}
+	return size
+}
+
+// oldestAge returns the age of the oldest buffered point.
+func (q *diskQueue) oldestAge() time.Duration {
+	if len(q.segments) == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(q.segments[0].oldest, 0))
+}
+
+func (q *diskQueue) updateMetrics() {
+	bufferDepth.Update(q.depth())
+	bufferSize.Update(q.size())
+	bufferOldestAge.Update(int64(q.oldestAge().Seconds()))
+}
+
+func readSegment(path string) ([]bufferedPoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var points []bufferedPoint
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxSegmentSize)
+	for scanner.Scan() {
+		var point bufferedPoint
+		if err := json.Unmarshal(scanner.Bytes(), &point); err != nil {
+			// skip partially written or corrupt entries
+			bufferErrors.Inc(1)
+			log.WithField("file", path).Debugf("skipping corrupt buffered point: %v", err)
+			continue
+		}
+		points = append(points, point)
+	}
+	return points, scanner.Err()
+}
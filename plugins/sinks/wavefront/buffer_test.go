@@ -0,0 +1,183 @@
+package wavefront
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQueue(t *testing.T, maxBytes int64, maxAge time.Duration) (*diskQueue, string) {
+	dir, err := ioutil.TempDir("", "wf-buffer")
+	require.NoError(t, err)
+	q, err := newDiskQueue(dir, maxBytes, maxAge)
+	require.NoError(t, err)
+	return q, dir
+}
+
+func testPoints(n int) []bufferedPoint {
+	points := make([]bufferedPoint, n)
+	for i := range points {
+		points[i] = bufferedPoint{
+			Metric:    "kubernetes.pod.cpu.usage_rate",
+			Value:     float64(i),
+			Timestamp: 1000,
+			Source:    "node1",
+			Tags:      map[string]string{"cluster": "test"},
+		}
+	}
+	return points
+}
+
+func TestReplayAll(t *testing.T) {
+	q, dir := newTestQueue(t, 1024*1024, time.Hour)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, q.enqueue(testPoints(10)))
+	assert.Equal(t, int64(10), q.depth())
+
+	var sent []bufferedPoint
+	err := q.replay(func(p bufferedPoint) error {
+		sent = append(sent, p)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, len(sent))
+	assert.Equal(t, float64(9), sent[9].Value)
+	assert.Equal(t, "test", sent[0].Tags["cluster"])
+	assert.True(t, q.empty())
+}
+
+func TestReplayPartialFailure(t *testing.T) {
+	q, dir := newTestQueue(t, 1024*1024, time.Hour)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, q.enqueue(testPoints(10)))
+
+	count := 0
+	err := q.replay(func(p bufferedPoint) error {
+		if count == 4 {
+			return errors.New("connection refused")
+		}
+		count++
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, int64(6), q.depth())
+
+	// points appended after a failed replay go to a new segment
+	require.NoError(t, q.enqueue(testPoints(2)))
+	assert.Equal(t, int64(8), q.depth())
+
+	var values []float64
+	for !q.empty() {
+		require.NoError(t, q.replay(func(p bufferedPoint) error {
+			values = append(values, p.Value)
+			return nil
+		}))
+	}
+	assert.Equal(t, []float64{4, 5, 6, 7, 8, 9, 0, 1}, values)
+}
+
+func TestMaxAge(t *testing.T) {
+	q, dir := newTestQueue(t, 1024*1024, time.Minute)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, q.enqueue(testPoints(5)))
+	q.segments[0].oldest = time.Now().Add(-time.Hour).Unix()
+	assert.True(t, q.oldestAge() > 59*time.Minute)
+
+	// rewrite the segment with expired entries
+	points, err := readSegment(q.segments[0].path)
+	require.NoError(t, err)
+	for i := range points {
+		points[i].Enqueued = time.Now().Add(-time.Hour).Unix()
+	}
+	q.seal()
+	require.NoError(t, q.rewrite(q.segments[0], points))
+
+	sent := 0
+	require.NoError(t, q.replay(func(p bufferedPoint) error {
+		sent++
+		return nil
+	}))
+	assert.Equal(t, 0, sent)
+	assert.True(t, q.empty())
+}
+
+func TestMaxSize(t *testing.T) {
+	q, dir := newTestQueue(t, 2*minSegmentSize, time.Hour)
+	defer os.RemoveAll(dir)
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, q.enqueue(testPoints(100)))
+	}
+	assert.True(t, q.size() <= 2*minSegmentSize+q.segments[len(q.segments)-1].size)
+	assert.True(t, q.depth() < 100*100)
+}
+
+func TestRecovery(t *testing.T) {
+	q, dir := newTestQueue(t, 1024*1024, time.Hour)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, q.enqueue(testPoints(3)))
+	q.close()
+
+	recovered, err := newDiskQueue(dir, 1024*1024, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), recovered.depth())
+
+	require.NoError(t, recovered.enqueue(testPoints(2)))
+	assert.Equal(t, 2, len(recovered.segments))
+	assert.Equal(t, int64(5), recovered.depth())
+}
+
+func TestReplayReadError(t *testing.T) {
+	q, dir := newTestQueue(t, 1024*1024, time.Hour)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, q.enqueue(testPoints(3)))
+	path := q.segments[0].path
+
+	// a segment that cannot be read is kept for the next replay
+	require.NoError(t, os.Rename(path, path+".bak"))
+	err := q.replay(func(p bufferedPoint) error {
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, int64(3), q.depth())
+
+	require.NoError(t, os.Rename(path+".bak", path))
+	sent := 0
+	require.NoError(t, q.replay(func(p bufferedPoint) error {
+		sent++
+		return nil
+	}))
+	assert.Equal(t, 3, sent)
+	assert.True(t, q.empty())
+}
+
+func TestCorruptPoints(t *testing.T) {
+	q, dir := newTestQueue(t, 1024*1024, time.Hour)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, q.enqueue(testPoints(2)))
+	f, err := os.OpenFile(q.segments[0].path, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("{\"metric\":\"trunc\n")
+	require.NoError(t, err)
+	f.Close()
+
+	before := bufferErrors.Count()
+	sent := 0
+	require.NoError(t, q.replay(func(p bufferedPoint) error {
+		sent++
+		return nil
+	}))
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, before+1, bufferErrors.Count())
+}
@@ -1,12 +1,16 @@
 package wavefront
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/events"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/relabel"
 )
 
 func NewFakeWavefrontSink() *wavefrontSink {
@@ -46,3 +50,58 @@ func TestCreateWavefrontSinkWithNoEmptyInputs(t *testing.T) {
 	assert.Equal(t, "testCluster", wfSink.ClusterName)
 	assert.Equal(t, "testPrefix", wfSink.Prefix)
 }
+
+func TestExportDistributions(t *testing.T) {
+	fakeSink := NewFakeWavefrontSink()
+	db := metrics.DataBatch{
+		Distributions: []*metrics.Distribution{{
+			Metric:        "request.latency",
+			Centroids:     []metrics.Centroid{{Value: 0.5, Count: 3}},
+			Granularities: []metrics.Granularity{metrics.MinuteGranularity},
+			Timestamp:     1520879607789,
+			Source:        "node1",
+		}},
+	}
+	fakeSink.ExportData(&db)
+	assert.Equal(t, 1, len(fakeSink.testReceivedLines))
+	assert.Equal(t, "!M 1520879607789 #3 0.500000 request.latency source=\"node1\" cluster=\"testCluster\"\n", fakeSink.testReceivedLines[0])
+}
+
+func TestRelabel(t *testing.T) {
+	fakeSink := NewFakeWavefrontSink()
+	relabeler, err := relabel.FromConfig([]relabel.Config{{
+		SourceLabels: []string{"code"},
+		Regex:        "5..",
+		Action:       relabel.Drop,
+	}})
+	assert.NoError(t, err)
+	fakeSink.relabeler = relabeler
+
+	db := metrics.DataBatch{
+		MetricPoints: []*metrics.MetricPoint{
+			{Metric: "requests", Value: 1, Source: "node1", StrTags: " code=200"},
+			{Metric: "requests", Value: 2, Source: "node1", StrTags: " code=500"},
+		},
+	}
+	fakeSink.ExportData(&db)
+	assert.Equal(t, 1, len(fakeSink.testReceivedLines))
+}
+
+func TestExportEvent(t *testing.T) {
+	fakeSink := NewFakeWavefrontSink()
+	fakeSink.ExportEvent(&events.Event{
+		Name:      "BackOff",
+		Message:   "Back-off restarting failed container",
+		Timestamp: time.Unix(1520879607, 0),
+		Source:    "node1",
+		Severity:  events.SeverityWarn,
+		Type:      "Warning",
+		Tags:      map[string]string{"namespace_name": "default"},
+	})
+	assert.Equal(t, 1, len(fakeSink.testReceivedLines))
+	line := fakeSink.testReceivedLines[0]
+	assert.True(t, strings.HasPrefix(line, "@Event 1520879607000 \"BackOff\" severity=\"warn\" type=\"Warning\""))
+	assert.Contains(t, line, "host=\"node1\"")
+	assert.Contains(t, line, "namespace_name=\"default\"")
+	assert.Contains(t, line, "cluster=\"testCluster\"")
+}
@@ -0,0 +1,32 @@
+package wavefront
+
+import (
+	"fmt"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+type factory struct{}
+
+// Returns a new wavefront sink factory
+func NewFactory() metrics.SinkFactory {
+	return factory{}
+}
+
+func (f factory) Build(cfg interface{}) (metrics.DataSink, error) {
+	c, ok := cfg.(*configuration.WavefrontSinkConfig)
+	if !ok || c == nil {
+		return nil, fmt.Errorf("invalid wavefront sink configuration: %T", cfg)
+	}
+	return NewWavefrontSink(*c)
+}
+
+// NewConfig returns a new configuration of the wavefront sink
+func (f factory) NewConfig() interface{} {
+	return &configuration.WavefrontSinkConfig{}
+}
+
+func (f factory) Name() string {
+	return configuration.WavefrontSinkType
+}
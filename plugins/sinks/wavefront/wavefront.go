@@ -3,11 +3,17 @@ package wavefront
 import (
 	"fmt"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/events"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/relabel"
+	"github.com/wavefronthq/wavefront-sdk-go/event"
+	"github.com/wavefronthq/wavefront-sdk-go/histogram"
 	"github.com/wavefronthq/wavefront-sdk-go/senders"
 
 	gm "github.com/rcrowley/go-metrics"
@@ -17,21 +23,34 @@ import (
 const (
 	proxyClient  = 1
 	directClient = 2
+
+	defaultBufferDir        = "/var/lib/wavefront-collector/buffer"
+	defaultBufferSizeMB     = 256
+	defaultBufferMaxAge     = 24 * time.Hour
+	defaultRetryInterval    = 5 * time.Second
+	defaultMaxRetryInterval = 5 * time.Minute
 )
 
 var (
-	excludeTagList = [...]string{"namespace_id", "host_id", "pod_id", "hostname"}
-	sentPoints     gm.Counter
-	errPoints      gm.Counter
-	msCount        gm.Counter
-	filteredPoints gm.Counter
-	clientType     gm.Gauge
-	sanitizedChars = strings.NewReplacer("+", "-")
+	excludeTagList    = [...]string{"namespace_id", "host_id", "pod_id", "hostname"}
+	sentPoints        gm.Counter
+	sentDistributions gm.Counter
+	sentEvents        gm.Counter
+	errEvents         gm.Counter
+	errPoints         gm.Counter
+	msCount           gm.Counter
+	filteredPoints    gm.Counter
+	clientType        gm.Gauge
+	sanitizedChars    = strings.NewReplacer("+", "-")
+	bufferDirChars    = strings.NewReplacer("https://", "", "http://", "", "/", "_", ":", "_")
 )
 
 func init() {
 	sentPoints = gm.GetOrRegisterCounter("wavefront.points.sent.count", gm.DefaultRegistry)
 	errPoints = gm.GetOrRegisterCounter("wavefront.points.errors.count", gm.DefaultRegistry)
+	sentDistributions = gm.GetOrRegisterCounter("wavefront.distributions.sent.count", gm.DefaultRegistry)
+	sentEvents = gm.GetOrRegisterCounter("wavefront.events.sent.count", gm.DefaultRegistry)
+	errEvents = gm.GetOrRegisterCounter("wavefront.events.errors.count", gm.DefaultRegistry)
 	msCount = gm.GetOrRegisterCounter("wavefront.points.metric-sets.count", gm.DefaultRegistry)
 	filteredPoints = gm.GetOrRegisterCounter("wavefront.points.filtered.count", gm.DefaultRegistry)
 	clientType = gm.GetOrRegisterGauge("wavefront.sender.type", gm.DefaultRegistry)
@@ -43,8 +62,14 @@ type wavefrontSink struct {
 	Prefix            string
 	globalTags        map[string]string
 	filters           filter.Filter
+	relabeler         *relabel.Relabeler
 	testMode          bool
 	testReceivedLines []string
+	queue             *diskQueue
+	failedPoints      []bufferedPoint
+	retryInterval     time.Duration
+	maxRetryInterval  time.Duration
+	stopCh            chan struct{}
 }
 
 func (sink *wavefrontSink) Name() string {
@@ -52,19 +77,19 @@ func (sink *wavefrontSink) Name() string {
 }
 
 func (sink *wavefrontSink) Stop() {
+	if sink.queue != nil {
+		close(sink.stopCh)
+		sink.queue.close()
+	}
 	sink.WavefrontClient.Close()
 }
 
 func (sink *wavefrontSink) sendPoint(metricName string, value float64, ts int64, source string, tags map[string]string) {
-	metricName = sanitizedChars.Replace(metricName)
-	if sink.filters != nil && !sink.filters.Match(metricName, tags) {
-		filteredPoints.Inc(1)
-		log.WithField("name", metricName).Trace("Dropping metric")
+	metricName, tags, ok := sink.preparePoint(metricName, tags)
+	if !ok {
 		return
 	}
 
-	tags = combineGlobalTags(tags, sink.globalTags)
-
 	if sink.testMode {
 		tagStr := ""
 		for k, v := range tags {
@@ -82,11 +107,135 @@ func (sink *wavefrontSink) sendPoint(metricName string, value float64, ts int64,
 			"name":  metricName,
 			"error": err,
 		}).Debug("error sending metric")
+		if sink.queue != nil {
+			sink.failedPoints = append(sink.failedPoints, bufferedPoint{
+				Metric:    metricName,
+				Value:     value,
+				Timestamp: ts,
+				Source:    source,
+				Tags:      tags,
+			})
+		}
 	} else {
 		sentPoints.Inc(1)
 	}
 }
 
+func (sink *wavefrontSink) sendDistribution(dist *metrics.Distribution) {
+	name, tags, ok := sink.preparePoint(dist.Metric, sink.buildTags(dist.Tags, dist.StrTags))
+	if !ok {
+		return
+	}
+
+	if sink.testMode {
+		line := fmt.Sprintf("%s %d", granularityPrefix(dist.Granularities), dist.Timestamp)
+		for _, c := range dist.Centroids {
+			line += fmt.Sprintf(" #%d %f", c.Count, c.Value)
+		}
+		line += fmt.Sprintf(" %s source=\"%s\"", name, dist.Source)
+		for k, v := range tags {
+			line += " " + k + "=\"" + v + "\""
+		}
+		sink.testReceivedLines = append(sink.testReceivedLines, line+"\n")
+		log.Infoln(line)
+		return
+	}
+
+	centroids := make([]histogram.Centroid, len(dist.Centroids))
+	for i, c := range dist.Centroids {
+		centroids[i] = histogram.Centroid{Value: c.Value, Count: c.Count}
+	}
+	granularities := make(map[histogram.Granularity]bool, len(dist.Granularities))
+	for _, g := range dist.Granularities {
+		granularities[toHistogramGranularity(g)] = true
+	}
+	err := sink.WavefrontClient.SendDistribution(name, centroids, granularities, dist.Timestamp, dist.Source, tags)
+	if err != nil {
+		errPoints.Inc(1)
+		log.WithFields(log.Fields{
+			"name":  name,
+			"error": err,
+		}).Debug("error sending distribution")
+	} else {
+		sentDistributions.Inc(1)
+	}
+}
+
+// ExportEvent sends a Kubernetes event to Wavefront.
+func (sink *wavefrontSink) ExportEvent(e *events.Event) {
+	tags := make(map[string]string, len(e.Tags)+len(sink.globalTags)+1)
+	for k, v := range e.Tags {
+		if len(v) > 0 {
+			tags[k] = v
+		}
+	}
+	tags["cluster"] = sink.ClusterName
+	tags = combineGlobalTags(tags, sink.globalTags)
+	ts := metrics.UnixMillis(e.Timestamp)
+
+	if sink.testMode {
+		line := fmt.Sprintf("@Event %d \"%s\" severity=\"%s\" type=\"%s\" details=\"%s\" host=\"%s\"", ts, e.Name, e.Severity, e.Type, e.Message, e.Source)
+		for k, v := range tags {
+			line += " " + k + "=\"" + v + "\""
+		}
+		sink.testReceivedLines = append(sink.testReceivedLines, line+"\n")
+		log.Infoln(line)
+		return
+	}
+
+	err := sink.WavefrontClient.SendEvent(e.Name, ts, 0, e.Source, tags,
+		event.Severity(e.Severity), event.Type(e.Type), event.Details(e.Message))
+	if err != nil {
+		errEvents.Inc(1)
+		log.WithFields(log.Fields{
+			"name":  e.Name,
+			"error": err,
+		}).Debug("error sending event")
+	} else {
+		sentEvents.Inc(1)
+	}
+}
+
+func toHistogramGranularity(g metrics.Granularity) histogram.Granularity {
+	switch g {
+	case metrics.HourGranularity:
+		return histogram.HOUR
+	case metrics.DayGranularity:
+		return histogram.DAY
+	default:
+		return histogram.MINUTE
+	}
+}
+
+func granularityPrefix(granularities []metrics.Granularity) string {
+	prefix := ""
+	for _, g := range granularities {
+		hg := toHistogramGranularity(g)
+		prefix += hg.String()
+	}
+	return prefix
+}
+
+// preparePoint relabels and sanitizes the metric name, applies the filters and adds the global tags.
+// Returns false if the point should be dropped.
+func (sink *wavefrontSink) preparePoint(metricName string, tags map[string]string) (string, map[string]string, bool) {
+	if sink.relabeler != nil {
+		var ok bool
+		if metricName, tags, ok = sink.relabeler.Relabel(metricName, tags); !ok {
+			filteredPoints.Inc(1)
+			log.WithField("name", metricName).Trace("Dropping metric")
+			return "", nil, false
+		}
+	}
+	metricName = sanitizedChars.Replace(metricName)
+	if sink.filters != nil && !sink.filters.Match(metricName, tags) {
+		filteredPoints.Inc(1)
+		log.WithField("name", metricName).Trace("Dropping metric")
+		return "", nil, false
+	}
+	return metricName, combineGlobalTags(tags, sink.globalTags), true
+}
+
 func combineGlobalTags(tags, globalTags map[string]string) map[string]string {
 	if tags == nil || len(tags) == 0 {
 		return globalTags
@@ -104,38 +253,110 @@ func combineGlobalTags(tags, globalTags map[string]string) map[string]string {
 	return tags
 }
 
-func (sink *wavefrontSink) send(batch *metrics.DataBatch) {
-	log.Debugf("received metric points: %d", len(batch.MetricPoints))
-
-	before := errPoints.Count()
-	for _, point := range batch.MetricPoints {
-		tags := make(map[string]string)
+func (sink *wavefrontSink) buildTags(pointTags map[string]string, strTags string) map[string]string {
+	tags := make(map[string]string)
 
-		for k, v := range point.Tags {
-			if len(v) > 0 {
-				tags[k] = v
-			}
+	for k, v := range pointTags {
+		if len(v) > 0 {
+			tags[k] = v
 		}
+	}
 
-		if len(point.StrTags) > 0 {
-			for _, tag := range strings.Split(point.StrTags, " ") {
-				if len(tag) > 0 {
-					s := strings.Split(tag, "=")
-					k, v := s[0], s[1]
-					if len(v) > 0 {
-						tags[k] = v
-					}
+	if len(strTags) > 0 {
+		for _, tag := range strings.Split(strTags, " ") {
+			if len(tag) > 0 {
+				s := strings.Split(tag, "=")
+				k, v := s[0], s[1]
+				if len(v) > 0 {
+					tags[k] = v
 				}
 			}
 		}
-		tags["cluster"] = sink.ClusterName
-		sink.sendPoint(point.Metric, point.Value, point.Timestamp, point.Source, tags)
+	}
+	tags["cluster"] = sink.ClusterName
+	return tags
+}
+
+func (sink *wavefrontSink) send(batch *metrics.DataBatch) {
+	log.Debugf("received metric points: %d", len(batch.MetricPoints))
+
+	before := errPoints.Count()
+	for _, point := range batch.MetricPoints {
+		sink.sendPoint(point.Metric, point.Value, point.Timestamp, point.Source, sink.buildTags(point.Tags, point.StrTags))
+	}
+
+	for _, dist := range batch.Distributions {
+		sink.sendDistribution(dist)
 	}
 
 	after := errPoints.Count()
 	if after > before {
 		log.WithField("count", after).Warning("Error sending one or more points")
 	}
+
+	if len(sink.failedPoints) > 0 {
+		if err := sink.queue.enqueue(sink.failedPoints); err != nil {
+			log.Errorf("error buffering points: %v", err)
+		}
+		sink.failedPoints = sink.failedPoints[:0]
+	}
+}
+
+// BufferData writes a batch that could not be exported in time directly to the disk buffer.
+func (sink *wavefrontSink) BufferData(batch *metrics.DataBatch) bool {
+	if sink.queue == nil {
+		return false
+	}
+	points := make([]bufferedPoint, 0, len(batch.MetricPoints))
+	for _, point := range batch.MetricPoints {
+		name, tags, ok := sink.preparePoint(point.Metric, sink.buildTags(point.Tags, point.StrTags))
+		if !ok {
+			continue
+		}
+		points = append(points, bufferedPoint{
+			Metric:    name,
+			Value:     point.Value,
+			Timestamp: point.Timestamp,
+			Source:    point.Source,
+			Tags:      tags,
+		})
+	}
+	if err := sink.queue.enqueue(points); err != nil {
+		log.Errorf("error buffering points: %v", err)
+		return false
+	}
+	return true
+}
+
+// replay periodically resends buffered points, backing off exponentially while sending fails.
+func (sink *wavefrontSink) replay() {
+	interval := sink.retryInterval
+	for {
+		select {
+		case <-sink.stopCh:
+			return
+		case <-time.After(interval):
+		}
+		if sink.queue.empty() {
+			interval = sink.retryInterval
+			continue
+		}
+		err := sink.queue.replay(func(p bufferedPoint) error {
+			return sink.WavefrontClient.SendMetric(p.Metric, p.Value, p.Timestamp, p.Source, p.Tags)
+		})
+		if err != nil {
+			interval *= 2
+			if interval > sink.maxRetryInterval {
+				interval = sink.maxRetryInterval
+			}
+			log.WithFields(log.Fields{
+				"error": err,
+				"retry": interval,
+			}).Debug("error replaying buffered points")
+		} else {
+			interval = sink.retryInterval
+		}
+	}
 }
 
 func (sink *wavefrontSink) ExportData(batch *metrics.DataBatch) {
@@ -154,6 +375,12 @@ func NewWavefrontSink(cfg configuration.WavefrontSinkConfig) (metrics.DataSink,
 		testMode:    cfg.TestMode,
 	}
 
+	relabeler, err := relabel.FromConfig(cfg.Relabel)
+	if err != nil {
+		return nil, err
+	}
+	storage.relabeler = relabeler
+
 	if cfg.ProxyAddress != "" {
 		s := strings.Split(cfg.ProxyAddress, ":")
 		host, portStr := s[0], s[1]
@@ -163,8 +390,10 @@ func NewWavefrontSink(cfg configuration.WavefrontSinkConfig) (metrics.DataSink,
 			return nil, fmt.Errorf("error parsing proxy port: %s", err.Error())
 		}
 		storage.WavefrontClient, err = senders.NewProxySender(&senders.ProxyConfiguration{
-			Host:        host,
-			MetricsPort: port,
+			Host:             host,
+			MetricsPort:      port,
+			DistributionPort: port,
+			EventsPort:       port,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("error creating proxy sender: %s", err.Error())
@@ -194,5 +423,31 @@ func NewWavefrontSink(cfg configuration.WavefrontSinkConfig) (metrics.DataSink,
 	}
 	storage.filters = filter.FromConfig(cfg.Filters)
 
+	if cfg.Buffer.Enabled && !cfg.TestMode {
+		address := cfg.ProxyAddress
+		if address == "" {
+			address = cfg.Server
+		}
+		dir := configuration.GetStringValue(cfg.Buffer.Dir, filepath.Join(defaultBufferDir, bufferDirName(address)))
+		maxSize := cfg.Buffer.MaxSizeMB
+		if maxSize <= 0 {
+			maxSize = defaultBufferSizeMB
+		}
+		queue, err := newDiskQueue(dir, maxSize*1024*1024, configuration.GetDurationValue(cfg.Buffer.MaxAge, defaultBufferMaxAge))
+		if err != nil {
+			return nil, fmt.Errorf("error creating buffer: %s", err.Error())
+		}
+		storage.queue = queue
+		storage.retryInterval = configuration.GetDurationValue(cfg.Buffer.RetryInterval, defaultRetryInterval)
+		storage.maxRetryInterval = configuration.GetDurationValue(cfg.Buffer.MaxRetryInterval, defaultMaxRetryInterval)
+		storage.stopCh = make(chan struct{})
+		go storage.replay()
+	}
+
 	return storage, nil
 }
+
+// bufferDirName converts a sink address into a directory name.
+func bufferDirName(address string) string {
+	return bufferDirChars.Replace(address)
+}
@@ -0,0 +1,135 @@
+package controlplane
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/httputil"
+)
+
+const (
+	APIServer         = "apiserver"
+	Scheduler         = "scheduler"
+	ControllerManager = "controller-manager"
+	Etcd              = "etcd"
+
+	serviceAccountCA    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	serviceAccountToken = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// metrics reported by all the control plane components
+var processMetrics = []string{
+	"process.cpu.seconds.total.counter",
+	"process.resident.memory.bytes.gauge",
+	"go.goroutines.gauge",
+}
+
+// defaults for locating and scraping the components. The label selectors match the static pods created by kubeadm.
+var defaultComponents = map[string]configuration.ControlPlaneComponentConfig{
+	APIServer: {
+		Namespace: "default",
+		Endpoints: "kubernetes",
+		Scheme:    "https",
+		HTTPClientConfig: httputil.ClientConfig{
+			BearerTokenFile: serviceAccountToken,
+			TLSConfig: httputil.TLSConfig{
+				CAFile:     serviceAccountCA,
+				ServerName: "kubernetes.default.svc",
+			},
+		},
+		MetricWhitelist: append([]string{
+			"apiserver.request.total.counter",
+			"apiserver.request.duration.seconds*",
+			"apiserver.current.inflight.requests.gauge",
+			"apiserver.storage.objects.gauge",
+			"etcd.request.duration.seconds*",
+		}, processMetrics...),
+	},
+	Scheduler: {
+		Namespace:     "kube-system",
+		LabelSelector: "component=kube-scheduler",
+		Scheme:        "https",
+		Port:          10259,
+		HTTPClientConfig: httputil.ClientConfig{
+			BearerTokenFile: serviceAccountToken,
+			// the scheduler serves metrics using a self-signed certificate by default
+			TLSConfig: httputil.TLSConfig{InsecureSkipVerify: true},
+		},
+		MetricWhitelist: append([]string{
+			"scheduler.schedule.attempts.total.counter",
+			"scheduler.pending.pods.gauge",
+			"scheduler.e2e.scheduling.duration.seconds*",
+			"scheduler.scheduling.attempt.duration.seconds*",
+			"scheduler.pod.scheduling.duration.seconds*",
+		}, processMetrics...),
+	},
+	ControllerManager: {
+		Namespace:     "kube-system",
+		LabelSelector: "component=kube-controller-manager",
+		Scheme:        "https",
+		Port:          10257,
+		HTTPClientConfig: httputil.ClientConfig{
+			BearerTokenFile: serviceAccountToken,
+			// the controller-manager serves metrics using a self-signed certificate by default
+			TLSConfig: httputil.TLSConfig{InsecureSkipVerify: true},
+		},
+		MetricWhitelist: append([]string{
+			"workqueue.depth.gauge",
+			"workqueue.adds.total.counter",
+			"workqueue.retries.total.counter",
+			"workqueue.queue.duration.seconds*",
+			"workqueue.work.duration.seconds*",
+		}, processMetrics...),
+	},
+	// etcd requires client certificates which have to be provided using the httpConfig
+	Etcd: {
+		Namespace:     "kube-system",
+		LabelSelector: "component=etcd",
+		Scheme:        "https",
+		Port:          2379,
+		MetricWhitelist: append([]string{
+			"etcd.server.has.leader.gauge",
+			"etcd.server.leader.changes.seen.total.counter",
+			"etcd.server.proposals.*",
+			"etcd.mvcc.db.total.size.in.bytes.gauge",
+			"etcd.disk.wal.fsync.duration.seconds*",
+			"etcd.disk.backend.commit.duration.seconds*",
+			"etcd.network.peer.round.trip.time.seconds*",
+		}, processMetrics...),
+	},
+}
+
+// components scraped when none are configured
+var defaultComponentNames = []string{APIServer, Scheduler, ControllerManager}
+
+// withDefaults fills the unset properties of the given component using the defaults of the component
+func withDefaults(cfg configuration.ControlPlaneComponentConfig) (configuration.ControlPlaneComponentConfig, error) {
+	defaults, found := defaultComponents[cfg.Name]
+	if !found {
+		return cfg, fmt.Errorf("unknown control plane component: %s", cfg.Name)
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = defaults.Namespace
+	}
+	if cfg.LabelSelector == "" && cfg.Endpoints == "" {
+		cfg.LabelSelector = defaults.LabelSelector
+		cfg.Endpoints = defaults.Endpoints
+	}
+	if cfg.Scheme == "" {
+		cfg.Scheme = defaults.Scheme
+	}
+	if cfg.Port == 0 {
+		cfg.Port = defaults.Port
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/metrics"
+	}
+	if reflect.DeepEqual(cfg.HTTPClientConfig, httputil.ClientConfig{}) {
+		cfg.HTTPClientConfig = defaults.HTTPClientConfig
+	}
+	if len(cfg.MetricWhitelist) == 0 {
+		cfg.MetricWhitelist = defaults.MetricWhitelist
+	}
+	return cfg, nil
+}
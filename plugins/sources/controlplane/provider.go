@@ -0,0 +1,231 @@
+// Package controlplane provides metrics from the Kubernetes control plane components
+package controlplane
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
+	kube_config "github.com/wavefronthq/wavefront-kubernetes-collector/internal/kubernetes"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/leadership"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/relabel"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources/prometheus"
+
+	"github.com/gobwas/glob"
+	log "github.com/sirupsen/logrus"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	kube_client "k8s.io/client-go/kubernetes"
+)
+
+const providerName = "control_plane_provider"
+
+// target is a single metrics endpoint of a control plane component
+type target struct {
+	url    string
+	source string
+}
+
+type component struct {
+	cfg     configuration.ControlPlaneComponentConfig
+	tags    map[string]string
+	filters filter.Filter
+}
+
+type controlPlaneProvider struct {
+	metrics.DefaultMetricsSourceProvider
+	kubeClient kube_client.Interface
+	prefix     string
+	relabeler  *relabel.Relabeler
+	components []component
+
+	mtx     sync.Mutex
+	sources map[string]metrics.MetricsSource
+}
+
+func (p *controlPlaneProvider) GetMetricsSources() []metrics.MetricsSource {
+	if !leadership.Leading() {
+		log.Infof("not scraping the control plane. current leader: %s", leadership.Leader())
+		return nil
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	// retain the sources of known targets across collections
+	current := make(map[string]metrics.MetricsSource)
+	var result []metrics.MetricsSource
+	for _, c := range p.components {
+		targets, err := p.targets(c.cfg)
+		if err != nil {
+			log.Errorf("error locating control plane component %s: %v", c.cfg.Name, err)
+			continue
+		}
+		for _, t := range targets {
+			src, found := p.sources[t.url]
+			if !found {
+				src, err = prometheus.NewPrometheusMetricsSource(t.url, p.prefix, t.source, "", c.tags, c.filters, p.relabeler,
+					c.cfg.HTTPClientConfig, false, configuration.DistributionConfig{}, nil)
+				if err != nil {
+					log.Errorf("error creating source for control plane component %s: %v", c.cfg.Name, err)
+					continue
+				}
+			}
+			current[t.url] = src
+			result = append(result, src)
+		}
+	}
+	p.sources = current
+	return result
+}
+
+// targets returns the metrics endpoints of the given component
+func (p *controlPlaneProvider) targets(cfg configuration.ControlPlaneComponentConfig) ([]target, error) {
+	var result []target
+	if len(cfg.URLs) > 0 {
+		for _, url := range cfg.URLs {
+			result = append(result, target{url: url, source: cfg.Name})
+		}
+		return result, nil
+	}
+
+	if cfg.Endpoints != "" {
+		endpoints, err := p.listEndpoints(cfg.Namespace, cfg.Endpoints)
+		if err != nil {
+			return nil, err
+		}
+		for _, ep := range endpoints.Items {
+			for _, subset := range ep.Subsets {
+				port := endpointPort(subset, cfg.Port)
+				for _, addr := range subset.Addresses {
+					source := addr.IP
+					if addr.NodeName != nil && *addr.NodeName != "" {
+						source = *addr.NodeName
+					}
+					result = append(result, target{url: metricsURL(cfg, addr.IP, port), source: source})
+				}
+			}
+		}
+		return result, nil
+	}
+
+	pods, err := p.listPods(cfg.Namespace, cfg.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != v1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		result = append(result, target{url: metricsURL(cfg, pod.Status.PodIP, cfg.Port), source: pod.Spec.NodeName})
+	}
+	return result, nil
+}
+
+func (p *controlPlaneProvider) listEndpoints(namespace, name string) (*v1.EndpointsList, error) {
+	endpoints := p.kubeClient.CoreV1().Endpoints(namespace)
+	options := metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String()}
+	return endpoints.List(options)
+}
+
+func (p *controlPlaneProvider) listPods(namespace, labelSelector string) (*v1.PodList, error) {
+	pods := p.kubeClient.CoreV1().Pods(namespace)
+	options := metav1.ListOptions{LabelSelector: labelSelector}
+	return pods.List(options)
+}
+
+// endpointPort returns the configured port or else the https port of the given endpoints
+func endpointPort(subset v1.EndpointSubset, port int) int {
+	if port > 0 || len(subset.Ports) == 0 {
+		return port
+	}
+	for _, p := range subset.Ports {
+		if p.Name == "https" {
+			return int(p.Port)
+		}
+	}
+	return int(subset.Ports[0].Port)
+}
+
+func metricsURL(cfg configuration.ControlPlaneComponentConfig, ip string, port int) string {
+	return fmt.Sprintf("%s://%s%s", cfg.Scheme, net.JoinHostPort(ip, strconv.Itoa(port)), cfg.Path)
+}
+
+func (p *controlPlaneProvider) Name() string {
+	return providerName
+}
+
+// whitelistFilter only matches metrics on the curated list of a component before applying the source filters
+type whitelistFilter struct {
+	whitelist glob.Glob
+	filters   filter.Filter
+}
+
+func (wf *whitelistFilter) Match(name string, tags map[string]string) bool {
+	if wf.whitelist != nil && !wf.whitelist.Match(name) {
+		return false
+	}
+	return wf.filters == nil || wf.filters.Match(name, tags)
+}
+
+func NewControlPlaneProvider(cfg configuration.ControlPlaneSourceConfig, summaryCfg configuration.SummaySourceConfig) (metrics.MetricsSourceProvider, error) {
+	kubeConfig, err := kube_config.GetKubeClientConfig(summaryCfg)
+	if err != nil {
+		return nil, err
+	}
+	return newControlPlaneProvider(kube_client.NewForConfigOrDie(kubeConfig), cfg)
+}
+
+func newControlPlaneProvider(kubeClient kube_client.Interface, cfg configuration.ControlPlaneSourceConfig) (*controlPlaneProvider, error) {
+	prefix := configuration.GetStringValue(cfg.Prefix, "kubernetes.controlplane.")
+	relabeler, err := relabel.FromConfig(cfg.Relabel)
+	if err != nil {
+		return nil, err
+	}
+
+	componentCfgs := cfg.Components
+	if len(componentCfgs) == 0 {
+		for _, name := range defaultComponentNames {
+			componentCfgs = append(componentCfgs, configuration.ControlPlaneComponentConfig{Name: name})
+		}
+	}
+
+	filters := filter.FromConfig(cfg.Filters)
+	var components []component
+	for _, componentCfg := range componentCfgs {
+		componentCfg, err := withDefaults(componentCfg)
+		if err != nil {
+			return nil, err
+		}
+		whitelist := make([]string, len(componentCfg.MetricWhitelist))
+		for i, pattern := range componentCfg.MetricWhitelist {
+			whitelist[i] = prefix + pattern
+		}
+
+		tags := make(map[string]string, len(cfg.Tags)+1)
+		for k, v := range cfg.Tags {
+			tags[k] = v
+		}
+		tags["component"] = componentCfg.Name
+
+		components = append(components, component{
+			cfg:     componentCfg,
+			tags:    tags,
+			filters: &whitelistFilter{whitelist: filter.Compile(whitelist), filters: filters},
+		})
+	}
+
+	return &controlPlaneProvider{
+		kubeClient: kubeClient,
+		prefix:     prefix,
+		relabeler:  relabeler,
+		components: components,
+		sources:    make(map[string]metrics.MetricsSource),
+	}, nil
+}
@@ -0,0 +1,100 @@
+package controlplane
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func fakeClient() *fake.Clientset {
+	node := "master-1"
+	return fake.NewSimpleClientset(
+		&v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "kubernetes", Namespace: "default"},
+			Subsets: []v1.EndpointSubset{{
+				Addresses: []v1.EndpointAddress{{IP: "10.0.0.1", NodeName: &node}, {IP: "10.0.0.2"}},
+				Ports:     []v1.EndpointPort{{Name: "https", Port: 6443}},
+			}},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-scheduler-master-1", Namespace: "kube-system", Labels: map[string]string{"component": "kube-scheduler"}},
+			Spec:       v1.PodSpec{NodeName: "master-1"},
+			Status:     v1.PodStatus{Phase: v1.PodRunning, PodIP: "10.0.0.1"},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-scheduler-master-2", Namespace: "kube-system", Labels: map[string]string{"component": "kube-scheduler"}},
+			Spec:       v1.PodSpec{NodeName: "master-2"},
+			Status:     v1.PodStatus{Phase: v1.PodPending},
+		},
+	)
+}
+
+func TestTargets(t *testing.T) {
+	p, err := newControlPlaneProvider(fakeClient(), configuration.ControlPlaneSourceConfig{})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(p.components))
+
+	targets, err := p.targets(p.components[0].cfg)
+	require.NoError(t, err)
+	sort.Slice(targets, func(i, j int) bool { return targets[i].url < targets[j].url })
+	assert.Equal(t, []target{
+		{url: "https://10.0.0.1:6443/metrics", source: "master-1"},
+		{url: "https://10.0.0.2:6443/metrics", source: "10.0.0.2"},
+	}, targets)
+
+	targets, err = p.targets(p.components[1].cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []target{{url: "https://10.0.0.1:10259/metrics", source: "master-1"}}, targets)
+
+	targets, err = p.targets(p.components[2].cfg)
+	require.NoError(t, err)
+	assert.Empty(t, targets)
+}
+
+func TestStaticURLs(t *testing.T) {
+	p, err := newControlPlaneProvider(fakeClient(), configuration.ControlPlaneSourceConfig{
+		Components: []configuration.ControlPlaneComponentConfig{{
+			Name: Etcd,
+			URLs: []string{"https://etcd:2379/metrics"},
+		}},
+	})
+	require.NoError(t, err)
+	targets, err := p.targets(p.components[0].cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []target{{url: "https://etcd:2379/metrics", source: Etcd}}, targets)
+	assert.Equal(t, Etcd, p.components[0].tags["component"])
+}
+
+func TestUnknownComponent(t *testing.T) {
+	_, err := newControlPlaneProvider(fakeClient(), configuration.ControlPlaneSourceConfig{
+		Components: []configuration.ControlPlaneComponentConfig{{Name: "kubelet"}},
+	})
+	assert.Error(t, err)
+}
+
+func TestWhitelist(t *testing.T) {
+	p, err := newControlPlaneProvider(fakeClient(), configuration.ControlPlaneSourceConfig{
+		Components: []configuration.ControlPlaneComponentConfig{
+			{Name: APIServer},
+			{Name: Scheduler, MetricWhitelist: []string{"scheduler.pending.pods.gauge"}},
+		},
+	})
+	require.NoError(t, err)
+
+	apiserver := p.components[0].filters
+	assert.True(t, apiserver.Match("kubernetes.controlplane.apiserver.request.total.counter", nil))
+	assert.True(t, apiserver.Match("kubernetes.controlplane.apiserver.request.duration.seconds.count", nil))
+	assert.False(t, apiserver.Match("kubernetes.controlplane.apiserver.admission.webhook.rejection.count.counter", nil))
+
+	scheduler := p.components[1].filters
+	assert.True(t, scheduler.Match("kubernetes.controlplane.scheduler.pending.pods.gauge", nil))
+	assert.False(t, scheduler.Match("kubernetes.controlplane.process.cpu.seconds.total.counter", nil))
+}
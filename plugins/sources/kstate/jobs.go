@@ -0,0 +1,71 @@
+package kstate
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func (src *stateMetricsSource) jobPoints(now int64) []*metrics.MetricPoint {
+	jobs, err := src.listers.jobs.List(labels.Everything())
+	if err != nil {
+		log.Errorf("error listing jobs: %v", err)
+		return nil
+	}
+	var points []*metrics.MetricPoint
+	for _, j := range jobs {
+		tags := src.buildTags("job_name", j.Name, j.Namespace)
+		points = src.filterAppend(points, src.metricPoint("job.active", float64(j.Status.Active), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("job.succeeded", float64(j.Status.Succeeded), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("job.failed", float64(j.Status.Failed), now, "", tags))
+		if j.Spec.Completions != nil {
+			points = src.filterAppend(points, src.metricPoint("job.completions", float64(*j.Spec.Completions), now, "", tags))
+		}
+		// 1 once the job completed successfully, -1 once the job failed and 0 while it is running
+		points = src.filterAppend(points, src.metricPoint("job.status", jobStatus(j), now, "", tags))
+	}
+	return points
+}
+
+func jobStatus(job *batchv1.Job) float64 {
+	for _, c := range job.Status.Conditions {
+		if c.Status != v1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return 1
+		case batchv1.JobFailed:
+			return -1
+		}
+	}
+	return 0
+}
+
+func (src *stateMetricsSource) cronJobPoints(now int64) []*metrics.MetricPoint {
+	cronJobs, err := src.listers.cronJobs.List(labels.Everything())
+	if err != nil {
+		log.Errorf("error listing cronjobs: %v", err)
+		return nil
+	}
+	var points []*metrics.MetricPoint
+	for _, c := range cronJobs {
+		tags := src.buildTags("cronjob_name", c.Name, c.Namespace)
+		suspended := 0.0
+		if c.Spec.Suspend != nil && *c.Spec.Suspend {
+			suspended = 1.0
+		}
+		points = src.filterAppend(points, src.metricPoint("cronjob.active", float64(len(c.Status.Active)), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("cronjob.suspended", suspended, now, "", tags))
+		if c.Status.LastScheduleTime != nil {
+			age := float64(now-metrics.UnixMillis(c.Status.LastScheduleTime.Time)) / 1000
+			points = src.filterAppend(points, src.metricPoint("cronjob.last_schedule.age.seconds", age, now, "", tags))
+		}
+	}
+	return points
+}
@@ -0,0 +1,98 @@
+package kstate
+
+import (
+	"time"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+
+	gometrics "github.com/rcrowley/go-metrics"
+	"github.com/wavefronthq/go-metrics-wavefront/reporting"
+)
+
+type stateMetricsSource struct {
+	prefix  string
+	source  string
+	tags    map[string]string
+	filters filter.Filter
+	listers *listers
+
+	pps gometrics.Counter
+	fps gometrics.Counter
+}
+
+func newStateMetricsSource(prefix, source string, tags map[string]string, filters filter.Filter, listers *listers) metrics.MetricsSource {
+	ppsKey := reporting.EncodeKey("source.points.collected", map[string]string{"type": "kstate"})
+	fpsKey := reporting.EncodeKey("source.points.filtered", map[string]string{"type": "kstate"})
+
+	// the state is read from the listers caches, so there is no pending work to cancel
+	return metrics.NewMetricsSourceAdapter(&stateMetricsSource{
+		prefix:  prefix,
+		source:  source,
+		tags:    tags,
+		filters: filters,
+		listers: listers,
+		pps:     gometrics.GetOrRegisterCounter(ppsKey, gometrics.DefaultRegistry),
+		fps:     gometrics.GetOrRegisterCounter(fpsKey, gometrics.DefaultRegistry),
+	})
+}
+
+func (src *stateMetricsSource) Name() string {
+	return "kstate_source"
+}
+
+func (src *stateMetricsSource) ScrapeMetrics() (*metrics.DataBatch, error) {
+	result := &metrics.DataBatch{
+		Timestamp: time.Now(),
+	}
+	now := metrics.UnixMillis(result.Timestamp)
+
+	var points []*metrics.MetricPoint
+	points = append(points, src.deploymentPoints(now)...)
+	points = append(points, src.statefulSetPoints(now)...)
+	points = append(points, src.daemonSetPoints(now)...)
+	points = append(points, src.replicaSetPoints(now)...)
+	points = append(points, src.jobPoints(now)...)
+	points = append(points, src.cronJobPoints(now)...)
+	points = append(points, src.hpaPoints(now)...)
+	points = append(points, src.podPoints(now)...)
+	points = append(points, src.nodePoints(now)...)
+
+	src.pps.Inc(int64(len(points)))
+	result.MetricPoints = points
+	return result, nil
+}
+
+// buildTags returns the tags identifying a Kubernetes object merged with the configured source tags
+func (src *stateMetricsSource) buildTags(nameKey, name, namespace string) map[string]string {
+	tags := make(map[string]string, len(src.tags)+4)
+	for k, v := range src.tags {
+		tags[k] = v
+	}
+	tags[nameKey] = name
+	if namespace != "" {
+		tags[metrics.LabelNamespaceName.Key] = namespace
+	}
+	return tags
+}
+
+func (src *stateMetricsSource) metricPoint(name string, value float64, ts int64, source string, tags map[string]string) *metrics.MetricPoint {
+	if source == "" {
+		source = src.source
+	}
+	return &metrics.MetricPoint{
+		Metric:    src.prefix + name,
+		Value:     value,
+		Timestamp: ts,
+		Source:    source,
+		Tags:      tags,
+	}
+}
+
+func (src *stateMetricsSource) filterAppend(slice []*metrics.MetricPoint, point *metrics.MetricPoint) []*metrics.MetricPoint {
+	if src.filters == nil || src.filters.Match(point.Metric, point.Tags) {
+		return append(slice, point)
+	}
+	src.fps.Inc(1)
+	return slice
+}
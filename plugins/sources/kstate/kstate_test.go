@@ -0,0 +1,199 @@
+package kstate
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	autoscalingv1listers "k8s.io/client-go/listers/autoscaling/v1"
+	batchv1listers "k8s.io/client-go/listers/batch/v1"
+	batchv1beta1listers "k8s.io/client-go/listers/batch/v1beta1"
+	v1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newIndexer(objs ...interface{}) cache.Indexer {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objs {
+		indexer.Add(obj)
+	}
+	return indexer
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func testListers() *listers {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 2, ReadyReplicas: 2, UpdatedReplicas: 3, UnavailableReplicas: 1},
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default"},
+		Status: batchv1.JobStatus{
+			Failed: 2,
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: v1.ConditionTrue},
+			},
+		},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Spec:       v1.PodSpec{NodeName: "node-1"},
+		Status: v1.PodStatus{
+			Phase: v1.PodPending,
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					Name:         "app",
+					RestartCount: 4,
+					State:        v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				},
+			},
+		},
+	}
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionTrue},
+				{Type: v1.NodeMemoryPressure, Status: v1.ConditionUnknown},
+			},
+		},
+	}
+
+	return &listers{
+		deployments:  appsv1listers.NewDeploymentLister(newIndexer(deployment)),
+		statefulSets: appsv1listers.NewStatefulSetLister(newIndexer()),
+		daemonSets:   appsv1listers.NewDaemonSetLister(newIndexer()),
+		replicaSets:  appsv1listers.NewReplicaSetLister(newIndexer()),
+		jobs:         batchv1listers.NewJobLister(newIndexer(job)),
+		cronJobs:     batchv1beta1listers.NewCronJobLister(newIndexer(&batchv1beta1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"}})),
+		hpas:         autoscalingv1listers.NewHorizontalPodAutoscalerLister(newIndexer(&autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})),
+		pods:         v1listers.NewPodLister(newIndexer(pod)),
+		nodes:        v1listers.NewNodeLister(newIndexer(node)),
+	}
+}
+
+func findPoint(points []*metrics.MetricPoint, name string, tags map[string]string) *metrics.MetricPoint {
+	for _, point := range points {
+		if point.Metric != name {
+			continue
+		}
+		match := true
+		for k, v := range tags {
+			if point.Tags[k] != v {
+				match = false
+				break
+			}
+		}
+		if match {
+			return point
+		}
+	}
+	return nil
+}
+
+func TestScrapeMetrics(t *testing.T) {
+	src := newStateMetricsSource("kubernetes.", "collector", map[string]string{"env": "test"}, nil, testListers())
+	batch, err := src.ScrapeMetrics(context.Background())
+	require.NoError(t, err)
+	points := batch.MetricPoints
+
+	testCases := []struct {
+		name   string
+		tags   map[string]string
+		value  float64
+		source string
+	}{
+		{"kubernetes.deployment.desired_replicas", map[string]string{"deployment_name": "web", "namespace_name": "default", "env": "test"}, 3, "collector"},
+		{"kubernetes.deployment.available_replicas", map[string]string{"deployment_name": "web"}, 2, "collector"},
+		{"kubernetes.deployment.unavailable_replicas", map[string]string{"deployment_name": "web"}, 1, "collector"},
+		{"kubernetes.job.failed", map[string]string{"job_name": "migrate"}, 2, "collector"},
+		{"kubernetes.job.status", map[string]string{"job_name": "migrate"}, -1, "collector"},
+		{"kubernetes.cronjob.suspended", map[string]string{"cronjob_name": "nightly"}, 0, "collector"},
+		{"kubernetes.hpa.min_replicas", map[string]string{"hpa_name": "web"}, 1, "collector"},
+		{"kubernetes.pod.status.phase", map[string]string{"pod_name": "web-1", "phase": "Pending"}, 1, "node-1"},
+		{"kubernetes.pod_container.status.waiting", map[string]string{"container_name": "app", "reason": "CrashLoopBackOff"}, 1, "node-1"},
+		{"kubernetes.pod_container.status.restarts", map[string]string{"container_name": "app"}, 4, "node-1"},
+		{"kubernetes.pod_container.status.ready", map[string]string{"container_name": "app"}, 0, "node-1"},
+		{"kubernetes.node.status.condition", map[string]string{"nodename": "node-1", "condition": "Ready"}, 1, "node-1"},
+		{"kubernetes.node.status.condition", map[string]string{"nodename": "node-1", "condition": "MemoryPressure"}, -1, "node-1"},
+		{"kubernetes.node.spec.unschedulable", map[string]string{"nodename": "node-1"}, 0, "node-1"},
+	}
+	for _, tc := range testCases {
+		point := findPoint(points, tc.name, tc.tags)
+		require.NotNil(t, point, "missing point %s %v", tc.name, tc.tags)
+		assert.Equal(t, tc.value, point.Value, tc.name)
+		assert.Equal(t, tc.source, point.Source, tc.name)
+	}
+	assert.Nil(t, findPoint(points, "kubernetes.pod_container.status.terminated", nil))
+}
+
+func TestFilters(t *testing.T) {
+	filters := filter.NewGlobFilter(filter.Config{
+		MetricWhitelist: []string{"kubernetes.node.*"},
+	})
+	src := newStateMetricsSource("kubernetes.", "collector", nil, filters, testListers())
+	batch, err := src.ScrapeMetrics(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, batch.MetricPoints)
+	for _, point := range batch.MetricPoints {
+		assert.Contains(t, point.Metric, "kubernetes.node.")
+	}
+}
+
+func TestDaemonModeInformers(t *testing.T) {
+	os.Setenv(util.DaemonModeEnvVar, "true")
+	defer os.Unsetenv(util.DaemonModeEnvVar)
+
+	client := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}, Spec: v1.PodSpec{NodeName: "node-1"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "default"}, Spec: v1.PodSpec{NodeName: "node-2"}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+	)
+	si, err := newStateInformers(client)
+	require.NoError(t, err)
+
+	// nothing is collected until the informers are started on the leader
+	_, synced := si.listers()
+	assert.False(t, synced)
+
+	si.start()
+	defer si.stop()
+	var l *listers
+	for i := 0; i < 100 && !synced; i++ {
+		time.Sleep(10 * time.Millisecond)
+		l, synced = si.listers()
+	}
+	require.True(t, synced)
+
+	// the leader reports the pods and nodes of all nodes
+	src := newStateMetricsSource("kubernetes.", "collector", nil, nil, l)
+	batch, err := src.ScrapeMetrics(context.Background())
+	require.NoError(t, err)
+	assert.NotNil(t, findPoint(batch.MetricPoints, "kubernetes.pod.status.phase", map[string]string{"pod_name": "web-2"}))
+	assert.NotNil(t, findPoint(batch.MetricPoints, "kubernetes.node.spec.unschedulable", map[string]string{"nodename": "node-2"}))
+
+	si.stop()
+	_, synced = si.listers()
+	assert.False(t, synced)
+}
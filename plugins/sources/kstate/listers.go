@@ -0,0 +1,154 @@
+package kstate
+
+import (
+	"sync"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	autoscalingv1listers "k8s.io/client-go/listers/autoscaling/v1"
+	batchv1listers "k8s.io/client-go/listers/batch/v1"
+	batchv1beta1listers "k8s.io/client-go/listers/batch/v1beta1"
+	v1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// listers provides read access to the cached Kubernetes objects reported by the source.
+type listers struct {
+	deployments  appsv1listers.DeploymentLister
+	statefulSets appsv1listers.StatefulSetLister
+	daemonSets   appsv1listers.DaemonSetLister
+	replicaSets  appsv1listers.ReplicaSetLister
+	jobs         batchv1listers.JobLister
+	cronJobs     batchv1beta1listers.CronJobLister
+	hpas         autoscalingv1listers.HorizontalPodAutoscalerLister
+	pods         v1listers.PodLister
+	nodes        v1listers.NodeLister
+}
+
+// stateInformers manages the caches read by the source. The informers only run on the leader and are stopped
+// when it loses the leadership. The pod and node listers the collector already maintains are shared outside of
+// daemon mode. In daemon mode those are limited to the current node, so the leader watches all pods and nodes.
+type stateInformers struct {
+	kubeClient    kubernetes.Interface
+	pods          v1listers.PodLister
+	podReflector  *cache.Reflector
+	nodes         v1listers.NodeLister
+	nodeReflector *cache.Reflector
+
+	mtx    sync.Mutex
+	stopCh chan struct{}
+	synced []cache.InformerSynced
+	cached *listers
+}
+
+func newStateInformers(kubeClient kubernetes.Interface) (*stateInformers, error) {
+	if util.GetDaemonMode() != "" {
+		return &stateInformers{kubeClient: kubeClient}, nil
+	}
+	pods, podReflector, err := util.GetPodLister(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	nodes, nodeReflector, err := util.GetNodeLister(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	return &stateInformers{
+		kubeClient:    kubeClient,
+		pods:          pods,
+		podReflector:  podReflector,
+		nodes:         nodes,
+		nodeReflector: nodeReflector,
+	}, nil
+}
+
+// start starts the informers if they are not already running
+func (si *stateInformers) start() {
+	si.mtx.Lock()
+	defer si.mtx.Unlock()
+
+	if si.stopCh != nil {
+		return
+	}
+
+	factory := informers.NewSharedInformerFactory(si.kubeClient, 0)
+	deployments := factory.Apps().V1().Deployments()
+	statefulSets := factory.Apps().V1().StatefulSets()
+	daemonSets := factory.Apps().V1().DaemonSets()
+	replicaSets := factory.Apps().V1().ReplicaSets()
+	jobs := factory.Batch().V1().Jobs()
+	cronJobs := factory.Batch().V1beta1().CronJobs()
+	hpas := factory.Autoscaling().V1().HorizontalPodAutoscalers()
+
+	si.cached = &listers{
+		deployments:  deployments.Lister(),
+		statefulSets: statefulSets.Lister(),
+		daemonSets:   daemonSets.Lister(),
+		replicaSets:  replicaSets.Lister(),
+		jobs:         jobs.Lister(),
+		cronJobs:     cronJobs.Lister(),
+		hpas:         hpas.Lister(),
+	}
+	si.synced = []cache.InformerSynced{
+		deployments.Informer().HasSynced,
+		statefulSets.Informer().HasSynced,
+		daemonSets.Informer().HasSynced,
+		replicaSets.Informer().HasSynced,
+		jobs.Informer().HasSynced,
+		cronJobs.Informer().HasSynced,
+		hpas.Informer().HasSynced,
+	}
+	if si.pods != nil {
+		si.cached.pods = si.pods
+		si.cached.nodes = si.nodes
+		si.synced = append(si.synced, reflectorSynced(si.podReflector), reflectorSynced(si.nodeReflector))
+	} else {
+		pods := factory.Core().V1().Pods()
+		nodes := factory.Core().V1().Nodes()
+		si.cached.pods = pods.Lister()
+		si.cached.nodes = nodes.Lister()
+		si.synced = append(si.synced, pods.Informer().HasSynced, nodes.Informer().HasSynced)
+	}
+	si.stopCh = make(chan struct{})
+	factory.Start(si.stopCh)
+}
+
+// stop stops the informers and drops their caches
+func (si *stateInformers) stop() {
+	si.mtx.Lock()
+	defer si.mtx.Unlock()
+
+	if si.stopCh == nil {
+		return
+	}
+	close(si.stopCh)
+	si.stopCh = nil
+	si.synced = nil
+	si.cached = nil
+}
+
+// listers returns the listers once their caches are synced, or false while the informers are
+// stopped or still syncing.
+func (si *stateInformers) listers() (*listers, bool) {
+	si.mtx.Lock()
+	defer si.mtx.Unlock()
+
+	if si.cached == nil {
+		return nil, false
+	}
+	for _, synced := range si.synced {
+		if !synced() {
+			return nil, false
+		}
+	}
+	return si.cached, true
+}
+
+func reflectorSynced(r *cache.Reflector) cache.InformerSynced {
+	return func() bool {
+		return r.LastSyncResourceVersion() != ""
+	}
+}
@@ -0,0 +1,47 @@
+package kstate
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+
+	v1 "k8s.io/api/core/v1"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func (src *stateMetricsSource) nodePoints(now int64) []*metrics.MetricPoint {
+	nodes, err := src.listers.nodes.List(labels.Everything())
+	if err != nil {
+		log.Errorf("error listing nodes: %v", err)
+		return nil
+	}
+	var points []*metrics.MetricPoint
+	for _, node := range nodes {
+		unschedulable := 0.0
+		if node.Spec.Unschedulable {
+			unschedulable = 1.0
+		}
+		tags := src.buildTags(metrics.LabelNodename.Key, node.Name, "")
+		points = src.filterAppend(points, src.metricPoint("node.spec.unschedulable", unschedulable, now, node.Name, tags))
+
+		for _, condition := range node.Status.Conditions {
+			conditionTags := copyTags(tags)
+			conditionTags["condition"] = string(condition.Type)
+			points = src.filterAppend(points, src.metricPoint("node.status.condition", conditionValue(condition.Status), now, node.Name, conditionTags))
+		}
+	}
+	return points
+}
+
+// conditionValue returns 1 for true, 0 for false and -1 for unknown conditions
+func conditionValue(status v1.ConditionStatus) float64 {
+	switch status {
+	case v1.ConditionTrue:
+		return 1
+	case v1.ConditionFalse:
+		return 0
+	default:
+		return -1
+	}
+}
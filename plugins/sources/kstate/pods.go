@@ -0,0 +1,72 @@
+package kstate
+
+import (
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+
+	v1 "k8s.io/api/core/v1"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func (src *stateMetricsSource) podPoints(now int64) []*metrics.MetricPoint {
+	pods, err := src.listers.pods.List(labels.Everything())
+	if err != nil {
+		log.Errorf("error listing pods: %v", err)
+		return nil
+	}
+	var points []*metrics.MetricPoint
+	for _, pod := range pods {
+		tags := src.buildTags(metrics.LabelPodName.Key, pod.Name, pod.Namespace)
+		tags["phase"] = string(pod.Status.Phase)
+		if pod.Spec.NodeName != "" {
+			tags[metrics.LabelNodename.Key] = pod.Spec.NodeName
+		}
+		points = src.filterAppend(points, src.metricPoint("pod.status.phase", 1, now, pod.Spec.NodeName, tags))
+
+		for _, status := range pod.Status.ContainerStatuses {
+			points = append(points, src.containerPoints(pod, status, now)...)
+		}
+	}
+	return points
+}
+
+func (src *stateMetricsSource) containerPoints(pod *v1.Pod, status v1.ContainerStatus, now int64) []*metrics.MetricPoint {
+	tags := src.buildTags(metrics.LabelPodName.Key, pod.Name, pod.Namespace)
+	tags[metrics.LabelContainerName.Key] = status.Name
+	if pod.Spec.NodeName != "" {
+		tags[metrics.LabelNodename.Key] = pod.Spec.NodeName
+	}
+
+	ready := 0.0
+	if status.Ready {
+		ready = 1.0
+	}
+	var points []*metrics.MetricPoint
+	points = src.filterAppend(points, src.metricPoint("pod_container.status.ready", ready, now, pod.Spec.NodeName, tags))
+	points = src.filterAppend(points, src.metricPoint("pod_container.status.restarts", float64(status.RestartCount), now, pod.Spec.NodeName, tags))
+
+	if waiting := status.State.Waiting; waiting != nil {
+		reasonTags := copyTags(tags)
+		reasonTags["reason"] = waiting.Reason
+		points = src.filterAppend(points, src.metricPoint("pod_container.status.waiting", 1, now, pod.Spec.NodeName, reasonTags))
+	}
+	if terminated := status.State.Terminated; terminated != nil {
+		reasonTags := copyTags(tags)
+		reasonTags["reason"] = terminated.Reason
+		reasonTags["exit_code"] = strconv.Itoa(int(terminated.ExitCode))
+		points = src.filterAppend(points, src.metricPoint("pod_container.status.terminated", 1, now, pod.Spec.NodeName, reasonTags))
+	}
+	return points
+}
+
+func copyTags(tags map[string]string) map[string]string {
+	result := make(map[string]string, len(tags)+2)
+	for k, v := range tags {
+		result[k] = v
+	}
+	return result
+}
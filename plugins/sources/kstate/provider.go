@@ -0,0 +1,69 @@
+// Package kstate provides metrics on the state of Kubernetes objects such as workloads, pods and nodes
+package kstate
+
+import (
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
+	kube_config "github.com/wavefronthq/wavefront-kubernetes-collector/internal/kubernetes"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/leadership"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
+
+	log "github.com/sirupsen/logrus"
+
+	kube_client "k8s.io/client-go/kubernetes"
+)
+
+type stateProvider struct {
+	metrics.DefaultMetricsSourceProvider
+	informers *stateInformers
+	prefix    string
+	source    string
+	tags      map[string]string
+	filters   filter.Filter
+}
+
+func (p *stateProvider) GetMetricsSources() []metrics.MetricsSource {
+	if !leadership.Leading() {
+		log.Debugf("not collecting kubernetes state metrics. current leader: %s", leadership.Leader())
+		p.informers.stop()
+		return nil
+	}
+	p.informers.start()
+
+	l, synced := p.informers.listers()
+	if !synced {
+		log.Info("waiting for the kubernetes state caches to sync")
+		return nil
+	}
+	return []metrics.MetricsSource{newStateMetricsSource(p.prefix, p.source, p.tags, p.filters, l)}
+}
+
+func (p *stateProvider) Name() string {
+	return "kstate_provider"
+}
+
+// Stop stops the informers once the provider is deleted
+func (p *stateProvider) Stop() {
+	p.informers.stop()
+}
+
+func NewStateProvider(cfg configuration.KubernetesStateSourceConfig, summaryCfg configuration.SummaySourceConfig) (metrics.MetricsSourceProvider, error) {
+	kubeConfig, err := kube_config.GetKubeClientConfig(summaryCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	informers, err := newStateInformers(kube_client.NewForConfigOrDie(kubeConfig))
+	if err != nil {
+		return nil, err
+	}
+
+	return &stateProvider{
+		informers: informers,
+		prefix:    configuration.GetStringValue(cfg.Prefix, "kubernetes."),
+		source:    configuration.GetStringValue(cfg.Source, configuration.GetStringValue(util.GetNodeName(), "wavefront-kubernetes-collector")),
+		tags:      cfg.Tags,
+		filters:   filter.FromConfig(cfg.Filters),
+	}, nil
+}
@@ -0,0 +1,111 @@
+package kstate
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func (src *stateMetricsSource) deploymentPoints(now int64) []*metrics.MetricPoint {
+	deployments, err := src.listers.deployments.List(labels.Everything())
+	if err != nil {
+		log.Errorf("error listing deployments: %v", err)
+		return nil
+	}
+	var points []*metrics.MetricPoint
+	for _, d := range deployments {
+		tags := src.buildTags("deployment_name", d.Name, d.Namespace)
+		desired := int32(1)
+		if d.Spec.Replicas != nil {
+			desired = *d.Spec.Replicas
+		}
+		points = src.filterAppend(points, src.metricPoint("deployment.desired_replicas", float64(desired), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("deployment.available_replicas", float64(d.Status.AvailableReplicas), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("deployment.ready_replicas", float64(d.Status.ReadyReplicas), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("deployment.updated_replicas", float64(d.Status.UpdatedReplicas), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("deployment.unavailable_replicas", float64(d.Status.UnavailableReplicas), now, "", tags))
+	}
+	return points
+}
+
+func (src *stateMetricsSource) statefulSetPoints(now int64) []*metrics.MetricPoint {
+	statefulSets, err := src.listers.statefulSets.List(labels.Everything())
+	if err != nil {
+		log.Errorf("error listing statefulsets: %v", err)
+		return nil
+	}
+	var points []*metrics.MetricPoint
+	for _, s := range statefulSets {
+		tags := src.buildTags("statefulset_name", s.Name, s.Namespace)
+		desired := int32(1)
+		if s.Spec.Replicas != nil {
+			desired = *s.Spec.Replicas
+		}
+		points = src.filterAppend(points, src.metricPoint("statefulset.desired_replicas", float64(desired), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("statefulset.current_replicas", float64(s.Status.CurrentReplicas), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("statefulset.ready_replicas", float64(s.Status.ReadyReplicas), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("statefulset.updated_replicas", float64(s.Status.UpdatedReplicas), now, "", tags))
+	}
+	return points
+}
+
+func (src *stateMetricsSource) daemonSetPoints(now int64) []*metrics.MetricPoint {
+	daemonSets, err := src.listers.daemonSets.List(labels.Everything())
+	if err != nil {
+		log.Errorf("error listing daemonsets: %v", err)
+		return nil
+	}
+	var points []*metrics.MetricPoint
+	for _, d := range daemonSets {
+		tags := src.buildTags("daemonset_name", d.Name, d.Namespace)
+		points = src.filterAppend(points, src.metricPoint("daemonset.desired_scheduled", float64(d.Status.DesiredNumberScheduled), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("daemonset.current_scheduled", float64(d.Status.CurrentNumberScheduled), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("daemonset.misscheduled", float64(d.Status.NumberMisscheduled), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("daemonset.ready", float64(d.Status.NumberReady), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("daemonset.available", float64(d.Status.NumberAvailable), now, "", tags))
+	}
+	return points
+}
+
+func (src *stateMetricsSource) replicaSetPoints(now int64) []*metrics.MetricPoint {
+	replicaSets, err := src.listers.replicaSets.List(labels.Everything())
+	if err != nil {
+		log.Errorf("error listing replicasets: %v", err)
+		return nil
+	}
+	var points []*metrics.MetricPoint
+	for _, r := range replicaSets {
+		tags := src.buildTags("replicaset_name", r.Name, r.Namespace)
+		desired := int32(1)
+		if r.Spec.Replicas != nil {
+			desired = *r.Spec.Replicas
+		}
+		points = src.filterAppend(points, src.metricPoint("replicaset.desired_replicas", float64(desired), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("replicaset.available_replicas", float64(r.Status.AvailableReplicas), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("replicaset.ready_replicas", float64(r.Status.ReadyReplicas), now, "", tags))
+	}
+	return points
+}
+
+func (src *stateMetricsSource) hpaPoints(now int64) []*metrics.MetricPoint {
+	hpas, err := src.listers.hpas.List(labels.Everything())
+	if err != nil {
+		log.Errorf("error listing horizontal pod autoscalers: %v", err)
+		return nil
+	}
+	var points []*metrics.MetricPoint
+	for _, h := range hpas {
+		tags := src.buildTags("hpa_name", h.Name, h.Namespace)
+		min := int32(1)
+		if h.Spec.MinReplicas != nil {
+			min = *h.Spec.MinReplicas
+		}
+		points = src.filterAppend(points, src.metricPoint("hpa.desired_replicas", float64(h.Status.DesiredReplicas), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("hpa.current_replicas", float64(h.Status.CurrentReplicas), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("hpa.min_replicas", float64(min), now, "", tags))
+		points = src.filterAppend(points, src.metricPoint("hpa.max_replicas", float64(h.Spec.MaxReplicas), now, "", tags))
+	}
+	return points
+}
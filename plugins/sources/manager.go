@@ -17,6 +17,7 @@
 package sources
 
 import (
+	"context"
 	"fmt"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
 	"math/rand"
@@ -25,6 +26,9 @@ import (
 	"time"
 
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources/controlplane"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources/kstate"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources/prometheus"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources/stats"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources/summary"
@@ -38,6 +42,9 @@ import (
 
 const (
 	jitterMs = 4
+
+	defaultScrapeTimeout     = time.Minute
+	defaultScrapeConcurrency = 10
 )
 
 var (
@@ -64,17 +71,21 @@ type SourceManager interface {
 	StopProviders()
 	GetPendingMetrics() []*metrics.DataBatch
 	SetDefaultCollectionInterval(time.Duration)
+	SetAlignCollectionIntervals(bool)
 	BuildProviders(config configuration.SourceConfig) error
 }
 
 type sourceManagerImpl struct {
 	responseChannel           chan *metrics.DataBatch
 	defaultCollectionInterval time.Duration
+	alignCollectionIntervals  bool
 
 	metricsSourcesMtx      sync.Mutex
 	metricsSourceProviders map[string]metrics.MetricsSourceProvider
-	metricsSourceTickers   map[string]*time.Ticker
+	metricsSourceTickers   map[string]*util.Ticker
 	metricsSourceQuits     map[string]chan struct{}
+	metricsSourceStopped   map[string]chan struct{}
+	metricsSourceTargets   map[string]*targets
 
 	responseMtx sync.Mutex
 	response    []*metrics.DataBatch
@@ -86,8 +97,10 @@ func Manager() SourceManager {
 		singleton = &sourceManagerImpl{
 			responseChannel:           make(chan *metrics.DataBatch),
 			metricsSourceProviders:    make(map[string]metrics.MetricsSourceProvider),
-			metricsSourceTickers:      make(map[string]*time.Ticker),
+			metricsSourceTickers:      make(map[string]*util.Ticker),
 			metricsSourceQuits:        make(map[string]chan struct{}),
+			metricsSourceStopped:      make(map[string]chan struct{}),
+			metricsSourceTargets:      make(map[string]*targets),
 			defaultCollectionInterval: time.Minute,
 		}
 		singleton.rotateResponse()
@@ -112,6 +125,11 @@ func (sm *sourceManagerImpl) SetDefaultCollectionInterval(defaultCollectionInter
 	sm.defaultCollectionInterval = defaultCollectionInterval
 }
 
+// SetAlignCollectionIntervals sets whether the providers added afterwards are scraped at wall-clock multiples of their interval
+func (sm *sourceManagerImpl) SetAlignCollectionIntervals(align bool) {
+	sm.alignCollectionIntervals = align
+}
+
 // AddProvider register and start a new MetricsSourceProvider
 func (sm *sourceManagerImpl) AddProvider(provider metrics.MetricsSourceProvider) {
 	name := provider.Name()
@@ -122,19 +140,23 @@ func (sm *sourceManagerImpl) AddProvider(provider metrics.MetricsSourceProvider)
 		"timeout":             provider.Timeout(),
 	}).Info("Adding provider")
 
-	if _, found := sm.metricsSourceProviders[name]; found {
+	sm.metricsSourcesMtx.Lock()
+	_, found := sm.metricsSourceProviders[name]
+	sm.metricsSourcesMtx.Unlock()
+	if found {
+		// the targets of the existing provider are kept, so only the ones missing from the new provider are marked stale
 		log.WithField("name", name).Info("deleting existing provider")
-		sm.DeleteProvider(name)
+		sm.deleteProvider(name)
 	}
 
 	sm.metricsSourcesMtx.Lock()
 	defer sm.metricsSourcesMtx.Unlock()
 
-	var ticker *time.Ticker
+	var ticker *util.Ticker
 	if provider.CollectionInterval() > 0 {
-		ticker = time.NewTicker(provider.CollectionInterval())
+		ticker = util.NewTicker(provider.CollectionInterval(), sm.alignCollectionIntervals)
 	} else {
-		ticker = time.NewTicker(sm.defaultCollectionInterval)
+		ticker = util.NewTicker(sm.defaultCollectionInterval, sm.alignCollectionIntervals)
 
 		log.WithFields(log.Fields{
 			"provider":            name,
@@ -143,18 +165,39 @@ func (sm *sourceManagerImpl) AddProvider(provider metrics.MetricsSourceProvider)
 	}
 
 	quit := make(chan struct{})
+	stopped := make(chan struct{})
 
 	sm.metricsSourceProviders[name] = provider
 	sm.metricsSourceTickers[name] = ticker
 	sm.metricsSourceQuits[name] = quit
+	sm.metricsSourceStopped[name] = stopped
+
+	providerTargets, found := sm.metricsSourceTargets[name]
+	if !found {
+		providerTargets = newTargets(name)
+		sm.metricsSourceTargets[name] = providerTargets
+	}
 
 	providerCount.Update(int64(len(sm.metricsSourceProviders)))
 
 	go func() {
+		// cancels the pending scrapes once the provider is deleted and waits for them to return
+		ctx, cancel := context.WithCancel(context.Background())
+		var scrapes sync.WaitGroup
+		defer func() {
+			cancel()
+			scrapes.Wait()
+			close(stopped)
+		}()
+
 		for {
 			select {
 			case <-ticker.C:
-				go scrape(provider, sm.responseChannel)
+				scrapes.Add(1)
+				go func() {
+					defer scrapes.Done()
+					scrape(ctx, provider, providerTargets, sm.responseChannel)
+				}()
 			case <-quit:
 				return
 			}
@@ -162,15 +205,32 @@ func (sm *sourceManagerImpl) AddProvider(provider metrics.MetricsSourceProvider)
 	}()
 }
 
+// DeleteProvider stops and removes a provider, marking its targets as stale
 func (sm *sourceManagerImpl) DeleteProvider(name string) {
-	if _, found := sm.metricsSourceProviders[name]; !found {
-		log.Debugf("Metrics Source Provider '%s' not found", name)
-		return
-	}
+	sm.deleteProvider(name)
 
 	sm.metricsSourcesMtx.Lock()
-	defer sm.metricsSourcesMtx.Unlock()
+	providerTargets, found := sm.metricsSourceTargets[name]
+	delete(sm.metricsSourceTargets, name)
+	sm.metricsSourcesMtx.Unlock()
 
+	if found {
+		if stale := providerTargets.clear(); len(stale) > 0 {
+			sm.responseChannel <- providerTargets.downBatch(nil, stale)
+		}
+	}
+}
+
+// deleteProvider removes the provider and waits for its pending scrapes to return before stopping it.
+// The lock is released while waiting, so other providers can be added or deleted in the meantime.
+func (sm *sourceManagerImpl) deleteProvider(name string) {
+	sm.metricsSourcesMtx.Lock()
+	provider, found := sm.metricsSourceProviders[name]
+	if !found {
+		sm.metricsSourcesMtx.Unlock()
+		log.Debugf("Metrics Source Provider '%s' not found", name)
+		return
+	}
 	delete(sm.metricsSourceProviders, name)
 	if ticker, ok := sm.metricsSourceTickers[name]; ok {
 		ticker.Stop()
@@ -180,13 +240,34 @@ func (sm *sourceManagerImpl) DeleteProvider(name string) {
 		close(quit)
 		delete(sm.metricsSourceQuits, name)
 	}
+	stopped, ok := sm.metricsSourceStopped[name]
+	delete(sm.metricsSourceStopped, name)
+	sm.metricsSourcesMtx.Unlock()
+
+	if ok {
+		<-stopped
+	}
+	if stoppable, ok := provider.(metrics.StoppableMetricsSourceProvider); ok {
+		stoppable.Stop()
+	}
 	log.WithField("name", name).Info("Deleted provider")
 }
 
+// StopProviders stops all the providers on shutdown. Their targets are not marked as stale.
 func (sm *sourceManagerImpl) StopProviders() {
-	for provider := range sm.metricsSourceProviders {
-		sm.DeleteProvider(provider)
+	sm.metricsSourcesMtx.Lock()
+	names := make([]string, 0, len(sm.metricsSourceProviders))
+	for name := range sm.metricsSourceProviders {
+		names = append(names, name)
+	}
+	sm.metricsSourcesMtx.Unlock()
+
+	for _, name := range names {
+		sm.deleteProvider(name)
 	}
+	sm.metricsSourcesMtx.Lock()
+	sm.metricsSourceTargets = make(map[string]*targets)
+	sm.metricsSourcesMtx.Unlock()
 }
 
 func (sm *sourceManagerImpl) run() {
@@ -208,44 +289,107 @@ func (sm *sourceManagerImpl) rotateResponse() []*metrics.DataBatch {
 	return response
 }
 
-func scrape(provider metrics.MetricsSourceProvider, channel chan *metrics.DataBatch) {
-	for _, source := range provider.GetMetricsSources() {
-		// Prevents network congestion.
-		jitter := time.Duration(rand.Intn(jitterMs)) * time.Millisecond
-		time.Sleep(jitter)
+// scrape queries the sources of the provider using a bounded pool of workers, so a slow
+// or failing source does not delay or prevent the scraping of the remaining sources.
+func scrape(ctx context.Context, provider metrics.MetricsSourceProvider, providerTargets *targets, channel chan *metrics.DataBatch) {
+	timeout := provider.Timeout()
+	if timeout <= 0 {
+		timeout = defaultScrapeTimeout
+	}
+	concurrency := defaultScrapeConcurrency
+	if i, ok := provider.(metrics.ConcurrentMetricsSourceProvider); ok && i.Concurrency() > 0 {
+		concurrency = i.Concurrency()
+	}
+
+	sources := provider.GetMetricsSources()
+	stale := providerTargets.update(sources)
+	if len(sources) < concurrency {
+		concurrency = len(sources)
+	}
 
-		scrapeStart := time.Now()
-		timeout := provider.Timeout()
-		if timeout <= 0 {
-			timeout = time.Minute
+	// indexes of the sources to scrape, each worker records whether its sources are up
+	queue := make(chan int)
+	up := make([]bool, len(sources))
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				up[i] = scrapeSource(ctx, providerTargets, sources[i], timeout, channel)
+			}
+		}()
+	}
+	for i := range sources {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	var down []string
+	if ctx.Err() == nil {
+		// the targets of a deleted provider are not reported as down
+		for i, source := range sources {
+			if !up[i] {
+				down = append(down, source.Name())
+			}
 		}
+	}
+	if len(down) > 0 || len(stale) > 0 {
+		channel <- providerTargets.downBatch(down, stale)
+	}
+}
 
-		log.WithField("name", source.Name()).Info("Querying source")
+// scrapeSource queries a single source, cancelling the scrape once the timeout expires.
+// Returns whether the source was scraped successfully, in which case the batch includes its target.up point.
+func scrapeSource(ctx context.Context, providerTargets *targets, source metrics.MetricsSource, timeout time.Duration, channel chan *metrics.DataBatch) bool {
+	// Prevents network congestion.
+	jitter := time.Duration(rand.Intn(jitterMs)) * time.Millisecond
+	time.Sleep(jitter)
 
-		dataBatch, err := source.ScrapeMetrics()
-		if err != nil {
-			scrapeErrors.Inc(1)
-			log.Errorf("Error in scraping containers from '%s': %v", source.Name(), err)
-			return
-		}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	log.WithField("name", source.Name()).Info("Querying source")
 
-		now := time.Now()
-		latency := now.Sub(scrapeStart)
-		scrapeLatency.Update(latency.Nanoseconds())
+	tags := targetTags(providerTargets.provider, source.Name())
+	scrapeStart := time.Now()
+	dataBatch, err := source.ScrapeMetrics(ctx)
+	latency := time.Since(scrapeStart)
 
-		if !now.Before(scrapeStart.Add(timeout)) {
+	// a scrape that completed before it was cancelled is still reported
+	if err != nil {
+		switch ctx.Err() {
+		case context.DeadlineExceeded:
 			scrapeTimeouts.Inc(1)
+			gometrics.GetOrRegisterCounter(reporting.EncodeKey("target.scrape.timeouts", tags), gometrics.DefaultRegistry).Inc(1)
 			log.Warningf("Failed to get '%s' response in time (%s latency)", source.Name(), latency)
-			return
+			return false
+		case context.Canceled:
+			log.WithField("name", source.Name()).Debug("Cancelled querying source")
+			return false
 		}
-		channel <- dataBatch
+	}
 
-		log.WithFields(log.Fields{
-			"name":          source.Name(),
-			"total_metrics": len(dataBatch.MetricPoints) + len(dataBatch.MetricSets),
-			"latency":       latency,
-		}).Debug("Finished querying source")
+	scrapeLatency.Update(latency.Nanoseconds())
+	targetLatency := gometrics.GetOrRegister(reporting.EncodeKey("target.scrape.latency", tags), reporting.NewHistogram)
+	targetLatency.(gometrics.Histogram).Update(latency.Nanoseconds())
+
+	if err != nil {
+		scrapeErrors.Inc(1)
+		gometrics.GetOrRegisterCounter(reporting.EncodeKey("target.scrape.errors", tags), gometrics.DefaultRegistry).Inc(1)
+		log.Errorf("Error in scraping containers from '%s': %v", source.Name(), err)
+		return false
 	}
+	dataBatch.MetricPoints = append(dataBatch.MetricPoints, providerTargets.upPoint(source.Name(), 1))
+	channel <- dataBatch
+
+	log.WithFields(log.Fields{
+		"name":          source.Name(),
+		"total_metrics": len(dataBatch.MetricPoints) + len(dataBatch.MetricSets),
+		"latency":       latency,
+	}).Debug("Finished querying source")
+	return true
 }
 
 func (sm *sourceManagerImpl) GetPendingMetrics() []*metrics.DataBatch {
@@ -269,6 +413,14 @@ func buildProviders(cfg configuration.SourceConfig) []metrics.MetricsSourceProvi
 		provider, err := stats.NewInternalStatsProvider(*cfg.StatsConfig)
 		result = appendProvider(result, provider, err, cfg.StatsConfig.Collection)
 	}
+	if cfg.StateConfig != nil && cfg.SummaryConfig != nil {
+		provider, err := kstate.NewStateProvider(*cfg.StateConfig, *cfg.SummaryConfig)
+		result = appendProvider(result, provider, err, cfg.StateConfig.Collection)
+	}
+	if cfg.ControlPlane != nil && cfg.SummaryConfig != nil {
+		provider, err := controlplane.NewControlPlaneProvider(*cfg.ControlPlane, *cfg.SummaryConfig)
+		result = appendProvider(result, provider, err, cfg.ControlPlane.Collection)
+	}
 	for _, srcCfg := range cfg.TelegrafConfigs {
 		provider, err := telegraf.NewProvider(*srcCfg)
 		result = appendProvider(result, provider, err, srcCfg.Collection)
@@ -296,6 +448,9 @@ func appendProvider(slice []metrics.MetricsSourceProvider, provider metrics.Metr
 		if i, ok := provider.(metrics.ConfigurabeMetricsSourceProvider); ok {
 			i.Configure(cfg.Interval, cfg.Timeout)
 		}
+		if i, ok := provider.(metrics.ConcurrentMetricsSourceProvider); ok {
+			i.SetConcurrency(cfg.Concurrency)
+		}
 	}
 	return slice
 }
@@ -15,12 +15,15 @@
 package sources
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
 )
@@ -76,6 +79,199 @@ func TestTimeout(t *testing.T) {
 
 }
 
+func TestSourceErrorIsolation(t *testing.T) {
+	provider := util.NewDummyMetricsSourceProvider(
+		"dummy_err", time.Hour, 50*time.Millisecond,
+		&errorMetricsSource{name: "err_1"},
+		util.NewDummyMetricsSource("ok_1", 0),
+		util.NewDummyMetricsSource("ok_2", 0))
+
+	present := scrapeAll(provider)
+
+	assert.True(t, present["ok_1"], "ok_1 not found - present:%v", present)
+	assert.True(t, present["ok_2"], "ok_2 not found - present:%v", present)
+}
+
+func TestParallelScrape(t *testing.T) {
+	provider := util.NewDummyMetricsSourceProvider(
+		"dummy_par", time.Hour, 200*time.Millisecond,
+		util.NewDummyMetricsSource("par_1", 50*time.Millisecond),
+		util.NewDummyMetricsSource("par_2", 50*time.Millisecond),
+		util.NewDummyMetricsSource("par_3", 50*time.Millisecond),
+		util.NewDummyMetricsSource("par_4", 50*time.Millisecond))
+
+	start := time.Now()
+	present := scrapeAll(provider)
+
+	for _, name := range []string{"par_1", "par_2", "par_3", "par_4"} {
+		assert.True(t, present[name], "%s not found - present:%v", name, present)
+	}
+	assert.True(t, time.Since(start) < 150*time.Millisecond, "sources not scraped in parallel")
+}
+
+func TestDeleteProviderCancelsScrape(t *testing.T) {
+	metricsSourceProvider := util.NewDummyMetricsSourceProvider(
+		"dummy_cancel", 10*time.Millisecond, time.Minute,
+		util.NewDummyMetricsSource("cancel_1", time.Minute))
+
+	Manager().AddProvider(metricsSourceProvider)
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	Manager().DeleteProvider("dummy_cancel")
+	assert.True(t, time.Since(start) < time.Second, "pending scrape not cancelled")
+	Manager().GetPendingMetrics()
+}
+
+type stoppableProvider struct {
+	metrics.MetricsSourceProvider
+	stopped bool
+}
+
+func (p *stoppableProvider) Stop() {
+	p.stopped = true
+}
+
+func TestDeleteProviderStopsProvider(t *testing.T) {
+	provider := &stoppableProvider{
+		MetricsSourceProvider: util.NewDummyMetricsSourceProvider("dummy_stop", 10*time.Millisecond, time.Minute),
+	}
+
+	Manager().AddProvider(provider)
+	Manager().DeleteProvider("dummy_stop")
+	assert.True(t, provider.stopped)
+	Manager().GetPendingMetrics()
+}
+
+type blockingProvider struct {
+	metrics.MetricsSourceProvider
+	scraping chan struct{}
+	release  chan struct{}
+}
+
+func (p *blockingProvider) GetMetricsSources() []metrics.MetricsSource {
+	select {
+	case p.scraping <- struct{}{}:
+	default:
+	}
+	<-p.release
+	return nil
+}
+
+func TestDeleteProviderReleasesLock(t *testing.T) {
+	provider := &blockingProvider{
+		MetricsSourceProvider: util.NewDummyMetricsSourceProvider("dummy_blocking", 10*time.Millisecond, time.Minute),
+		scraping:              make(chan struct{}),
+		release:               make(chan struct{}),
+	}
+	Manager().AddProvider(provider)
+	<-provider.scraping
+
+	deleted := make(chan struct{})
+	go func() {
+		Manager().DeleteProvider("dummy_blocking")
+		close(deleted)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// providers can be added while the delete waits for the pending scrape
+	start := time.Now()
+	Manager().AddProvider(util.NewDummyMetricsSourceProvider("dummy_added", time.Minute, time.Minute))
+	assert.True(t, time.Since(start) < time.Second, "add blocked by the pending delete")
+	select {
+	case <-deleted:
+		t.Fatal("delete returned before the pending scrape")
+	default:
+	}
+
+	close(provider.release)
+	<-deleted
+	Manager().DeleteProvider("dummy_added")
+	Manager().GetPendingMetrics()
+}
+
+func TestTargetUp(t *testing.T) {
+	provider := util.NewDummyMetricsSourceProvider(
+		"dummy_up", time.Hour, 50*time.Millisecond,
+		&errorMetricsSource{name: "down_1"},
+		util.NewDummyMetricsSource("up_1", 0))
+
+	up := make(map[string]float64)
+	channel := make(chan *metrics.DataBatch)
+	done := make(chan struct{})
+	go func() {
+		for dataBatch := range channel {
+			for _, point := range dataBatch.MetricPoints {
+				if point.Metric == targetUpMetric {
+					assert.Equal(t, "dummy_up", point.Tags["provider"])
+					up[point.Tags["target"]] = point.Value
+				}
+			}
+		}
+		close(done)
+	}()
+	scrape(context.Background(), provider, newTargets(provider.Name()), channel)
+	close(channel)
+	<-done
+
+	assert.Equal(t, map[string]float64{"down_1": 0, "up_1": 1}, up)
+}
+
+func TestDeleteProviderMarksTargetsStale(t *testing.T) {
+	metricsSourceProvider := util.NewDummyMetricsSourceProvider(
+		"dummy_stale", 10*time.Millisecond, 50*time.Millisecond,
+		util.NewDummyMetricsSource("stale_1", 0))
+
+	Manager().AddProvider(metricsSourceProvider)
+	time.Sleep(50 * time.Millisecond)
+	Manager().DeleteProvider("dummy_stale")
+	time.Sleep(10 * time.Millisecond)
+
+	dataBatchList := Manager().GetPendingMetrics()
+	require.NotEmpty(t, dataBatchList)
+	var last *metrics.MetricPoint
+	for _, dataBatch := range dataBatchList {
+		for _, point := range dataBatch.MetricPoints {
+			if point.Metric == targetUpMetric && point.Tags["target"] == "stale_1" {
+				last = point
+			}
+		}
+	}
+	require.NotNil(t, last, "no %s point for stale_1", targetUpMetric)
+	assert.Equal(t, 0.0, last.Value)
+}
+
+// scrapeAll scrapes the provider once and returns the names of the collected metrics.
+func scrapeAll(provider metrics.MetricsSourceProvider) map[string]bool {
+	channel := make(chan *metrics.DataBatch)
+	done := make(chan struct{})
+	present := make(map[string]bool)
+	go func() {
+		for dataBatch := range channel {
+			for _, point := range dataBatch.MetricPoints {
+				present[point.Metric] = true
+			}
+		}
+		close(done)
+	}()
+	scrape(context.Background(), provider, newTargets(provider.Name()), channel)
+	close(channel)
+	<-done
+	return present
+}
+
+type errorMetricsSource struct {
+	name string
+}
+
+func (src *errorMetricsSource) Name() string {
+	return src.name
+}
+
+func (src *errorMetricsSource) ScrapeMetrics(context.Context) (*metrics.DataBatch, error) {
+	return nil, errors.New("scrape failed")
+}
+
 func TestMultipleMetrics(t *testing.T) {
 	msp1 := util.NewDummyMetricsSourceProvider(
 		"p1", 10*time.Millisecond, 10*time.Millisecond,
@@ -0,0 +1,110 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+// parseGranularities converts the configured granularity names. Defaults to minute.
+func parseGranularities(names []string) ([]metrics.Granularity, error) {
+	if len(names) == 0 {
+		return []metrics.Granularity{metrics.MinuteGranularity}, nil
+	}
+	var result []metrics.Granularity
+	for _, name := range names {
+		switch name {
+		case "minute":
+			result = append(result, metrics.MinuteGranularity)
+		case "hour":
+			result = append(result, metrics.HourGranularity)
+		case "day":
+			result = append(result, metrics.DayGranularity)
+		default:
+			return nil, fmt.Errorf("invalid distribution granularity: %s", name)
+		}
+	}
+	return result, nil
+}
+
+// buildDistribution converts a histogram into a distribution of the observations made since the previous scrape.
+// Each bucket yields a centroid weighted by the increase of its own count, located at the midpoint of the bucket.
+// The lowest bucket is located at its upper bound and the +Inf bucket at the highest finite bound.
+// Returns nil for the first scrape of a series, which only records the baseline counts.
+func (src *prometheusMetricsSource) buildDistribution(name string, m *dto.Metric, ts int64, tags string, seen map[string][]uint64) *metrics.Distribution {
+	buckets := make([]*dto.Bucket, len(m.GetHistogram().Bucket))
+	copy(buckets, m.GetHistogram().Bucket)
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].GetUpperBound() < buckets[j].GetUpperBound()
+	})
+
+	counts := make([]uint64, len(buckets))
+	for i, b := range buckets {
+		counts[i] = b.GetCumulativeCount()
+	}
+
+	key := histogramKey(name, m)
+	seen[key] = counts
+	prev, found := src.histograms[key]
+	if !found || len(prev) != len(counts) {
+		return nil
+	}
+	for i := range counts {
+		if counts[i] < prev[i] {
+			// counter reset: all current observations are new
+			prev = make([]uint64, len(counts))
+			break
+		}
+	}
+
+	var centroids []metrics.Centroid
+	var below uint64
+	for i := range buckets {
+		increase := counts[i] - prev[i]
+		if increase > below {
+			if value, ok := centroidValue(buckets, i); ok {
+				centroids = append(centroids, metrics.Centroid{
+					Value: value,
+					Count: int(increase - below),
+				})
+			}
+			below = increase
+		}
+	}
+	if len(centroids) == 0 {
+		return nil
+	}
+
+	return &metrics.Distribution{
+		Metric:        src.prefix + strings.Replace(name, "_", ".", -1),
+		Centroids:     centroids,
+		Granularities: src.granularities,
+		Timestamp:     ts,
+		Source:        src.source,
+		StrTags:       tags,
+	}
+}
+
+func centroidValue(buckets []*dto.Bucket, i int) (float64, bool) {
+	upper := buckets[i].GetUpperBound()
+	if i == 0 {
+		return upper, !math.IsInf(upper, 1)
+	}
+	lower := buckets[i-1].GetUpperBound()
+	if math.IsInf(upper, 1) {
+		return lower, true
+	}
+	return (lower + upper) / 2, true
+}
+
+func histogramKey(name string, m *dto.Metric) string {
+	buf := bytes.NewBufferString(name)
+	encodeLabelTags(m.Label, buf)
+	return buf.String()
+}
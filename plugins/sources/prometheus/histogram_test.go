@@ -0,0 +1,100 @@
+package prometheus
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+func testHistogram(counts ...uint64) *dto.Metric {
+	bounds := []float64{0.1, 1, math.Inf(1)}
+	var buckets []*dto.Bucket
+	for i, c := range counts {
+		buckets = append(buckets, &dto.Bucket{
+			UpperBound:      proto.Float64(bounds[i]),
+			CumulativeCount: proto.Uint64(c),
+		})
+	}
+	return &dto.Metric{
+		Label:     []*dto.LabelPair{{Name: proto.String("path"), Value: proto.String("/")}},
+		Histogram: &dto.Histogram{Bucket: buckets},
+	}
+}
+
+func scrapeDistribution(src *prometheusMetricsSource, m *dto.Metric) *metrics.Distribution {
+	seen := make(map[string][]uint64)
+	dist := src.buildDistribution("request_latency", m, 1000, " path=/", seen)
+	src.histograms = seen
+	return dist
+}
+
+func TestParseGranularities(t *testing.T) {
+	result, err := parseGranularities(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []metrics.Granularity{metrics.MinuteGranularity}, result)
+
+	result, err = parseGranularities([]string{"hour", "day"})
+	require.NoError(t, err)
+	assert.Equal(t, []metrics.Granularity{metrics.HourGranularity, metrics.DayGranularity}, result)
+
+	_, err = parseGranularities([]string{"week"})
+	assert.Error(t, err)
+}
+
+func TestBuildDistribution(t *testing.T) {
+	src := &prometheusMetricsSource{
+		prefix:        "prom.",
+		source:        "node1",
+		granularities: []metrics.Granularity{metrics.MinuteGranularity},
+	}
+
+	// the first scrape only records the baseline
+	assert.Nil(t, scrapeDistribution(src, testHistogram(2, 4, 5)))
+
+	dist := scrapeDistribution(src, testHistogram(5, 8, 10))
+	require.NotNil(t, dist)
+	assert.Equal(t, "prom.request.latency", dist.Metric)
+	assert.Equal(t, "node1", dist.Source)
+	assert.Equal(t, " path=/", dist.StrTags)
+	assert.Equal(t, int64(1000), dist.Timestamp)
+	assert.Equal(t, []metrics.Centroid{
+		{Value: 0.1, Count: 3},
+		{Value: 0.55, Count: 1},
+		{Value: 1, Count: 1},
+	}, dist.Centroids)
+
+	// no new observations
+	assert.Nil(t, scrapeDistribution(src, testHistogram(5, 8, 10)))
+
+	// counter reset
+	dist = scrapeDistribution(src, testHistogram(1, 1, 2))
+	require.NotNil(t, dist)
+	assert.Equal(t, []metrics.Centroid{
+		{Value: 0.1, Count: 1},
+		{Value: 1, Count: 1},
+	}, dist.Centroids)
+}
+
+func TestFilterDistribution(t *testing.T) {
+	src := &prometheusMetricsSource{
+		filters: filter.FromConfig(filter.Config{
+			MetricTagWhitelist: map[string][]string{"env": {"prod"}},
+			TagExclude:         []string{"pod"},
+		}),
+	}
+
+	dist := &metrics.Distribution{Metric: "request.latency", StrTags: " env=prod pod=web-1"}
+	assert.True(t, src.filterDistribution(dist))
+	assert.Equal(t, map[string]string{"env": "prod"}, dist.Tags)
+	assert.Equal(t, "", dist.StrTags)
+
+	dist = &metrics.Distribution{Metric: "request.latency", StrTags: " env=dev"}
+	assert.False(t, src.filterDistribution(dist))
+}
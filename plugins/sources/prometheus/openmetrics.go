@@ -0,0 +1,388 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	openMetricsEOF = "# EOF"
+
+	omCounter        = "counter"
+	omGauge          = "gauge"
+	omHistogram      = "histogram"
+	omGaugeHistogram = "gaugehistogram"
+	omSummary        = "summary"
+	omInfo           = "info"
+	omStateSet       = "stateset"
+	omUnknown        = "unknown"
+)
+
+// omFamily tracks the metric family a sample belongs to while parsing.
+type omFamily struct {
+	name    string
+	omType  string
+	metrics map[string]*dto.Metric
+}
+
+// openMetricsParser converts the OpenMetrics text format into the metric families used by the text format,
+// so that a given exporter yields the same points in either format. Counters are named with their _total
+// suffix, _created samples and info metrics become gauges named with their suffix, states of a stateset
+// become gauges with the state as a label and gauge histograms become histograms. Exemplars are validated
+// and dropped since Wavefront has no equivalent.
+type openMetricsParser struct {
+	families map[string]*dto.MetricFamily
+	current  *omFamily
+}
+
+// parseOpenMetrics parses an OpenMetrics text exposition. The exposition must be terminated by "# EOF".
+func parseOpenMetrics(buf []byte) (map[string]*dto.MetricFamily, error) {
+	p := &openMetricsParser{families: make(map[string]*dto.MetricFamily)}
+
+	lines := strings.Split(string(buf), "\n")
+	// a trailing newline after # EOF is allowed
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	eof := false
+	for i, line := range lines {
+		if eof {
+			return nil, fmt.Errorf("line %d: unexpected content after %s", i+1, openMetricsEOF)
+		}
+		if line == openMetricsEOF {
+			eof = true
+			continue
+		}
+		var err error
+		if strings.HasPrefix(line, "#") {
+			err = p.parseComment(line)
+		} else {
+			err = p.parseSample(line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+	}
+	if !eof {
+		return nil, fmt.Errorf("missing %s, the exposition may be truncated", openMetricsEOF)
+	}
+	return p.families, nil
+}
+
+func (p *openMetricsParser) parseComment(line string) error {
+	parts := strings.SplitN(line, " ", 4)
+	if len(parts) < 3 || parts[0] != "#" {
+		return fmt.Errorf("invalid comment: %q", line)
+	}
+	keyword, name := parts[1], parts[2]
+	switch keyword {
+	case "TYPE":
+		if len(parts) < 4 {
+			return fmt.Errorf("missing type for %s", name)
+		}
+		switch parts[3] {
+		case omCounter, omGauge, omHistogram, omGaugeHistogram, omSummary, omInfo, omStateSet, omUnknown:
+			p.startFamily(name, parts[3])
+		default:
+			return fmt.Errorf("invalid type %q for %s", parts[3], name)
+		}
+	case "HELP", "UNIT":
+		if p.current == nil || p.current.name != name {
+			p.startFamily(name, omUnknown)
+		}
+	default:
+		return fmt.Errorf("invalid comment: %q", line)
+	}
+	return nil
+}
+
+func (p *openMetricsParser) startFamily(name, omType string) {
+	p.current = &omFamily{
+		name:    name,
+		omType:  omType,
+		metrics: make(map[string]*dto.Metric),
+	}
+}
+
+func (p *openMetricsParser) parseSample(line string) error {
+	name, labels, rest, err := parseSeries(line)
+	if err != nil {
+		return err
+	}
+
+	// drop the exemplar if present
+	if idx := strings.Index(rest, "#"); idx >= 0 {
+		if err := validateExemplar(rest[idx+1:]); err != nil {
+			return err
+		}
+		rest = rest[:idx]
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return fmt.Errorf("invalid sample: %q", line)
+	}
+	value, err := parseFloat(fields[0])
+	if err != nil {
+		return fmt.Errorf("invalid value %q: %v", fields[0], err)
+	}
+	var timestampMs int64
+	if len(fields) == 2 {
+		ts, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q: %v", fields[1], err)
+		}
+		// OpenMetrics timestamps are in seconds
+		timestampMs = int64(ts * 1000)
+	}
+
+	f := p.current
+	if f == nil || !f.matches(name) {
+		// samples without metadata are their own family of unknown type
+		p.startFamily(name, omUnknown)
+		f = p.current
+	}
+	suffix := strings.TrimPrefix(name, f.name)
+
+	if suffix == "_created" {
+		m := p.metric(name, dto.MetricType_GAUGE, labels, "")
+		m.Gauge = &dto.Gauge{Value: proto.Float64(value)}
+		setTimestamp(m, timestampMs)
+		return nil
+	}
+
+	switch f.omType {
+	case omCounter:
+		m := p.metric(name, dto.MetricType_COUNTER, labels, "")
+		m.Counter = &dto.Counter{Value: proto.Float64(value)}
+		setTimestamp(m, timestampMs)
+	case omGauge, omInfo, omStateSet:
+		m := p.metric(name, dto.MetricType_GAUGE, labels, "")
+		m.Gauge = &dto.Gauge{Value: proto.Float64(value)}
+		setTimestamp(m, timestampMs)
+	case omHistogram, omGaugeHistogram:
+		m := p.metric(f.name, dto.MetricType_HISTOGRAM, labels, "le")
+		if m.Histogram == nil {
+			m.Histogram = &dto.Histogram{}
+		}
+		switch suffix {
+		case "_bucket", "_gbucket":
+			le, err := parseFloat(labelValue(labels, "le"))
+			if err != nil {
+				return fmt.Errorf("invalid le label for %s: %v", name, err)
+			}
+			m.Histogram.Bucket = append(m.Histogram.Bucket, &dto.Bucket{
+				UpperBound:      proto.Float64(le),
+				CumulativeCount: proto.Uint64(toUint64(value)),
+			})
+		case "_count", "_gcount":
+			m.Histogram.SampleCount = proto.Uint64(toUint64(value))
+		case "_sum", "_gsum":
+			m.Histogram.SampleSum = proto.Float64(value)
+		}
+		setTimestamp(m, timestampMs)
+	case omSummary:
+		m := p.metric(f.name, dto.MetricType_SUMMARY, labels, "quantile")
+		if m.Summary == nil {
+			m.Summary = &dto.Summary{}
+		}
+		switch suffix {
+		case "":
+			q, err := parseFloat(labelValue(labels, "quantile"))
+			if err != nil {
+				return fmt.Errorf("invalid quantile label for %s: %v", name, err)
+			}
+			m.Summary.Quantile = append(m.Summary.Quantile, &dto.Quantile{
+				Quantile: proto.Float64(q),
+				Value:    proto.Float64(value),
+			})
+		case "_count":
+			m.Summary.SampleCount = proto.Uint64(toUint64(value))
+		case "_sum":
+			m.Summary.SampleSum = proto.Float64(value)
+		}
+		setTimestamp(m, timestampMs)
+	default:
+		m := p.metric(name, dto.MetricType_UNTYPED, labels, "")
+		m.Untyped = &dto.Untyped{Value: proto.Float64(value)}
+		setTimestamp(m, timestampMs)
+	}
+	return nil
+}
+
+// matches returns true if the given sample name belongs to the family.
+func (f *omFamily) matches(sample string) bool {
+	if !strings.HasPrefix(sample, f.name) {
+		return false
+	}
+	suffix := sample[len(f.name):]
+	switch f.omType {
+	case omCounter:
+		return suffix == "_total" || suffix == "_created"
+	case omHistogram:
+		return suffix == "_bucket" || suffix == "_count" || suffix == "_sum" || suffix == "_created"
+	case omGaugeHistogram:
+		return suffix == "_gbucket" || suffix == "_gcount" || suffix == "_gsum"
+	case omSummary:
+		return suffix == "" || suffix == "_count" || suffix == "_sum" || suffix == "_created"
+	case omInfo:
+		return suffix == "_info"
+	default:
+		return suffix == ""
+	}
+}
+
+// metric returns the metric for the given labels within the named family, creating both if needed.
+// The label named by exclude is not part of the identity of the metric nor included in its labels.
+func (p *openMetricsParser) metric(family string, mtype dto.MetricType, labels []*dto.LabelPair, exclude string) *dto.Metric {
+	mf, found := p.families[family]
+	if !found {
+		mf = &dto.MetricFamily{
+			Name: proto.String(family),
+			Type: mtype.Enum(),
+		}
+		p.families[family] = mf
+	}
+
+	var filtered []*dto.LabelPair
+	for _, l := range labels {
+		if l.GetName() != exclude {
+			filtered = append(filtered, l)
+		}
+	}
+	key := family + "|" + labelsKey(filtered)
+	if m, found := p.current.metrics[key]; found {
+		return m
+	}
+	m := &dto.Metric{Label: filtered}
+	mf.Metric = append(mf.Metric, m)
+	p.current.metrics[key] = m
+	return m
+}
+
+func setTimestamp(m *dto.Metric, timestampMs int64) {
+	if timestampMs != 0 {
+		m.TimestampMs = proto.Int64(timestampMs)
+	}
+}
+
+func labelsKey(labels []*dto.LabelPair) string {
+	pairs := make([]string, len(labels))
+	for i, l := range labels {
+		pairs[i] = l.GetName() + "=" + l.GetValue()
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\xff")
+}
+
+func labelValue(labels []*dto.LabelPair, name string) string {
+	for _, l := range labels {
+		if l.GetName() == name {
+			return l.GetValue()
+		}
+	}
+	return ""
+}
+
+// parseSeries parses the metric name and labels of a sample line and returns the remainder of the line.
+func parseSeries(line string) (string, []*dto.LabelPair, string, error) {
+	end := strings.IndexAny(line, "{ ")
+	if end <= 0 {
+		return "", nil, "", fmt.Errorf("invalid sample: %q", line)
+	}
+	name := line[:end]
+	if line[end] == ' ' {
+		return name, nil, line[end:], nil
+	}
+	labels, n, err := parseLabels(line[end:])
+	if err != nil {
+		return "", nil, "", fmt.Errorf("invalid labels for %s: %v", name, err)
+	}
+	return name, labels, line[end+n:], nil
+}
+
+// parseLabels parses a label set of the form {a="b",c="d"} and returns the number of bytes consumed.
+func parseLabels(s string) ([]*dto.LabelPair, int, error) {
+	var labels []*dto.LabelPair
+	i := 1
+	for {
+		if i >= len(s) {
+			return nil, 0, fmt.Errorf("unterminated label set")
+		}
+		if s[i] == '}' {
+			return labels, i + 1, nil
+		}
+		eq := strings.IndexByte(s[i:], '=')
+		if eq <= 0 || i+eq+1 >= len(s) || s[i+eq+1] != '"' {
+			return nil, 0, fmt.Errorf("invalid label at %q", s[i:])
+		}
+		name := s[i : i+eq]
+		i += eq + 2
+
+		var value strings.Builder
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i])
+				}
+				continue
+			}
+			value.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return nil, 0, fmt.Errorf("unterminated label value for %s", name)
+		}
+		i++
+		labels = append(labels, &dto.LabelPair{Name: proto.String(name), Value: proto.String(value.String())})
+		if i < len(s) && s[i] == ',' {
+			i++
+		}
+	}
+}
+
+func validateExemplar(s string) error {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") {
+		return fmt.Errorf("invalid exemplar: %q", s)
+	}
+	_, n, err := parseLabels(s)
+	if err != nil {
+		return fmt.Errorf("invalid exemplar: %v", err)
+	}
+	fields := strings.Fields(s[n:])
+	if len(fields) == 0 || len(fields) > 2 {
+		return fmt.Errorf("invalid exemplar: %q", s)
+	}
+	if _, err := parseFloat(fields[0]); err != nil {
+		return fmt.Errorf("invalid exemplar value: %q", fields[0])
+	}
+	return nil
+}
+
+func parseFloat(s string) (float64, error) {
+	switch s {
+	case "+Inf":
+		return math.Inf(1), nil
+	case "-Inf":
+		return math.Inf(-1), nil
+	case "NaN":
+		return math.NaN(), nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func toUint64(value float64) uint64 {
+	if value < 0 || math.IsNaN(value) {
+		return 0
+	}
+	return uint64(value)
+}
@@ -0,0 +1,125 @@
+package prometheus
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleOpenMetrics = `# TYPE http_requests counter
+# HELP http_requests Total HTTP requests.
+http_requests_total{code="200",path="/a b"} 1027 1520879607.789 # {trace_id="KOO5S4vxi0o"} 0.67
+http_requests_created{code="200",path="/a b"} 1520430000.123
+# TYPE temperature gauge
+# UNIT temperature celsius
+temperature{room="a\"b\\c"} 21.5
+# TYPE build info
+build_info{version="1.2.3",revision="abc"} 1
+# TYPE feature stateset
+feature{feature="a"} 1
+feature{feature="b"} 0
+# TYPE latency histogram
+latency_bucket{le="0.1"} 8 # {trace_id="abc"} 0.05 1520879607.789
+latency_bucket{le="1"} 10
+latency_bucket{le="+Inf"} 11
+latency_count 11
+latency_sum 3.5
+latency_created 1520430000
+# TYPE queue gaugehistogram
+queue_gbucket{le="10"} 3
+queue_gbucket{le="+Inf"} 5
+queue_gcount 5
+queue_gsum 22
+# TYPE rpc summary
+rpc{quantile="0.5"} 0.2
+rpc{quantile="0.99"} 1.5
+rpc_count 100
+rpc_sum 30
+untyped_metric 42
+# EOF
+`
+
+func TestParseOpenMetrics(t *testing.T) {
+	families, err := parseOpenMetrics([]byte(sampleOpenMetrics))
+	require.NoError(t, err)
+
+	mf := families["http_requests_total"]
+	require.NotNil(t, mf)
+	assert.Equal(t, dto.MetricType_COUNTER, mf.GetType())
+	require.Equal(t, 1, len(mf.Metric))
+	assert.Equal(t, 1027.0, mf.Metric[0].GetCounter().GetValue())
+	assert.Equal(t, int64(1520879607789), mf.Metric[0].GetTimestampMs())
+	assert.Equal(t, "/a b", labelValue(mf.Metric[0].Label, "path"))
+
+	mf = families["http_requests_created"]
+	require.NotNil(t, mf)
+	assert.Equal(t, dto.MetricType_GAUGE, mf.GetType())
+	assert.Equal(t, 1520430000.123, mf.Metric[0].GetGauge().GetValue())
+
+	mf = families["temperature"]
+	require.NotNil(t, mf)
+	assert.Equal(t, `a"b\c`, labelValue(mf.Metric[0].Label, "room"))
+
+	mf = families["build_info"]
+	require.NotNil(t, mf)
+	assert.Equal(t, dto.MetricType_GAUGE, mf.GetType())
+	assert.Equal(t, "1.2.3", labelValue(mf.Metric[0].Label, "version"))
+
+	mf = families["feature"]
+	require.NotNil(t, mf)
+	assert.Equal(t, 2, len(mf.Metric))
+
+	mf = families["latency"]
+	require.NotNil(t, mf)
+	assert.Equal(t, dto.MetricType_HISTOGRAM, mf.GetType())
+	require.Equal(t, 1, len(mf.Metric))
+	assert.Equal(t, 3, len(mf.Metric[0].GetHistogram().Bucket))
+	assert.Equal(t, uint64(11), mf.Metric[0].GetHistogram().GetSampleCount())
+	assert.Equal(t, 3.5, mf.Metric[0].GetHistogram().GetSampleSum())
+	assert.Empty(t, mf.Metric[0].Label)
+	assert.NotNil(t, families["latency_created"])
+
+	mf = families["queue"]
+	require.NotNil(t, mf)
+	assert.Equal(t, dto.MetricType_HISTOGRAM, mf.GetType())
+	assert.Equal(t, uint64(5), mf.Metric[0].GetHistogram().GetSampleCount())
+
+	mf = families["rpc"]
+	require.NotNil(t, mf)
+	assert.Equal(t, dto.MetricType_SUMMARY, mf.GetType())
+	assert.Equal(t, 2, len(mf.Metric[0].GetSummary().Quantile))
+	assert.Equal(t, uint64(100), mf.Metric[0].GetSummary().GetSampleCount())
+
+	mf = families["untyped_metric"]
+	require.NotNil(t, mf)
+	assert.Equal(t, dto.MetricType_UNTYPED, mf.GetType())
+	assert.Equal(t, 42.0, mf.Metric[0].GetUntyped().GetValue())
+}
+
+func TestParseOpenMetricsEOF(t *testing.T) {
+	_, err := parseOpenMetrics([]byte("# TYPE a gauge\na 1\n"))
+	assert.Error(t, err)
+
+	_, err = parseOpenMetrics([]byte("# TYPE a gauge\na 1\n# EOF\na 2\n"))
+	assert.Error(t, err)
+
+	_, err = parseOpenMetrics([]byte("# EOF\n"))
+	assert.NoError(t, err)
+}
+
+func TestParseOpenMetricsInvalid(t *testing.T) {
+	invalid := []string{
+		"# TYPE a foo\n# EOF\n",
+		"a{b=\"c\" 1\n# EOF\n",
+		"a{b=c} 1\n# EOF\n",
+		"a abc\n# EOF\n",
+		"a 1 # trace_id 1\n# EOF\n",
+		"# random comment\n# EOF\n",
+	}
+	for _, s := range invalid {
+		_, err := parseOpenMetrics([]byte(s))
+		assert.Error(t, err, s)
+	}
+}
@@ -3,19 +3,25 @@ package prometheus
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"io"
 	"io/ioutil"
 	"math"
+	"mime"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/cardinality"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/httputil"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/leadership"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/relabel"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
 
 	log "github.com/sirupsen/logrus"
@@ -26,6 +32,15 @@ import (
 	"github.com/wavefronthq/go-metrics-wavefront/reporting"
 )
 
+const (
+	formatText        = "text"
+	formatOpenMetrics = "openmetrics"
+	formatProtobuf    = "protobuf"
+
+	// prefer the richer formats while accepting the text format from any exporter
+	acceptHeader = `application/openmetrics-text; version=0.0.1,application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.8,text/plain;version=0.0.4;q=0.5,*/*;q=0.1`
+)
+
 var (
 	collectErrors   gometrics.Counter
 	filteredPoints  gometrics.Counter
@@ -46,18 +61,36 @@ type prometheusMetricsSource struct {
 	tags       map[string]string
 	buf        *bytes.Buffer
 	filters    filter.Filter
+	relabeler  *relabel.Relabeler
 	client     *http.Client
 	pps        gometrics.Counter
 	eps        gometrics.Counter
+
+	// use the sample timestamps provided by the exporter when present
+	honorTimestamps bool
+
+	// send histograms as distributions
+	distributions bool
+	granularities []metrics.Granularity
+	// cumulative bucket counts of the histograms from the previous scrape
+	histograms map[string][]uint64
+	mtx        sync.Mutex
+
+	// limits the number of unique series, nil when not configured. May be shared with other sources.
+	limiter *cardinality.Limiter
 }
 
 //TODO: move tags, prefix, source, filters into a single common struct used by all sources and sinks
-func NewPrometheusMetricsSource(metricsURL, prefix, source, discovered string, tags map[string]string, filters filter.Filter, httpCfg httputil.ClientConfig) (metrics.MetricsSource, error) {
+func NewPrometheusMetricsSource(metricsURL, prefix, source, discovered string, tags map[string]string, filters filter.Filter, relabeler *relabel.Relabeler, httpCfg httputil.ClientConfig, honorTimestamps bool, distCfg configuration.DistributionConfig, limiter *cardinality.Limiter) (metrics.MetricsSource, error) {
 	client, err := httpClient(metricsURL, httpCfg)
 	if err != nil {
 		log.Errorf("error creating http client: %q", err)
 		return nil, err
 	}
+	granularities, err := parseGranularities(distCfg.Granularities)
+	if err != nil {
+		return nil, err
+	}
 
 	pt := extractTags(tags, discovered, metricsURL)
 	ppsKey := reporting.EncodeKey("target.points.collected", pt)
@@ -70,9 +103,16 @@ func NewPrometheusMetricsSource(metricsURL, prefix, source, discovered string, t
 		tags:       tags,
 		buf:        bytes.NewBufferString(""),
 		filters:    filters,
+		relabeler:  relabeler,
 		client:     client,
 		pps:        gometrics.GetOrRegisterCounter(ppsKey, gometrics.DefaultRegistry),
 		eps:        gometrics.GetOrRegisterCounter(epsKey, gometrics.DefaultRegistry),
+
+		honorTimestamps: honorTimestamps,
+		distributions:   distCfg.Enabled,
+		granularities:   granularities,
+		histograms:      make(map[string][]uint64),
+		limiter:         limiter,
 	}, nil
 }
 
@@ -115,12 +155,20 @@ func (src *prometheusMetricsSource) Name() string {
 	return fmt.Sprintf("prometheus_source: %s", src.metricsURL)
 }
 
-func (src *prometheusMetricsSource) ScrapeMetrics() (*metrics.DataBatch, error) {
+func (src *prometheusMetricsSource) ScrapeMetrics(ctx context.Context) (*metrics.DataBatch, error) {
 	result := &metrics.DataBatch{
 		Timestamp: time.Now(),
 	}
 
-	resp, err := src.client.Get(src.metricsURL)
+	req, err := http.NewRequest("GET", src.metricsURL, nil)
+	if err != nil {
+		collectErrors.Inc(1)
+		src.eps.Inc(1)
+		return nil, err
+	}
+	req.Header.Set("Accept", acceptHeader)
+
+	resp, err := src.client.Do(req.WithContext(ctx))
 	if err != nil {
 		collectErrors.Inc(1)
 		src.eps.Inc(1)
@@ -140,56 +188,127 @@ func (src *prometheusMetricsSource) ScrapeMetrics() (*metrics.DataBatch, error)
 		src.eps.Inc(1)
 		return nil, err
 	}
-	points, err := src.parseMetrics(body, resp.Header)
+	points, distributions, err := src.parseMetrics(body, resp.Header)
 	if err != nil {
 		collectErrors.Inc(1)
 		src.eps.Inc(1)
 		return result, err
 	}
+	if src.limiter != nil {
+		points = src.limiter.Limit(points, result.Timestamp)
+		distributions = src.limiter.LimitDistributions(distributions, result.Timestamp)
+	}
 	result.MetricPoints = points
+	result.Distributions = distributions
 	collectedPoints.Inc(int64(len(points)))
 	src.pps.Inc(int64(len(points)))
 
 	return result, nil
 }
 
-func (src *prometheusMetricsSource) parseMetrics(buf []byte, header http.Header) ([]*metrics.MetricPoint, error) {
-	var parser expfmt.TextParser
+func (src *prometheusMetricsSource) parseMetrics(buf []byte, header http.Header) ([]*metrics.MetricPoint, []*metrics.Distribution, error) {
+	var metricFamilies map[string]*dto.MetricFamily
+	var err error
 
-	// parse even if the buffer begins with a newline
-	buf = bytes.TrimPrefix(buf, []byte("\n"))
-	// Read raw data
-	buffer := bytes.NewBuffer(buf)
-	reader := bufio.NewReader(buffer)
+	switch responseFormat(header) {
+	case formatOpenMetrics:
+		metricFamilies, err = parseOpenMetrics(buf)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading openmetrics format failed: %v", err)
+		}
+	case formatProtobuf:
+		metricFamilies, err = parseProtobuf(buf)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading protobuf format failed: %v", err)
+		}
+	default:
+		var parser expfmt.TextParser
+
+		// parse even if the buffer begins with a newline
+		buf = bytes.TrimPrefix(buf, []byte("\n"))
+		// Read raw data
+		buffer := bytes.NewBuffer(buf)
+		reader := bufio.NewReader(buffer)
+
+		metricFamilies, err = parser.TextToMetricFamilies(reader)
+		if err != nil {
+			log.Errorf("reading text format failed: %s", err)
+		}
+	}
+	return src.buildPoints(metricFamilies)
+}
 
-	metricFamilies, err := parser.TextToMetricFamilies(reader)
+// responseFormat returns the exposition format of a response based on its Content-Type header.
+func responseFormat(header http.Header) string {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
 	if err != nil {
-		log.Errorf("reading text format failed: %s", err)
+		return formatText
 	}
-	return src.buildPoints(metricFamilies)
+	switch {
+	case mediaType == "application/openmetrics-text":
+		return formatOpenMetrics
+	case mediaType == "application/vnd.google.protobuf" &&
+		params["proto"] == "io.prometheus.client.MetricFamily" && params["encoding"] == "delimited":
+		return formatProtobuf
+	}
+	return formatText
 }
 
-func (src *prometheusMetricsSource) buildPoints(metricFamilies map[string]*dto.MetricFamily) ([]*metrics.MetricPoint, error) {
-	now := time.Now().Unix()
+// parseProtobuf parses length delimited protobuf encoded metric families.
+func parseProtobuf(buf []byte) (map[string]*dto.MetricFamily, error) {
+	result := make(map[string]*dto.MetricFamily)
+	decoder := expfmt.NewDecoder(bytes.NewReader(buf), expfmt.FmtProtoDelim)
+	for {
+		mf := &dto.MetricFamily{}
+		if err := decoder.Decode(mf); err != nil {
+			if err == io.EOF {
+				return result, nil
+			}
+			return nil, err
+		}
+		result[mf.GetName()] = mf
+	}
+}
+
+func (src *prometheusMetricsSource) buildPoints(metricFamilies map[string]*dto.MetricFamily) ([]*metrics.MetricPoint, []*metrics.Distribution, error) {
+	src.mtx.Lock()
+	defer src.mtx.Unlock()
+
+	now := metrics.UnixMillis(time.Now())
 	var result []*metrics.MetricPoint
+	var distributions []*metrics.Distribution
+	histograms := make(map[string][]uint64)
 
 	for metricName, mf := range metricFamilies {
 		for _, m := range mf.Metric {
 			tags := src.buildTags(m)
+			ts := now
+			if src.honorTimestamps && m.TimestampMs != nil {
+				ts = m.GetTimestampMs()
+			}
 			if mf.GetType() == dto.MetricType_SUMMARY {
 				// summary metric
-				result = append(result, src.buildQuantiles(metricName, m, now, tags)...)
+				result = append(result, src.buildQuantiles(metricName, m, ts, tags)...)
+			} else if mf.GetType() == dto.MetricType_HISTOGRAM && src.distributions {
+				// histogram metric sent as a distribution
+				result = append(result, src.buildHistoTotals(metricName, m, ts, tags)...)
+				dist := src.buildDistribution(metricName, m, ts, tags, histograms)
+				if dist != nil && src.filterDistribution(dist) {
+					distributions = append(distributions, dist)
+				}
 			} else if mf.GetType() == dto.MetricType_HISTOGRAM {
 				// histogram metric
-				result = append(result, src.buildHistos(metricName, m, now, tags)...)
+				result = append(result, src.buildHistos(metricName, m, ts, tags)...)
 			} else {
 				// standard metric
-				result = append(result, src.buildPoint(metricName, m, now, tags)...)
+				result = append(result, src.buildPoint(metricName, m, ts, tags)...)
 			}
 		}
 	}
-	log.Debugf("%s total points: %d", src.Name(), len(result))
-	return result, nil
+	// only retain the histograms seen in this scrape
+	src.histograms = histograms
+	log.Debugf("%s total points: %d distributions: %d", src.Name(), len(result), len(distributions))
+	return result, distributions, nil
 }
 
 func (src *prometheusMetricsSource) metricPoint(name string, value float64, ts int64, source string, tags string) *metrics.MetricPoint {
@@ -250,6 +369,12 @@ func (src *prometheusMetricsSource) buildHistos(name string, m *dto.Metric, now
 		point := src.metricPoint(name, float64(b.GetCumulativeCount()), now, src.source, newTags)
 		result = src.filterAppend(result, point)
 	}
+	return append(result, src.buildHistoTotals(name, m, now, tags)...)
+}
+
+// Get count and sum from histogram metric
+func (src *prometheusMetricsSource) buildHistoTotals(name string, m *dto.Metric, now int64, tags string) []*metrics.MetricPoint {
+	var result []*metrics.MetricPoint
 	point := src.metricPoint(name+".count", float64(m.GetHistogram().GetSampleCount()), now, src.source, tags)
 	result = src.filterAppend(result, point)
 	point = src.metricPoint(name+".sum", float64(m.GetHistogram().GetSampleSum()), now, src.source, tags)
@@ -290,7 +415,17 @@ func encodeTags(tags map[string]string, buf *bytes.Buffer) {
 }
 
 func (src *prometheusMetricsSource) filterAppend(slice []*metrics.MetricPoint, point *metrics.MetricPoint) []*metrics.MetricPoint {
-	if src.filters == nil || src.filters.Match(point.Metric, point.Tags) {
+	if src.relabeler != nil && !src.relabeler.Apply(point) {
+		filteredPoints.Inc(1)
+		log.Debugf("dropping relabeled metric: %s", point.Metric)
+		return slice
+	}
+	if src.filters == nil {
+		return append(slice, point)
+	}
+	// the filters match against and remove the decoded label tags
+	point.Tags, point.StrTags = metrics.DecodeTags(point.Tags, point.StrTags), ""
+	if src.filters.Match(point.Metric, point.Tags) {
 		return append(slice, point)
 	}
 	filteredPoints.Inc(1)
@@ -298,6 +433,25 @@ func (src *prometheusMetricsSource) filterAppend(slice []*metrics.MetricPoint, p
 	return slice
 }
 
+func (src *prometheusMetricsSource) filterDistribution(dist *metrics.Distribution) bool {
+	if src.relabeler != nil && !src.relabeler.ApplyDistribution(dist) {
+		filteredPoints.Inc(1)
+		log.Debugf("dropping relabeled distribution: %s", dist.Metric)
+		return false
+	}
+	if src.filters == nil {
+		return true
+	}
+	// the filters match against and remove the decoded label tags
+	dist.Tags, dist.StrTags = metrics.DecodeTags(dist.Tags, dist.StrTags), ""
+	if src.filters.Match(dist.Metric, dist.Tags) {
+		return true
+	}
+	filteredPoints.Inc(1)
+	log.Debugf("dropping distribution: %s", dist.Metric)
+	return false
+}
+
 type prometheusProvider struct {
 	metrics.DefaultMetricsSourceProvider
 	urls       []string
@@ -308,6 +462,7 @@ type prometheusProvider struct {
 	sources    []metrics.MetricsSource
 	tags       map[string]string
 	filters    filter.Filter
+	limiter    *cardinality.Limiter
 }
 
 func (p *prometheusProvider) GetMetricsSources() []metrics.MetricsSource {
@@ -322,6 +477,10 @@ func (p *prometheusProvider) Name() string {
 	return p.name
 }
 
+func (p *prometheusProvider) Stop() {
+	cardinality.ReleaseLimiter(p.limiter)
+}
+
 const providerName = "prometheus_metrics_provider"
 
 func NewPrometheusProvider(cfg configuration.PrometheusSourceConfig) (metrics.MetricsSourceProvider, error) {
@@ -347,9 +506,24 @@ func NewPrometheusProvider(cfg configuration.PrometheusSourceConfig) (metrics.Me
 	prefix := cfg.Prefix
 	tags := cfg.Tags
 	filters := filter.FromConfig(cfg.Filters)
+	relabeler, err := relabel.FromConfig(cfg.Relabel)
+	if err != nil {
+		return nil, err
+	}
+
+	// the targets of a discovery rule share the series budget of the rule
+	limiterName, limiterTags := name, extractTags(tags, discovered, cfg.URL)
+	if cfg.Rule != "" {
+		limiterName = fmt.Sprintf("%s: rule %s", providerName, cfg.Rule)
+		limiterTags = map[string]string{"discovered": discovered, "rule": cfg.Rule, "type": "prometheus"}
+	}
+	limiter, err := cardinality.AcquireLimiter(limiterName, cfg.Cardinality, limiterTags)
+	if err != nil {
+		return nil, err
+	}
 
 	var sources []metrics.MetricsSource
-	metricsSource, err := NewPrometheusMetricsSource(cfg.URL, prefix, source, discovered, tags, filters, httpCfg)
+	metricsSource, err := NewPrometheusMetricsSource(cfg.URL, prefix, source, discovered, tags, filters, relabeler, httpCfg, cfg.HonorTimestamps, cfg.Distributions, limiter)
 	if err == nil {
 		sources = append(sources, metricsSource)
 	} else {
@@ -365,5 +539,6 @@ func NewPrometheusProvider(cfg configuration.PrometheusSourceConfig) (metrics.Me
 		sources:    sources,
 		tags:       tags,
 		filters:    filters,
+		limiter:    limiter,
 	}, nil
 }
@@ -0,0 +1,169 @@
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/cardinality"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/filter"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/httputil"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+)
+
+func pointNames(points []*metrics.MetricPoint) map[string]float64 {
+	result := make(map[string]float64)
+	for _, p := range points {
+		result[p.Metric] = p.Value
+	}
+	return result
+}
+
+func scrape(t *testing.T, contentType string, body []byte) []*metrics.MetricPoint {
+	return scrapeWithTimestamps(t, contentType, body, false)
+}
+
+func scrapeWithTimestamps(t *testing.T, contentType string, body []byte, honorTimestamps bool) []*metrics.MetricPoint {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text"))
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	src, err := NewPrometheusMetricsSource(server.URL, "", "test", "", nil, nil, nil, httputil.ClientConfig{}, honorTimestamps, configuration.DistributionConfig{}, nil)
+	require.NoError(t, err)
+	batch, err := src.ScrapeMetrics(context.Background())
+	require.NoError(t, err)
+	return batch.MetricPoints
+}
+
+func TestScrapeText(t *testing.T) {
+	body := "# TYPE http_requests_total counter\nhttp_requests_total{code=\"200\"} 1027\n"
+	points := pointNames(scrape(t, "text/plain; version=0.0.4", []byte(body)))
+	assert.Equal(t, 1027.0, points["http.requests.total.counter"])
+}
+
+func TestScrapeOpenMetrics(t *testing.T) {
+	points := pointNames(scrape(t, "application/openmetrics-text; version=0.0.1; charset=utf-8", []byte(sampleOpenMetrics)))
+	assert.Equal(t, 1027.0, points["http.requests.total.counter"])
+	assert.Equal(t, 1520430000.123, points["http.requests.created.gauge"])
+	assert.Equal(t, 1.0, points["build.info.gauge"])
+	assert.Equal(t, 11.0, points["latency.count"])
+	assert.Equal(t, 42.0, points["untyped.metric.value"])
+}
+
+func TestScrapeProtobuf(t *testing.T) {
+	buf := &bytes.Buffer{}
+	encoder := expfmt.NewEncoder(buf, expfmt.FmtProtoDelim)
+	err := encoder.Encode(&dto.MetricFamily{
+		Name: proto.String("http_requests_total"),
+		Type: dto.MetricType_COUNTER.Enum(),
+		Metric: []*dto.Metric{{
+			Label:   []*dto.LabelPair{{Name: proto.String("code"), Value: proto.String("200")}},
+			Counter: &dto.Counter{Value: proto.Float64(1027)},
+		}},
+	})
+	require.NoError(t, err)
+
+	points := scrape(t, string(expfmt.FmtProtoDelim), buf.Bytes())
+	require.Equal(t, 1, len(points))
+	assert.Equal(t, "http.requests.total.counter", points[0].Metric)
+	assert.Equal(t, 1027.0, points[0].Value)
+	assert.Equal(t, " code=200", points[0].StrTags)
+}
+
+func TestHonorTimestamps(t *testing.T) {
+	body := "# TYPE a gauge\na 1 1520879607789\n# TYPE b gauge\nb 2\n"
+	before := metrics.UnixMillis(time.Now())
+
+	points := scrapeWithTimestamps(t, "text/plain; version=0.0.4", []byte(body), false)
+	require.Equal(t, 2, len(points))
+	for _, p := range points {
+		assert.True(t, p.Timestamp >= before)
+	}
+
+	points = scrapeWithTimestamps(t, "text/plain; version=0.0.4", []byte(body), true)
+	require.Equal(t, 2, len(points))
+	for _, p := range points {
+		if p.Metric == "a.gauge" {
+			assert.Equal(t, int64(1520879607789), p.Timestamp)
+		} else {
+			assert.True(t, p.Timestamp >= before)
+		}
+	}
+
+	points = scrapeWithTimestamps(t, "application/openmetrics-text; version=0.0.1", []byte("a 1 1520879607.789\n# EOF\n"), true)
+	require.Equal(t, 1, len(points))
+	assert.Equal(t, int64(1520879607789), points[0].Timestamp)
+}
+
+func TestFilterPoints(t *testing.T) {
+	src := &prometheusMetricsSource{
+		filters: filter.FromConfig(filter.Config{
+			MetricTagWhitelist: map[string][]string{"code": {"2*"}},
+			TagExclude:         []string{"path"},
+		}),
+	}
+
+	// the filters match against the label tags of the points
+	points := src.filterAppend(nil, &metrics.MetricPoint{Metric: "http.requests", StrTags: " code=200 path=/"})
+	require.Equal(t, 1, len(points))
+	assert.Equal(t, map[string]string{"code": "200"}, points[0].Tags)
+	assert.Equal(t, "", points[0].StrTags)
+
+	points = src.filterAppend(nil, &metrics.MetricPoint{Metric: "http.requests", StrTags: " code=500"})
+	assert.Equal(t, 0, len(points))
+}
+
+func TestResponseFormat(t *testing.T) {
+	header := http.Header{}
+	assert.Equal(t, formatText, responseFormat(header))
+	header.Set("Content-Type", "text/plain; version=0.0.4")
+	assert.Equal(t, formatText, responseFormat(header))
+	header.Set("Content-Type", "application/openmetrics-text; version=0.0.1")
+	assert.Equal(t, formatOpenMetrics, responseFormat(header))
+	header.Set("Content-Type", "application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited")
+	assert.Equal(t, formatProtobuf, responseFormat(header))
+	header.Set("Content-Type", "application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=text")
+	assert.Equal(t, formatText, responseFormat(header))
+}
+
+func TestRuleLimiterShared(t *testing.T) {
+	newProvider := func(url, rule string) *prometheusProvider {
+		provider, err := NewPrometheusProvider(configuration.PrometheusSourceConfig{
+			URL:         url,
+			Discovered:  "rule",
+			Rule:        rule,
+			Cardinality: cardinality.Config{MaxSeries: 10},
+		})
+		require.NoError(t, err)
+		return provider.(*prometheusProvider)
+	}
+	first := newProvider("http://10.0.0.1/metrics", "exporters")
+	second := newProvider("http://10.0.0.2/metrics", "exporters")
+	target := newProvider("http://10.0.0.3/metrics", "")
+	defer target.Stop()
+
+	require.NotNil(t, first.limiter)
+	assert.True(t, first.limiter == second.limiter)
+	assert.False(t, first.limiter == target.limiter)
+
+	// the budget of the rule is kept while any of its targets remains
+	first.Stop()
+	third := newProvider("http://10.0.0.4/metrics", "exporters")
+	assert.True(t, second.limiter == third.limiter)
+	second.Stop()
+	third.Stop()
+}
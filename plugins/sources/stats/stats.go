@@ -42,7 +42,8 @@ func newInternalMetricsSource(prefix string, tags map[string]string, filters fil
 	if len(tags) == 0 {
 		tags = make(map[string]string, 1)
 	}
-	return &internalMetricsSource{
+	// the internal metrics are read from memory, so there is no pending work to cancel
+	return metrics.NewMetricsSourceAdapter(&internalMetricsSource{
 		prefix:  prefix,
 		tags:    tags,
 		filters: filters,
@@ -51,7 +52,7 @@ func newInternalMetricsSource(prefix string, tags map[string]string, filters fil
 		source:      getDefault(util.GetNodeName(), "wavefront-kubernetes-collector"),
 		pps:         gometrics.GetOrRegisterCounter(ppsKey, gometrics.DefaultRegistry),
 		fps:         gometrics.GetOrRegisterCounter(fpsKey, gometrics.DefaultRegistry),
-	}, nil
+	}), nil
 }
 
 func getDefault(val, defaultVal string) string {
@@ -84,23 +85,23 @@ func (src *internalMetricsSource) internalStats() (*metrics.DataBatch, error) {
 	gometrics.DefaultRegistry.Each(func(name string, i interface{}) {
 		switch metric := i.(type) {
 		case gometrics.Counter:
-			points = src.filterAppend(points, src.point(name, float64(metric.Count()), now.Unix()))
+			points = src.filterAppend(points, src.point(name, float64(metric.Count()), metrics.UnixMillis(now)))
 		case gometrics.Gauge:
-			points = src.filterAppend(points, src.point(name, float64(metric.Value()), now.Unix()))
+			points = src.filterAppend(points, src.point(name, float64(metric.Value()), metrics.UnixMillis(now)))
 		case gometrics.GaugeFloat64:
-			points = src.filterAppend(points, src.point(name, metric.Value(), now.Unix()))
+			points = src.filterAppend(points, src.point(name, metric.Value(), metrics.UnixMillis(now)))
 		case gometrics.Timer:
 			timer := metric.Snapshot()
 			points = append(points, src.addHisto(name, timer.Min(), timer.Max(), timer.Mean(),
-				timer.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999}), now.Unix())...)
-			points = append(points, src.addRate(name, timer.Count(), timer.Rate1(), timer.RateMean(), now.Unix())...)
+				timer.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999}), metrics.UnixMillis(now))...)
+			points = append(points, src.addRate(name, timer.Count(), timer.Rate1(), timer.RateMean(), metrics.UnixMillis(now))...)
 		case gometrics.Histogram:
 			histo := metric.Snapshot()
 			points = append(points, src.addHisto(name, histo.Min(), histo.Max(), histo.Mean(),
-				histo.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999}), now.Unix())...)
+				histo.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999}), metrics.UnixMillis(now))...)
 		case gometrics.Meter:
 			meter := metric.Snapshot()
-			points = append(points, src.addRate(name, meter.Count(), meter.Rate1(), meter.RateMean(), now.Unix())...)
+			points = append(points, src.addRate(name, meter.Count(), meter.Rate1(), meter.RateMean(), metrics.UnixMillis(now))...)
 		}
 	})
 	src.pps.Inc(int64(len(points)))
@@ -0,0 +1,226 @@
+// Based on https://github.com/kubernetes-retired/heapster/blob/master/metrics/sources/kubelet/kubelet.go
+// Diff against master for changes to the original code.
+
+// Copyright 2014 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package summary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cadvisor "github.com/google/cadvisor/info/v1"
+	log "github.com/sirupsen/logrus"
+
+	. "github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources/summary/kubelet"
+)
+
+const (
+	// cadvisor labels set on the containers managed by the kubelet
+	kubernetesPodNameLabel       = "io.kubernetes.pod.name"
+	kubernetesPodNamespaceLabel  = "io.kubernetes.pod.namespace"
+	kubernetesPodUIDLabel        = "io.kubernetes.pod.uid"
+	kubernetesContainerNameLabel = "io.kubernetes.container.name"
+
+	// name of the pod sandbox container that holds the network namespace of a pod
+	infraContainerName = "POD"
+
+	// window of stats requested from cadvisor, the latest sample within it is used
+	cadvisorStatsWindow = 30 * time.Second
+)
+
+// Maps the raw cgroup names of the node level system containers to the names used by the summary source.
+var systemContainers = map[string]string{
+	"/kubelet":                         "kubelet",
+	"/system.slice/kubelet.service":    "kubelet",
+	"/docker-daemon":                   "docker-daemon",
+	"/system.slice/docker.service":     "docker-daemon",
+	"/system.slice/containerd.service": "docker-daemon",
+	"/system":                          "system",
+	"/system.slice":                    "system",
+	"/kubepods":                        "pods",
+	"/kubepods.slice":                  "pods",
+}
+
+// Kubelet-provided cadvisor metrics for pod and system containers.
+type cadvisorMetricsSource struct {
+	node          NodeInfo
+	kubeletClient *kubelet.KubeletClient
+}
+
+func NewCadvisorMetricsSource(node NodeInfo, client *kubelet.KubeletClient) MetricsSource {
+	return &cadvisorMetricsSource{
+		node:          node,
+		kubeletClient: client,
+	}
+}
+
+func (src *cadvisorMetricsSource) Name() string {
+	return src.String()
+}
+
+func (src *cadvisorMetricsSource) String() string {
+	return fmt.Sprintf("kubelet_cadvisor:%s:%d", src.node.IP, src.node.Port)
+}
+
+func (src *cadvisorMetricsSource) ScrapeMetrics(ctx context.Context) (*DataBatch, error) {
+	result := &DataBatch{
+		Timestamp: time.Now(),
+	}
+
+	end := result.Timestamp
+	containers, err := src.kubeletClient.GetAllRawContainers(ctx, src.node.Host, end.Add(-cadvisorStatsWindow), end)
+	if err != nil {
+		collectErrors.Inc(1)
+		return nil, err
+	}
+
+	result.MetricSets = src.decodeContainers(containers)
+	return result, nil
+}
+
+// decodeContainers translates the raw cadvisor containers into the flattened MetricSet API.
+func (src *cadvisorMetricsSource) decodeContainers(containers []cadvisor.ContainerInfo) map[string]*MetricSet {
+	result := map[string]*MetricSet{}
+	for i := range containers {
+		key, metrics := src.decodeContainer(&containers[i])
+		if key == "" || metrics == nil {
+			continue
+		}
+		// This check ensures that we are not replacing metrics of running container with metrics of terminated one if
+		// there are two exactly same containers reported by kubelet.
+		if existing, exist := result[key]; exist && metrics.CollectionStartTime.Before(existing.CollectionStartTime) {
+			log.Debugf("Metrics reported from two containers with the same key: %v. Metrics from the older container are going to be dropped.", key)
+			continue
+		}
+		result[key] = metrics
+	}
+	log.Debugf("End cadvisor decode")
+	return result
+}
+
+func (src *cadvisorMetricsSource) decodeContainer(c *cadvisor.ContainerInfo) (string, *MetricSet) {
+	if len(c.Stats) == 0 {
+		return "", nil
+	}
+	stat := c.Stats[0]
+
+	var key string
+	cMetrics := &MetricSet{
+		Labels: map[string]string{
+			LabelNodename.Key: src.node.NodeName,
+			LabelHostname.Key: src.node.HostName,
+			LabelHostID.Key:   src.node.HostID,
+		},
+		MetricValues:        map[string]MetricValue{},
+		LabeledMetrics:      []LabeledMetric{},
+		CollectionStartTime: c.Spec.CreationTime,
+		ScrapeTime:          stat.Timestamp,
+	}
+
+	if c.Name == "/" {
+		key = NodeKey(src.node.NodeName)
+		cMetrics.Labels[LabelMetricSetType.Key] = MetricSetTypeNode
+		src.decodeStandardMetrics(cMetrics, c, stat, true)
+		src.decodeInterfaceStats(cMetrics, stat)
+		return key, cMetrics
+	}
+
+	if podName, found := c.Spec.Labels[kubernetesPodNameLabel]; found {
+		ns := c.Spec.Labels[kubernetesPodNamespaceLabel]
+		cName := c.Spec.Labels[kubernetesContainerNameLabel]
+		cMetrics.Labels[LabelPodId.Key] = c.Spec.Labels[kubernetesPodUIDLabel]
+		cMetrics.Labels[LabelPodName.Key] = podName
+		cMetrics.Labels[LabelNamespaceName.Key] = ns
+
+		if cName == infraContainerName {
+			// The sandbox only provides the network stats of the pod, the remaining pod
+			// metrics are aggregated from its containers by the pod aggregator.
+			key = PodKey(ns, podName)
+			cMetrics.Labels[LabelMetricSetType.Key] = MetricSetTypePod
+			src.decodeNetworkStats(cMetrics, c, stat)
+			src.decodeInterfaceStats(cMetrics, stat)
+			return key, cMetrics
+		}
+		key = PodContainerKey(ns, podName, cName)
+		cMetrics.Labels[LabelMetricSetType.Key] = MetricSetTypePodContainer
+		cMetrics.Labels[LabelContainerName.Key] = cName
+		src.decodeStandardMetrics(cMetrics, c, stat, false)
+		return key, cMetrics
+	}
+
+	if cName, found := systemContainers[c.Name]; found {
+		key = NodeContainerKey(src.node.NodeName, cName)
+		cMetrics.Labels[LabelMetricSetType.Key] = MetricSetTypeSystemContainer
+		cMetrics.Labels[LabelContainerName.Key] = cName
+		src.decodeStandardMetrics(cMetrics, c, stat, false)
+		return key, cMetrics
+	}
+
+	log.Tracef("skipping cadvisor container %s", c.Name)
+	return "", nil
+}
+
+// decodeStandardMetrics adds the standard and labeled metrics available for the container.
+// Network metrics are only added if includeNetwork is set, as containers share the network of their pod.
+func (src *cadvisorMetricsSource) decodeStandardMetrics(metrics *MetricSet, c *cadvisor.ContainerInfo, stat *cadvisor.ContainerStats, includeNetwork bool) {
+	for _, metric := range StandardMetrics {
+		if metric.HasValue == nil || !metric.HasValue(&c.Spec) {
+			continue
+		}
+		if !includeNetwork && MetricFamilyForName(metric.Name) == MetricFamilyNetwork {
+			continue
+		}
+		metrics.MetricValues[metric.Name] = metric.GetValue(&c.Spec, stat)
+	}
+	for _, metric := range LabeledMetrics {
+		if metric.HasLabeledMetric != nil && metric.HasLabeledMetric(&c.Spec, stat) {
+			metrics.LabeledMetrics = append(metrics.LabeledMetrics, metric.GetLabeledMetric(&c.Spec, stat)...)
+		}
+	}
+}
+
+func (src *cadvisorMetricsSource) decodeNetworkStats(metrics *MetricSet, c *cadvisor.ContainerInfo, stat *cadvisor.ContainerStats) {
+	for _, metric := range NetworkMetrics {
+		if metric.HasValue != nil && metric.HasValue(&c.Spec) {
+			metrics.MetricValues[metric.Name] = metric.GetValue(&c.Spec, stat)
+		}
+	}
+}
+
+// decodeInterfaceStats adds the network metrics labeled by interface, similar to the summary source.
+func (src *cadvisorMetricsSource) decodeInterfaceStats(metrics *MetricSet, stat *cadvisor.ContainerStats) {
+	for _, netInterface := range stat.Network.Interfaces {
+		intfLabels := map[string]string{NetworkInterfaceKey: netInterface.Name}
+		addLabeledCounter(metrics, &MetricNetworkRx, intfLabels, netInterface.RxBytes)
+		addLabeledCounter(metrics, &MetricNetworkRxErrors, intfLabels, netInterface.RxErrors)
+		addLabeledCounter(metrics, &MetricNetworkTx, intfLabels, netInterface.TxBytes)
+		addLabeledCounter(metrics, &MetricNetworkTxErrors, intfLabels, netInterface.TxErrors)
+	}
+}
+
+func addLabeledCounter(metrics *MetricSet, metric *Metric, labels map[string]string, value uint64) {
+	metrics.LabeledMetrics = append(metrics.LabeledMetrics, LabeledMetric{
+		Name:   metric.Name,
+		Labels: labels,
+		MetricValue: MetricValue{
+			ValueType:  ValueInt64,
+			MetricType: metric.Type,
+			IntValue:   int64(value),
+		},
+	})
+}
@@ -0,0 +1,180 @@
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	cadvisor "github.com/google/cadvisor/info/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources/summary/kubelet"
+	util "k8s.io/client-go/util/testing"
+)
+
+func testingCadvisorMetricsSource() *cadvisorMetricsSource {
+	return &cadvisorMetricsSource{
+		node:          nodeInfo,
+		kubeletClient: &kubelet.KubeletClient{},
+	}
+}
+
+func testContainer(name string, labels map[string]string, seed uint64) cadvisor.ContainerInfo {
+	return cadvisor.ContainerInfo{
+		ContainerReference: cadvisor.ContainerReference{Name: name},
+		Spec: cadvisor.ContainerSpec{
+			CreationTime: startTime,
+			Labels:       labels,
+			HasCpu:       true,
+			HasMemory:    true,
+			HasNetwork:   true,
+			HasDiskIo:    true,
+		},
+		Stats: []*cadvisor.ContainerStats{{
+			Timestamp: scrapeTime,
+			Cpu: cadvisor.CpuStats{
+				Usage: cadvisor.CpuUsage{Total: seed},
+				CFS: cadvisor.CpuCFS{
+					Periods:          seed + 1,
+					ThrottledPeriods: seed + 2,
+					ThrottledTime:    seed + 3,
+				},
+			},
+			Memory: cadvisor.MemoryStats{
+				Usage:   seed + 4,
+				Failcnt: seed + 5,
+			},
+			Network: cadvisor.NetworkStats{
+				Interfaces: []cadvisor.InterfaceStats{
+					{Name: "eth0", RxBytes: seed + 6, TxBytes: seed + 7},
+					{Name: "eth1", RxBytes: seed + 8, TxBytes: seed + 9},
+				},
+			},
+			DiskIo: cadvisor.DiskIoStats{
+				IoServiceBytes: []cadvisor.PerDiskStats{
+					{Device: "/dev/sda", Stats: map[string]uint64{"Read": seed + 10, "Write": seed + 11}},
+				},
+			},
+		}},
+	}
+}
+
+func podLabels(container string) map[string]string {
+	return map[string]string{
+		kubernetesPodNameLabel:       pName0,
+		kubernetesPodNamespaceLabel:  namespace0,
+		kubernetesPodUIDLabel:        "uid0",
+		kubernetesContainerNameLabel: container,
+	}
+}
+
+func TestDecodeCadvisorContainers(t *testing.T) {
+	ms := testingCadvisorMetricsSource()
+	containers := []cadvisor.ContainerInfo{
+		testContainer("/", nil, seedNode),
+		testContainer("/system.slice/kubelet.service", nil, seedKubelet),
+		testContainer("/system.slice/sshd.service", nil, seedMisc),
+		testContainer("/kubepods/pod0/infra", podLabels(infraContainerName), seedPod0),
+		testContainer("/kubepods/pod0/c0", podLabels(cName00), seedPod0Container0),
+		{ContainerReference: cadvisor.ContainerReference{Name: "/kubepods/pod0/stale"}, Spec: cadvisor.ContainerSpec{Labels: podLabels(cName01)}},
+	}
+
+	metrics := ms.decodeContainers(containers)
+	require.Len(t, metrics, 4)
+
+	node := metrics[core.NodeKey(nodeInfo.NodeName)]
+	require.NotNil(t, node)
+	assert.Equal(t, core.MetricSetTypeNode, node.Labels[core.LabelMetricSetType.Key])
+	assert.Equal(t, int64(seedNode+6+seedNode+8), node.MetricValues[core.MetricNetworkRx.Name].IntValue)
+	assertLabeledValue(t, node.LabeledMetrics, core.MetricNetworkTx.Name, NetworkInterfaceKey, "eth1", seedNode+9)
+
+	sysContainer := metrics[core.NodeContainerKey(nodeInfo.NodeName, "kubelet")]
+	require.NotNil(t, sysContainer)
+	assert.Equal(t, core.MetricSetTypeSystemContainer, sysContainer.Labels[core.LabelMetricSetType.Key])
+	assert.Equal(t, "kubelet", sysContainer.Labels[core.LabelContainerName.Key])
+
+	pod := metrics[core.PodKey(namespace0, pName0)]
+	require.NotNil(t, pod)
+	assert.Equal(t, core.MetricSetTypePod, pod.Labels[core.LabelMetricSetType.Key])
+	assert.Equal(t, "uid0", pod.Labels[core.LabelPodId.Key])
+	assert.Equal(t, int64(seedPod0+7+seedPod0+9), pod.MetricValues[core.MetricNetworkTx.Name].IntValue)
+	assert.NotContains(t, pod.MetricValues, core.MetricCpuUsage.Name)
+	assertLabeledValue(t, pod.LabeledMetrics, core.MetricNetworkRx.Name, NetworkInterfaceKey, "eth0", seedPod0+6)
+
+	container := metrics[core.PodContainerKey(namespace0, pName0, cName00)]
+	require.NotNil(t, container)
+	assert.Equal(t, core.MetricSetTypePodContainer, container.Labels[core.LabelMetricSetType.Key])
+	assert.Equal(t, cName00, container.Labels[core.LabelContainerName.Key])
+	assert.Equal(t, namespace0, container.Labels[core.LabelNamespaceName.Key])
+	assert.Equal(t, nodeInfo.NodeName, container.Labels[core.LabelNodename.Key])
+	assert.Equal(t, scrapeTime, container.ScrapeTime)
+
+	values := map[string]int64{
+		core.MetricCpuUsage.Name:               seedPod0Container0,
+		core.MetricCpuCfsPeriods.Name:          seedPod0Container0 + 1,
+		core.MetricCpuCfsThrottledPeriods.Name: seedPod0Container0 + 2,
+		core.MetricCpuCfsThrottledTime.Name:    seedPod0Container0 + 3,
+		core.MetricMemoryUsage.Name:            seedPod0Container0 + 4,
+		core.MetricMemoryFailcnt.Name:          seedPod0Container0 + 5,
+	}
+	for name, value := range values {
+		assert.Equal(t, value, container.MetricValues[name].IntValue, name)
+	}
+	assert.NotContains(t, container.MetricValues, core.MetricNetworkRx.Name)
+	assertLabeledValue(t, container.LabeledMetrics, core.MetricDiskIORead.Name, core.LabelResourceID.Key, "/dev/sda", seedPod0Container0+10)
+	assertLabeledValue(t, container.LabeledMetrics, core.MetricDiskIOWrite.Name, core.LabelResourceID.Key, "/dev/sda", seedPod0Container0+11)
+}
+
+func TestDecodeCadvisorKeepsNewestContainer(t *testing.T) {
+	ms := testingCadvisorMetricsSource()
+	old := testContainer("/kubepods/pod0/old", podLabels(cName00), 1)
+	old.Spec.CreationTime = startTime.Add(-time.Hour)
+	current := testContainer("/kubepods/pod0/current", podLabels(cName00), 2)
+
+	for _, containers := range [][]cadvisor.ContainerInfo{{old, current}, {current, old}} {
+		metrics := ms.decodeContainers(containers)
+		require.Len(t, metrics, 1)
+		assert.Equal(t, int64(2), metrics[core.PodContainerKey(namespace0, pName0, cName00)].MetricValues[core.MetricCpuUsage.Name].IntValue)
+	}
+}
+
+func TestScrapeCadvisorMetrics(t *testing.T) {
+	containers := map[string]cadvisor.ContainerInfo{
+		"/": testContainer("/", nil, seedNode),
+	}
+	data, err := json.Marshal(&containers)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(&util.FakeHandler{
+		StatusCode:   200,
+		ResponseBody: string(data),
+		T:            t,
+	})
+	defer server.Close()
+
+	ms := testingCadvisorMetricsSource()
+	split := strings.SplitN(strings.Replace(server.URL, "http://", "", 1), ":", 2)
+	ms.node.IP = net.ParseIP(split[0])
+	ms.node.Port, err = strconv.Atoi(split[1])
+	require.NoError(t, err)
+
+	res, err := ms.ScrapeMetrics(context.Background())
+	assert.Nil(t, err, "scrape error")
+	assert.Equal(t, core.MetricSetTypeNode, res.MetricSets["node:test"].Labels[core.LabelMetricSetType.Key])
+}
+
+func assertLabeledValue(t *testing.T, metrics []core.LabeledMetric, name, label, labelValue string, value uint64) {
+	for _, metric := range metrics {
+		if metric.Name == name && metric.Labels[label] == labelValue {
+			assert.Equal(t, int64(value), metric.IntValue, name)
+			return
+		}
+	}
+	assert.Fail(t, "missing labeled metric", "%s{%s=%s}", name, label, labelValue)
+}
@@ -22,6 +22,7 @@ package kubelet
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -32,6 +33,8 @@ import (
 
 	cadvisor "github.com/google/cadvisor/info/v1"
 	"github.com/json-iterator/go"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	log "github.com/sirupsen/logrus"
 	stats "k8s.io/kubernetes/pkg/kubelet/apis/stats/v1alpha1"
 )
@@ -71,20 +74,20 @@ func sampleContainerStats(stats []*cadvisor.ContainerStats) []*cadvisor.Containe
 	return []*cadvisor.ContainerStats{stats[len(stats)-1]}
 }
 
-func (kc *KubeletClient) postRequestAndGetValue(client *http.Client, req *http.Request, value interface{}) error {
-	response, err := client.Do(req)
+func (kc *KubeletClient) doRequest(ctx context.Context, client *http.Client, req *http.Request) ([]byte, error) {
+	response, err := client.Do(req.WithContext(ctx))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer response.Body.Close()
 	body, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body - %v", err)
+		return nil, fmt.Errorf("failed to read response body - %v", err)
 	}
 	if response.StatusCode == http.StatusNotFound {
-		return &ErrNotFound{req.URL.String()}
+		return nil, &ErrNotFound{req.URL.String()}
 	} else if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("request failed - %q, response: %q", response.Status, string(body))
+		return nil, fmt.Errorf("request failed - %q, response: %q", response.Status, string(body))
 	}
 
 	kubeletAddr := "[unknown]"
@@ -97,6 +100,14 @@ func (kc *KubeletClient) postRequestAndGetValue(client *http.Client, req *http.R
 		"response": string(body),
 	}).Trace("Raw response from kubelet")
 
+	return body, nil
+}
+
+func (kc *KubeletClient) postRequestAndGetValue(ctx context.Context, client *http.Client, req *http.Request, value interface{}) error {
+	body, err := kc.doRequest(ctx, client, req)
+	if err != nil {
+		return err
+	}
 	err = jsoniter.ConfigFastest.Unmarshal(body, value)
 	if err != nil {
 		return fmt.Errorf("failed to parse output. Response: %q. Error: %v", string(body), err)
@@ -153,12 +164,12 @@ func (kc *KubeletClient) getUrl(host Host, path string) string {
 }
 
 // Get stats for all non-Kubernetes containers.
-func (kc *KubeletClient) GetAllRawContainers(host Host, start, end time.Time) ([]cadvisor.ContainerInfo, error) {
+func (kc *KubeletClient) GetAllRawContainers(ctx context.Context, host Host, start, end time.Time) ([]cadvisor.ContainerInfo, error) {
 	u := kc.getUrl(host, "/stats/container/")
-	return kc.getAllContainers(u, start, end)
+	return kc.getAllContainers(ctx, u, start, end)
 }
 
-func (kc *KubeletClient) GetSummary(host Host) (*stats.Summary, error) {
+func (kc *KubeletClient) GetSummary(ctx context.Context, host Host) (*stats.Summary, error) {
 	u := kc.getUrl(host, "/stats/summary/")
 
 	req, err := http.NewRequest("GET", u, nil)
@@ -170,15 +181,41 @@ func (kc *KubeletClient) GetSummary(host Host) (*stats.Summary, error) {
 	if client == nil {
 		client = http.DefaultClient
 	}
-	err = kc.postRequestAndGetValue(client, req, summary)
+	err = kc.postRequestAndGetValue(ctx, client, req, summary)
 	return summary, err
 }
 
+// GetMetrics retrieves and parses the prometheus metrics exposed by the kubelet under the given path,
+// such as /metrics/resource or /metrics/probes.
+func (kc *KubeletClient) GetMetrics(ctx context.Context, host Host, path string) (map[string]*dto.MetricFamily, error) {
+	u := kc.getUrl(host, path)
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(expfmt.FmtText))
+	client := kc.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, err := kc.doRequest(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metrics from Kubelet URL %q: %v", u, err)
+	}
+	return families, nil
+}
+
 func (kc *KubeletClient) GetPort() int {
 	return int(kc.config.Port)
 }
 
-func (kc *KubeletClient) getAllContainers(url string, start, end time.Time) ([]cadvisor.ContainerInfo, error) {
+func (kc *KubeletClient) getAllContainers(ctx context.Context, url string, start, end time.Time) ([]cadvisor.ContainerInfo, error) {
 	// Request data from all subcontainers.
 	request := statsRequest{
 		ContainerName: "/",
@@ -202,7 +239,7 @@ func (kc *KubeletClient) getAllContainers(url string, start, end time.Time) ([]c
 	if client == nil {
 		client = http.DefaultClient
 	}
-	err = kc.postRequestAndGetValue(client, req, &containers)
+	err = kc.postRequestAndGetValue(ctx, client, req, &containers)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all container stats from Kubelet URL %q: %v", url, err)
 	}
@@ -15,7 +15,12 @@
 package kubelet
 
 import (
+	"context"
+	"net"
+	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
@@ -96,9 +101,73 @@ func TestAllContainers(t *testing.T) {
 	server := httptest.NewServer(&handler)
 	defer server.Close()
 	kubeletClient := KubeletClient{}
-	containers, err := kubeletClient.getAllContainers(server.URL, time.Now(), time.Now().Add(time.Minute))
+	containers, err := kubeletClient.getAllContainers(context.Background(), server.URL, time.Now(), time.Now().Add(time.Minute))
 	require.NoError(t, err)
 	require.Len(t, containers, 2)
 	checkContainer(t, rootContainer, containers[0])
 	checkContainer(t, subcontainer, containers[1])
 }
+
+func testHost(t *testing.T, serverURL string) Host {
+	u, err := url.Parse(serverURL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	return Host{IP: net.ParseIP(u.Hostname()), Port: port}
+}
+
+func TestGetMetrics(t *testing.T) {
+	handler := util.FakeHandler{
+		StatusCode: 200,
+		ResponseBody: `# TYPE node_memory_working_set_bytes gauge
+node_memory_working_set_bytes 1.024e+06 1568023050000
+`,
+		T: t,
+	}
+	server := httptest.NewServer(&handler)
+	defer server.Close()
+
+	kubeletClient := KubeletClient{}
+	families, err := kubeletClient.GetMetrics(context.Background(), testHost(t, server.URL), "/metrics/resource")
+	require.NoError(t, err)
+	handler.ValidateRequest(t, "/metrics/resource", "GET", nil)
+
+	family := families["node_memory_working_set_bytes"]
+	require.NotNil(t, family)
+	require.Len(t, family.Metric, 1)
+	assert.Equal(t, 1.024e+06, family.Metric[0].GetGauge().GetValue())
+	assert.Equal(t, int64(1568023050000), family.Metric[0].GetTimestampMs())
+}
+
+func TestGetMetricsNotFound(t *testing.T) {
+	handler := util.FakeHandler{
+		StatusCode:   404,
+		ResponseBody: "404 page not found",
+		T:            t,
+	}
+	server := httptest.NewServer(&handler)
+	defer server.Close()
+
+	kubeletClient := KubeletClient{}
+	_, err := kubeletClient.GetMetrics(context.Background(), testHost(t, server.URL), "/metrics/probes")
+	require.Error(t, err)
+	assert.True(t, IsNotFoundError(err))
+}
+
+func TestGetSummaryCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	kubeletClient := KubeletClient{}
+	start := time.Now()
+	_, err := kubeletClient.GetSummary(ctx, testHost(t, server.URL))
+	require.Error(t, err)
+	assert.True(t, time.Since(start) < time.Second, "request not cancelled")
+}
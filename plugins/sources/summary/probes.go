@@ -0,0 +1,148 @@
+package summary
+
+import (
+	"context"
+
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+
+	. "github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources/summary/kubelet"
+)
+
+const (
+	probesPath = "/metrics/probes"
+
+	proberProbeTotal    = "prober_probe_total"
+	proberProbeDuration = "prober_probe_duration_seconds"
+)
+
+// probesMetricsSource wraps a kubelet metrics source and adds the container probe metrics
+// from the kubelet's /metrics/probes endpoint to the pod container metric sets.
+type probesMetricsSource struct {
+	MetricsSource
+	node          NodeInfo
+	kubeletClient *kubelet.KubeletClient
+}
+
+func NewProbesMetricsSource(delegate MetricsSource, node NodeInfo, client *kubelet.KubeletClient) MetricsSource {
+	return &probesMetricsSource{
+		MetricsSource: delegate,
+		node:          node,
+		kubeletClient: client,
+	}
+}
+
+func (src *probesMetricsSource) ScrapeMetrics(ctx context.Context) (*DataBatch, error) {
+	result, err := src.MetricsSource.ScrapeMetrics(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	families, err := src.kubeletClient.GetMetrics(ctx, src.node.Host, probesPath)
+	if err != nil {
+		// older kubelets do not expose the probes endpoint, the remaining metrics are still reported
+		if kubelet.IsNotFoundError(err) {
+			log.Debugf("probe metrics not available on node %s: %v", src.node.NodeName, err)
+		} else {
+			collectErrors.Inc(1)
+			log.Errorf("error collecting probe metrics from node %s: %v", src.node.NodeName, err)
+		}
+		return result, nil
+	}
+
+	if result.MetricSets == nil {
+		result.MetricSets = map[string]*MetricSet{}
+	}
+	src.decodeProbes(result.MetricSets, families)
+	return result, nil
+}
+
+// decodeProbes adds the probe outcomes and durations as labeled metrics of the pod containers.
+func (src *probesMetricsSource) decodeProbes(metricSets map[string]*MetricSet, families map[string]*dto.MetricFamily) {
+	if family, found := families[proberProbeTotal]; found {
+		for _, m := range family.Metric {
+			labels := promLabels(m)
+			ms := src.containerMetricSet(metricSets, labels)
+			if ms == nil {
+				continue
+			}
+			ms.LabeledMetrics = append(ms.LabeledMetrics, LabeledMetric{
+				Name: MetricProbeTotal.Name,
+				Labels: map[string]string{
+					LabelProbeType.Key:   labels["probe_type"],
+					LabelProbeResult.Key: labels["result"],
+				},
+				MetricValue: MetricValue{
+					ValueType:  ValueInt64,
+					MetricType: MetricProbeTotal.Type,
+					IntValue:   int64(sampleValue(m)),
+				},
+			})
+		}
+	}
+
+	if family, found := families[proberProbeDuration]; found {
+		for _, m := range family.Metric {
+			if m.Histogram == nil {
+				continue
+			}
+			labels := promLabels(m)
+			ms := src.containerMetricSet(metricSets, labels)
+			if ms == nil {
+				continue
+			}
+			probeLabels := map[string]string{LabelProbeType.Key: labels["probe_type"]}
+			ms.LabeledMetrics = append(ms.LabeledMetrics,
+				LabeledMetric{
+					Name:   MetricProbeDurationSum.Name,
+					Labels: probeLabels,
+					MetricValue: MetricValue{
+						ValueType:  ValueFloat,
+						MetricType: MetricProbeDurationSum.Type,
+						FloatValue: m.Histogram.GetSampleSum(),
+					},
+				},
+				LabeledMetric{
+					Name:   MetricProbeDurationCount.Name,
+					Labels: probeLabels,
+					MetricValue: MetricValue{
+						ValueType:  ValueInt64,
+						MetricType: MetricProbeDurationCount.Type,
+						IntValue:   int64(m.Histogram.GetSampleCount()),
+					},
+				})
+		}
+	}
+}
+
+// containerMetricSet returns the metric set of the probed container, creating it if the
+// container was not reported by the wrapped source.
+func (src *probesMetricsSource) containerMetricSet(metricSets map[string]*MetricSet, labels map[string]string) *MetricSet {
+	ns, pod, container := labels["namespace"], labels["pod"], labels["container"]
+	if ns == "" || pod == "" || container == "" {
+		return nil
+	}
+	key := PodContainerKey(ns, pod, container)
+	if ms, found := metricSets[key]; found {
+		return ms
+	}
+	ms := &MetricSet{
+		Labels: map[string]string{
+			LabelMetricSetType.Key: MetricSetTypePodContainer,
+			LabelNodename.Key:      src.node.NodeName,
+			LabelHostname.Key:      src.node.HostName,
+			LabelHostID.Key:        src.node.HostID,
+			LabelNamespaceName.Key: ns,
+			LabelPodName.Key:       pod,
+			LabelContainerName.Key: container,
+		},
+		MetricValues:   map[string]MetricValue{},
+		LabeledMetrics: []LabeledMetric{},
+	}
+	if uid, found := labels["pod_uid"]; found {
+		ms.Labels[LabelPodId.Key] = uid
+	}
+	metricSets[key] = ms
+	return ms
+}
@@ -0,0 +1,132 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources/summary/kubelet"
+	util "k8s.io/client-go/util/testing"
+)
+
+const probeMetrics = `# TYPE prober_probe_duration_seconds histogram
+prober_probe_duration_seconds_bucket{container="c0",namespace="test0",pod="pod0",probe_type="Liveness",le="0.1"} 9
+prober_probe_duration_seconds_bucket{container="c0",namespace="test0",pod="pod0",probe_type="Liveness",le="+Inf"} 10
+prober_probe_duration_seconds_sum{container="c0",namespace="test0",pod="pod0",probe_type="Liveness"} 0.75
+prober_probe_duration_seconds_count{container="c0",namespace="test0",pod="pod0",probe_type="Liveness"} 10
+# TYPE prober_probe_total counter
+prober_probe_total{container="c0",namespace="test0",pod="pod0",pod_uid="uid0",probe_type="Liveness",result="failed"} 3
+prober_probe_total{container="c0",namespace="test0",pod="pod0",pod_uid="uid0",probe_type="Liveness",result="successful"} 7
+prober_probe_total{container="c1",namespace="test1",pod="pod1",pod_uid="uid1",probe_type="Readiness",result="successful"} 5
+`
+
+type fakeMetricsSource struct {
+	batch *core.DataBatch
+	err   error
+}
+
+func (src *fakeMetricsSource) Name() string {
+	return "fake"
+}
+
+func (src *fakeMetricsSource) ScrapeMetrics(context.Context) (*core.DataBatch, error) {
+	return src.batch, src.err
+}
+
+func testingProbesMetricsSource(t *testing.T, delegate core.MetricsSource, handler *util.FakeHandler) (*probesMetricsSource, func()) {
+	server := httptest.NewServer(handler)
+	node := nodeInfo
+	split := strings.SplitN(strings.Replace(server.URL, "http://", "", 1), ":", 2)
+	node.IP = net.ParseIP(split[0])
+	port, err := strconv.Atoi(split[1])
+	require.NoError(t, err)
+	node.Port = port
+
+	src := NewProbesMetricsSource(delegate, node, &kubelet.KubeletClient{}).(*probesMetricsSource)
+	return src, server.Close
+}
+
+func TestScrapeProbeMetrics(t *testing.T) {
+	containerKey := core.PodContainerKey(namespace0, pName0, cName00)
+	delegate := &fakeMetricsSource{batch: &core.DataBatch{
+		MetricSets: map[string]*core.MetricSet{
+			containerKey: {
+				Labels:       map[string]string{core.LabelContainerName.Key: cName00},
+				MetricValues: map[string]core.MetricValue{core.MetricCpuUsage.Name: {IntValue: 1}},
+			},
+		},
+	}}
+	src, closer := testingProbesMetricsSource(t, delegate, &util.FakeHandler{
+		StatusCode:   200,
+		ResponseBody: probeMetrics,
+		T:            t,
+	})
+	defer closer()
+
+	batch, err := src.ScrapeMetrics(context.Background())
+	require.NoError(t, err)
+	require.Len(t, batch.MetricSets, 2)
+
+	container := batch.MetricSets[containerKey]
+	assert.Equal(t, int64(1), container.MetricValues[core.MetricCpuUsage.Name].IntValue)
+	assert.Len(t, container.LabeledMetrics, 4)
+	assertProbeValue(t, container.LabeledMetrics, core.MetricProbeTotal.Name, "Liveness", "failed", 3)
+	assertProbeValue(t, container.LabeledMetrics, core.MetricProbeTotal.Name, "Liveness", "successful", 7)
+	assertProbeValue(t, container.LabeledMetrics, core.MetricProbeDurationSum.Name, "Liveness", "", 0.75)
+	assertProbeValue(t, container.LabeledMetrics, core.MetricProbeDurationCount.Name, "Liveness", "", 10)
+
+	// containers not reported by the wrapped source get their own metric set
+	other := batch.MetricSets[core.PodContainerKey(namespace1, "pod1", cName01)]
+	require.NotNil(t, other)
+	assert.Equal(t, core.MetricSetTypePodContainer, other.Labels[core.LabelMetricSetType.Key])
+	assert.Equal(t, "uid1", other.Labels[core.LabelPodId.Key])
+	assert.Equal(t, nodeInfo.NodeName, other.Labels[core.LabelNodename.Key])
+	assertProbeValue(t, other.LabeledMetrics, core.MetricProbeTotal.Name, "Readiness", "successful", 5)
+}
+
+func TestScrapeProbeMetricsNotAvailable(t *testing.T) {
+	batch := &core.DataBatch{MetricSets: map[string]*core.MetricSet{}}
+	src, closer := testingProbesMetricsSource(t, &fakeMetricsSource{batch: batch}, &util.FakeHandler{
+		StatusCode:   404,
+		ResponseBody: "404 page not found",
+		T:            t,
+	})
+	defer closer()
+
+	result, err := src.ScrapeMetrics(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, batch, result)
+}
+
+func TestScrapeProbeMetricsSourceError(t *testing.T) {
+	src, closer := testingProbesMetricsSource(t, &fakeMetricsSource{err: errors.New("scrape failed")}, &util.FakeHandler{
+		StatusCode:   200,
+		ResponseBody: probeMetrics,
+		T:            t,
+	})
+	defer closer()
+
+	_, err := src.ScrapeMetrics(context.Background())
+	assert.Error(t, err)
+}
+
+func assertProbeValue(t *testing.T, metrics []core.LabeledMetric, name, probeType, result string, value float64) {
+	for _, metric := range metrics {
+		if metric.Name == name && metric.Labels[core.LabelProbeType.Key] == probeType && metric.Labels[core.LabelProbeResult.Key] == result {
+			if metric.ValueType == core.ValueFloat {
+				assert.Equal(t, value, metric.FloatValue, name)
+			} else {
+				assert.Equal(t, int64(value), metric.IntValue, name)
+			}
+			return
+		}
+	}
+	assert.Fail(t, "missing probe metric", "%s{probe_type=%s,result=%s}", name, probeType, result)
+}
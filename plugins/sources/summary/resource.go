@@ -0,0 +1,163 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+
+	. "github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources/summary/kubelet"
+)
+
+const resourcePath = "/metrics/resource"
+
+// Kubelet-provided resource metrics for the node, its pods and containers. The /metrics/resource
+// endpoint is a lightweight alternative to the Summary API that only reports CPU and memory usage.
+type resourceMetricsSource struct {
+	node          NodeInfo
+	kubeletClient *kubelet.KubeletClient
+}
+
+func NewResourceMetricsSource(node NodeInfo, client *kubelet.KubeletClient) MetricsSource {
+	return &resourceMetricsSource{
+		node:          node,
+		kubeletClient: client,
+	}
+}
+
+func (src *resourceMetricsSource) Name() string {
+	return src.String()
+}
+
+func (src *resourceMetricsSource) String() string {
+	return fmt.Sprintf("kubelet_resource:%s:%d", src.node.IP, src.node.Port)
+}
+
+func (src *resourceMetricsSource) ScrapeMetrics(ctx context.Context) (*DataBatch, error) {
+	result := &DataBatch{
+		Timestamp: time.Now(),
+	}
+
+	families, err := src.kubeletClient.GetMetrics(ctx, src.node.Host, resourcePath)
+	if err != nil {
+		collectErrors.Inc(1)
+		return nil, err
+	}
+
+	result.MetricSets = src.decodeResourceMetrics(families, result.Timestamp)
+	return result, nil
+}
+
+// decodeResourceMetrics translates the kubelet resource metrics into the flattened MetricSet API.
+func (src *resourceMetricsSource) decodeResourceMetrics(families map[string]*dto.MetricFamily, now time.Time) map[string]*MetricSet {
+	result := map[string]*MetricSet{}
+
+	for name, family := range families {
+		for _, m := range family.Metric {
+			ms := src.metricSet(result, name, promLabels(m))
+			if ms == nil {
+				continue
+			}
+			if m.TimestampMs != nil {
+				ts := time.Unix(0, m.GetTimestampMs()*int64(time.Millisecond))
+				if ts.After(ms.ScrapeTime) {
+					ms.ScrapeTime = ts
+				}
+			}
+
+			value := sampleValue(m)
+			switch name {
+			case "node_cpu_usage_seconds_total", "pod_cpu_usage_seconds_total", "container_cpu_usage_seconds_total":
+				src.addIntMetric(ms, &MetricCpuUsage, int64(value*float64(time.Second)))
+			case "node_memory_working_set_bytes", "pod_memory_working_set_bytes", "container_memory_working_set_bytes":
+				src.addIntMetric(ms, &MetricMemoryWorkingSet, int64(value))
+			case "container_start_time_seconds":
+				startTime := time.Unix(0, int64(value*float64(time.Second)))
+				ms.CollectionStartTime = startTime
+				src.addIntMetric(ms, &MetricUptime, int64(now.Sub(startTime)/time.Millisecond))
+			}
+		}
+	}
+
+	for _, ms := range result {
+		if ms.ScrapeTime.IsZero() {
+			ms.ScrapeTime = now
+		}
+	}
+	log.Debugf("End resource metrics decode")
+	return result
+}
+
+// metricSet returns the node, pod or container metric set of a resource metric, creating it if needed.
+// Returns nil for metrics that are not reported per node, pod or container.
+func (src *resourceMetricsSource) metricSet(result map[string]*MetricSet, name string, labels map[string]string) *MetricSet {
+	var key, setType string
+	ns, pod, container := labels["namespace"], labels["pod"], labels["container"]
+	switch name {
+	case "node_cpu_usage_seconds_total", "node_memory_working_set_bytes":
+		key, setType = NodeKey(src.node.NodeName), MetricSetTypeNode
+	case "pod_cpu_usage_seconds_total", "pod_memory_working_set_bytes":
+		key, setType = PodKey(ns, pod), MetricSetTypePod
+	case "container_cpu_usage_seconds_total", "container_memory_working_set_bytes", "container_start_time_seconds":
+		key, setType = PodContainerKey(ns, pod, container), MetricSetTypePodContainer
+	default:
+		return nil
+	}
+
+	if ms, found := result[key]; found {
+		return ms
+	}
+	ms := &MetricSet{
+		Labels: map[string]string{
+			LabelMetricSetType.Key: setType,
+			LabelNodename.Key:      src.node.NodeName,
+			LabelHostname.Key:      src.node.HostName,
+			LabelHostID.Key:        src.node.HostID,
+		},
+		MetricValues:   map[string]MetricValue{},
+		LabeledMetrics: []LabeledMetric{},
+	}
+	if setType != MetricSetTypeNode {
+		ms.Labels[LabelNamespaceName.Key] = ns
+		ms.Labels[LabelPodName.Key] = pod
+	}
+	if setType == MetricSetTypePodContainer {
+		ms.Labels[LabelContainerName.Key] = container
+	}
+	result[key] = ms
+	return ms
+}
+
+func (src *resourceMetricsSource) addIntMetric(metrics *MetricSet, metric *Metric, value int64) {
+	metrics.MetricValues[metric.Name] = MetricValue{
+		ValueType:  ValueInt64,
+		MetricType: metric.Type,
+		IntValue:   value,
+	}
+}
+
+// promLabels returns the labels of a prometheus metric as a map.
+func promLabels(m *dto.Metric) map[string]string {
+	labels := make(map[string]string, len(m.Label))
+	for _, label := range m.Label {
+		labels[label.GetName()] = label.GetValue()
+	}
+	return labels
+}
+
+// sampleValue returns the value of a counter, gauge or untyped prometheus metric.
+func sampleValue(m *dto.Metric) float64 {
+	switch {
+	case m.Counter != nil:
+		return m.Counter.GetValue()
+	case m.Gauge != nil:
+		return m.Gauge.GetValue()
+	case m.Untyped != nil:
+		return m.Untyped.GetValue()
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,71 @@
+package summary
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/plugins/sources/summary/kubelet"
+)
+
+const resourceMetrics = `# TYPE container_cpu_usage_seconds_total counter
+container_cpu_usage_seconds_total{container="c0",namespace="test0",pod="pod0"} 1.5 1568023050000
+# TYPE container_memory_working_set_bytes gauge
+container_memory_working_set_bytes{container="c0",namespace="test0",pod="pod0"} 2048 1568023050000
+# TYPE container_start_time_seconds gauge
+container_start_time_seconds{container="c0",namespace="test0",pod="pod0"} 1.568023e+09
+# TYPE node_cpu_usage_seconds_total counter
+node_cpu_usage_seconds_total 20.25 1568023051000
+# TYPE node_memory_working_set_bytes gauge
+node_memory_working_set_bytes 4096 1568023051000
+# TYPE pod_memory_working_set_bytes gauge
+pod_memory_working_set_bytes{namespace="test0",pod="pod0"} 3072 1568023050000
+# TYPE scrape_error gauge
+scrape_error 0
+`
+
+func parseTestMetrics(t *testing.T, text string) map[string]*dto.MetricFamily {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader([]byte(text)))
+	require.NoError(t, err)
+	return families
+}
+
+func TestDecodeResourceMetrics(t *testing.T) {
+	src := &resourceMetricsSource{
+		node:          nodeInfo,
+		kubeletClient: &kubelet.KubeletClient{},
+	}
+	now := time.Unix(1568023060, 0)
+	metrics := src.decodeResourceMetrics(parseTestMetrics(t, resourceMetrics), now)
+	require.Len(t, metrics, 3)
+
+	node := metrics[core.NodeKey(nodeInfo.NodeName)]
+	require.NotNil(t, node)
+	assert.Equal(t, core.MetricSetTypeNode, node.Labels[core.LabelMetricSetType.Key])
+	assert.Equal(t, int64(20250000000), node.MetricValues[core.MetricCpuUsage.Name].IntValue)
+	assert.Equal(t, int64(4096), node.MetricValues[core.MetricMemoryWorkingSet.Name].IntValue)
+	assert.Equal(t, time.Unix(1568023051, 0), node.ScrapeTime)
+
+	pod := metrics[core.PodKey(namespace0, pName0)]
+	require.NotNil(t, pod)
+	assert.Equal(t, core.MetricSetTypePod, pod.Labels[core.LabelMetricSetType.Key])
+	assert.Equal(t, pName0, pod.Labels[core.LabelPodName.Key])
+	assert.Equal(t, int64(3072), pod.MetricValues[core.MetricMemoryWorkingSet.Name].IntValue)
+
+	container := metrics[core.PodContainerKey(namespace0, pName0, cName00)]
+	require.NotNil(t, container)
+	assert.Equal(t, core.MetricSetTypePodContainer, container.Labels[core.LabelMetricSetType.Key])
+	assert.Equal(t, cName00, container.Labels[core.LabelContainerName.Key])
+	assert.Equal(t, nodeInfo.NodeName, container.Labels[core.LabelNodename.Key])
+	assert.Equal(t, int64(1500000000), container.MetricValues[core.MetricCpuUsage.Name].IntValue)
+	assert.Equal(t, int64(2048), container.MetricValues[core.MetricMemoryWorkingSet.Name].IntValue)
+	assert.Equal(t, int64(60000), container.MetricValues[core.MetricUptime.Name].IntValue)
+	assert.Equal(t, time.Unix(1568023000, 0), container.CollectionStartTime)
+	assert.Equal(t, time.Unix(1568023050, 0), container.ScrapeTime)
+}
@@ -18,6 +18,7 @@
 package summary
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -42,6 +43,13 @@ import (
 // Prefix used for the LabelResourceID for volume metrics.
 const VolumeResourcePrefix = "Volume:"
 
+// Modes of the kubernetes source, selecting the kubelet endpoint the stats are collected from.
+const (
+	SummaryMode  = "summary"
+	CadvisorMode = "cadvisor"
+	ResourceMode = "resource"
+)
+
 var collectErrors gm.Counter
 
 func init() {
@@ -78,13 +86,13 @@ func (src *summaryMetricsSource) String() string {
 	return fmt.Sprintf("kubelet_summary:%s:%d", src.node.IP, src.node.Port)
 }
 
-func (src *summaryMetricsSource) ScrapeMetrics() (*DataBatch, error) {
+func (src *summaryMetricsSource) ScrapeMetrics(ctx context.Context) (*DataBatch, error) {
 	result := &DataBatch{
 		Timestamp: time.Now(),
 	}
 
 	summary, err := func() (*stats.Summary, error) {
-		return src.kubeletClient.GetSummary(src.node.Host)
+		return src.kubeletClient.GetSummary(ctx, src.node.Host)
 	}()
 
 	if err != nil {
@@ -154,6 +162,7 @@ func (src *summaryMetricsSource) decodeNodeStats(metrics map[string]*MetricSet,
 	src.decodeNetworkStats(nodeMetrics, node.Network)
 	src.decodeFsStats(nodeMetrics, RootFsKey, node.Fs)
 	src.decodeEphemeralStorageStats(nodeMetrics, node.Fs)
+	src.decodeRlimitStats(nodeMetrics, node.Rlimit)
 	metrics[NodeKey(node.NodeName)] = nodeMetrics
 
 	for _, container := range node.SystemContainers {
@@ -184,8 +193,9 @@ func (src *summaryMetricsSource) decodePodStats(metrics map[string]*MetricSet, n
 	src.decodeCPUStats(podMetrics, pod.CPU)
 	src.decodeMemoryStats(podMetrics, pod.Memory)
 	src.decodeEphemeralStorageStats(podMetrics, pod.EphemeralStorage)
-	for _, vol := range pod.VolumeStats {
-		src.decodeFsStats(podMetrics, VolumeResourcePrefix+vol.Name, &vol.FsStats)
+	src.decodeProcessStats(podMetrics, pod.ProcessStats)
+	for i := range pod.VolumeStats {
+		src.decodeVolumeStats(podMetrics, &pod.VolumeStats[i])
 	}
 	metrics[PodKey(ref.Namespace, ref.Name)] = podMetrics
 
@@ -268,6 +278,23 @@ func (src *summaryMetricsSource) decodeEphemeralStorageStatsForContainer(metrics
 	src.addIntMetric(metrics, &MetricEphemeralStorageUsage, &usage)
 }
 
+func (src *summaryMetricsSource) decodeProcessStats(metrics *MetricSet, process *stats.ProcessStats) {
+	if process == nil {
+		log.Trace("missing process metrics!")
+		return
+	}
+	src.addIntMetric(metrics, &MetricProcessCount, process.ProcessCount)
+}
+
+func (src *summaryMetricsSource) decodeRlimitStats(metrics *MetricSet, rlimit *stats.RlimitStats) {
+	if rlimit == nil {
+		log.Trace("missing rlimit metrics!")
+		return
+	}
+	src.addInt64Metric(metrics, &MetricProcessRunning, rlimit.NumOfRunningProcesses)
+	src.addInt64Metric(metrics, &MetricProcessMaxPid, rlimit.MaxPID)
+}
+
 func (src *summaryMetricsSource) decodeMemoryStats(metrics *MetricSet, memory *stats.MemoryStats) {
 	if memory == nil {
 		log.Trace("missing memory metrics!")
@@ -321,6 +348,22 @@ func (src *summaryMetricsSource) decodeFsStats(metrics *MetricSet, fsKey string,
 	}
 
 	fsLabels := map[string]string{LabelResourceID.Key: fsKey}
+	src.addFsMetrics(metrics, fsLabels, fs)
+}
+
+// decodeVolumeStats adds the filesystem metrics of a pod volume, labeled with the claim if the volume is a PVC.
+func (src *summaryMetricsSource) decodeVolumeStats(metrics *MetricSet, vol *stats.VolumeStats) {
+	volLabels := map[string]string{
+		LabelResourceID.Key: VolumeResourcePrefix + vol.Name,
+		LabelVolumeName.Key: vol.Name,
+	}
+	if vol.PVCRef != nil {
+		volLabels[LabelPVCName.Key] = vol.PVCRef.Name
+	}
+	src.addFsMetrics(metrics, volLabels, &vol.FsStats)
+}
+
+func (src *summaryMetricsSource) addFsMetrics(metrics *MetricSet, fsLabels map[string]string, fs *stats.FsStats) {
 	src.addLabeledIntMetric(metrics, &MetricFilesystemUsage, fsLabels, fs.UsedBytes)
 	src.addLabeledIntMetric(metrics, &MetricFilesystemLimit, fsLabels, fs.CapacityBytes)
 	src.addLabeledIntMetric(metrics, &MetricFilesystemAvailable, fsLabels, fs.AvailableBytes)
@@ -379,6 +422,19 @@ func (src *summaryMetricsSource) addIntMetric(metrics *MetricSet, metric *Metric
 	metrics.MetricValues[metric.Name] = val
 }
 
+// addInt64Metric is the same as addIntMetric for the signed values of the summary API.
+func (src *summaryMetricsSource) addInt64Metric(metrics *MetricSet, metric *Metric, value *int64) {
+	if value == nil {
+		log.Debugf("skipping metric %s because the value was nil", metric.Name)
+		return
+	}
+	metrics.MetricValues[metric.Name] = MetricValue{
+		ValueType:  ValueInt64,
+		MetricType: metric.Type,
+		IntValue:   *value,
+	}
+}
+
 // addLabeledIntMetric is a convenience method for adding the labeled metric and value to the metric set.
 func (src *summaryMetricsSource) addLabeledIntMetric(metrics *MetricSet, metric *Metric, labels map[string]string, value *uint64) {
 	if value == nil {
@@ -412,6 +468,8 @@ type summaryProvider struct {
 	reflector        *cache.Reflector
 	kubeletClient    *kubelet.KubeletClient
 	hostIDAnnotation string
+	mode             string
+	probes           bool
 }
 
 func (sp *summaryProvider) GetMetricsSources() []MetricsSource {
@@ -428,7 +486,19 @@ func (sp *summaryProvider) GetMetricsSources() []MetricsSource {
 			log.Errorf("%v", err)
 			continue
 		}
-		sources = append(sources, NewSummaryMetricsSource(info, sp.kubeletClient))
+		var source MetricsSource
+		switch sp.mode {
+		case CadvisorMode:
+			source = NewCadvisorMetricsSource(info, sp.kubeletClient)
+		case ResourceMode:
+			source = NewResourceMetricsSource(info, sp.kubeletClient)
+		default:
+			source = NewSummaryMetricsSource(info, sp.kubeletClient)
+		}
+		if sp.probes {
+			source = NewProbesMetricsSource(source, info, sp.kubeletClient)
+		}
+		sources = append(sources, source)
 	}
 	return sources
 }
@@ -474,6 +544,11 @@ func (sp *summaryProvider) getNodeInfo(node *kube_api.Node) (NodeInfo, error) {
 func NewSummaryProvider(cfg configuration.SummaySourceConfig) (MetricsSourceProvider, error) {
 	hostIDAnnotation := ""
 
+	mode := configuration.GetStringValue(cfg.Mode, SummaryMode)
+	if mode != SummaryMode && mode != CadvisorMode && mode != ResourceMode {
+		return nil, fmt.Errorf("invalid kubernetes_source mode: %s", mode)
+	}
+
 	// create clients
 	kubeConfig, kubeletConfig, err := kubelet.GetKubeConfigs(cfg)
 	if err != nil {
@@ -492,5 +567,7 @@ func NewSummaryProvider(cfg configuration.SummaySourceConfig) (MetricsSourceProv
 		reflector:        reflector,
 		kubeletClient:    kubeletClient,
 		hostIDAnnotation: hostIDAnnotation,
+		mode:             mode,
+		probes:           cfg.Probes,
 	}, nil
 }
@@ -15,6 +15,7 @@
 package summary
 
 import (
+	"context"
 	"encoding/json"
 	"net"
 	"net/http/httptest"
@@ -119,7 +120,7 @@ type fakeSource struct {
 }
 
 func (f *fakeSource) Name() string { return "fake" }
-func (f *fakeSource) ScrapeMetrics() (*core.DataBatch, error) {
+func (f *fakeSource) ScrapeMetrics(context.Context) (*core.DataBatch, error) {
 	f.scraped = true
 	return nil, nil
 }
@@ -200,7 +201,8 @@ func TestDecodeSummaryMetrics(t *testing.T) {
 				genTestSummaryContainer(cName30, seedPod3Container0),
 			},
 			VolumeStats: []stats.VolumeStats{{
-				Name: "C",
+				Name:   "C",
+				PVCRef: &stats.PVCReference{Name: "claim-c", Namespace: namespace0},
 				FsStats: stats.FsStats{
 					AvailableBytes: &availableFsBytes,
 					UsedBytes:      &usedFsBytes,
@@ -411,12 +413,16 @@ func TestDecodeSummaryMetrics(t *testing.T) {
 	var mappedVolumeStats = map[string]int64{}
 	for _, labeledMetric := range metrics[volumeInformationMetricsKey].LabeledMetrics {
 		assert.True(t, strings.HasPrefix("Volume:C", labeledMetric.Labels["resource_id"]))
+		assert.Equal(t, "C", labeledMetric.Labels[core.LabelVolumeName.Key])
+		assert.Equal(t, "claim-c", labeledMetric.Labels[core.LabelPVCName.Key])
 		mappedVolumeStats[labeledMetric.Name] = labeledMetric.IntValue
 	}
 
 	assert.True(t, mappedVolumeStats["filesystem/available"] == int64(availableFsBytes))
 	assert.True(t, mappedVolumeStats["filesystem/usage"] == int64(usedFsBytes))
 	assert.True(t, mappedVolumeStats["filesystem/limit"] == int64(totalFsBytes))
+	assert.True(t, mappedVolumeStats["filesystem/inodes"] == int64(totalInode))
+	assert.True(t, mappedVolumeStats["filesystem/inodes_free"] == int64(freeInode))
 
 	delete(metrics, volumeInformationMetricsKey)
 
@@ -425,6 +431,36 @@ func TestDecodeSummaryMetrics(t *testing.T) {
 	}
 }
 
+func TestDecodeProcessStats(t *testing.T) {
+	ms := testingSummaryMetricsSource()
+	maxPID, runningProcesses := int64(32768), int64(1200)
+	processCount := uint64(12)
+	summary := stats.Summary{
+		Node: stats.NodeStats{
+			NodeName:  nodeInfo.NodeName,
+			StartTime: metav1.NewTime(startTime),
+			Rlimit: &stats.RlimitStats{
+				MaxPID:                &maxPID,
+				NumOfRunningProcesses: &runningProcesses,
+			},
+		},
+		Pods: []stats.PodStats{{
+			PodRef: stats.PodReference{
+				Name:      pName0,
+				Namespace: namespace0,
+			},
+			StartTime:    metav1.NewTime(startTime),
+			ProcessStats: &stats.ProcessStats{ProcessCount: &processCount},
+		}},
+	}
+
+	metrics := ms.decodeSummary(&summary)
+	node := metrics[core.NodeKey(nodeInfo.NodeName)]
+	checkIntMetric(t, node, "node", core.MetricProcessMaxPid, maxPID)
+	checkIntMetric(t, node, "node", core.MetricProcessRunning, runningProcesses)
+	checkIntMetric(t, metrics[core.PodKey(namespace0, pName0)], "pod", core.MetricProcessCount, int64(processCount))
+}
+
 func genTestSummaryTerminatedContainer(name string, seed int) stats.ContainerStats {
 	return stats.ContainerStats{
 		Name:      name,
@@ -616,7 +652,7 @@ func TestScrapeSummaryMetrics(t *testing.T) {
 	ms.node.Port, err = strconv.Atoi(split[1])
 	require.NoError(t, err)
 
-	res, err := ms.ScrapeMetrics()
+	res, err := ms.ScrapeMetrics(context.Background())
 	assert.Nil(t, err, "scrape error")
 	assert.Equal(t, res.MetricSets["node:test"].Labels[core.LabelMetricSetType.Key], core.MetricSetTypeNode)
 }
@@ -82,10 +82,10 @@ func (converter *pointConverter) Process(batch *metrics.DataBatch) (*metrics.Dat
 				continue
 			}
 
-			ts := ts.Unix()
+			ts := metrics.UnixMillis(ts)
 			source := nodeName
 			if source == "" {
-				if metricType == "cluster" {
+				if metricType == "cluster" || metricType == "workload" || metricType == "group" {
 					source = converter.cluster
 				} else if metricType == "ns" {
 					source = tags["namespace_name"] + "-ns"
@@ -110,7 +110,7 @@ func (converter *pointConverter) Process(batch *metrics.DataBatch) (*metrics.Dat
 				continue
 			}
 
-			ts := ts.Unix()
+			ts := metrics.UnixMillis(ts)
 			source := nodeName
 			if source == "" {
 				source = hostname
@@ -17,6 +17,7 @@
 package systemd
 
 import (
+	"context"
 	"fmt"
 	"github.com/wavefronthq/go-metrics-wavefront/reporting"
 	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/configuration"
@@ -56,14 +57,27 @@ func (src *systemdMetricsSource) Name() string {
 	return "systemd_metrics_source"
 }
 
-func (src *systemdMetricsSource) ScrapeMetrics() (*DataBatch, error) {
+func (src *systemdMetricsSource) ScrapeMetrics(ctx context.Context) (*DataBatch, error) {
 	// gathers metrics from systemd using dbus. collection is done in parallel to reduce wait time for responses.
 	conn, err := dbus.New()
 	if err != nil {
 		src.eps.Inc(1)
 		return nil, fmt.Errorf("couldn't get dbus connection: %s", err)
 	}
-	defer conn.Close()
+	var closeOnce sync.Once
+	closeConn := func() { closeOnce.Do(conn.Close) }
+	defer closeConn()
+
+	// the dbus calls do not accept a context, closing the connection aborts the pending calls instead
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			closeConn()
+		case <-stop:
+		}
+	}()
 
 	allUnits, err := src.getAllUnits(conn)
 	if err != nil {
@@ -71,7 +85,7 @@ func (src *systemdMetricsSource) ScrapeMetrics() (*DataBatch, error) {
 		return nil, fmt.Errorf("couldn't get units: %s", err)
 	}
 
-	now := time.Now().Unix()
+	now := metrics.UnixMillis(time.Now())
 	result := &DataBatch{
 		Timestamp: time.Now(),
 	}
@@ -150,6 +164,10 @@ func (src *systemdMetricsSource) ScrapeMetrics() (*DataBatch, error) {
 	// wait for gathering to process all the points
 	<-done
 
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
 	result.MetricPoints = points
 	count := len(result.MetricPoints)
 	log.Infof("%s metrics: %d", "systemd", count)
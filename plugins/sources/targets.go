@@ -0,0 +1,102 @@
+package sources
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
+
+	gometrics "github.com/rcrowley/go-metrics"
+	"github.com/wavefronthq/go-metrics-wavefront/reporting"
+)
+
+const (
+	// reported for every target of a provider, 1 if the target was scraped successfully and 0 otherwise
+	targetUpMetric = "kubernetes.collector.target.up"
+
+	defaultTargetSource = "wavefront-kubernetes-collector"
+)
+
+// targets tracks the scraped targets of a provider. Targets that disappear are reported as down
+// once, so dashboards can tell a target that is gone from a target reporting zero values.
+type targets struct {
+	provider string
+
+	mtx  sync.Mutex
+	seen map[string]bool
+}
+
+func newTargets(provider string) *targets {
+	return &targets{
+		provider: provider,
+		seen:     make(map[string]bool),
+	}
+}
+
+// update records the current targets of the provider and returns the targets that disappeared since the previous scrape.
+func (t *targets) update(sources []metrics.MetricsSource) []string {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	current := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		current[source.Name()] = true
+	}
+	var stale []string
+	for name := range t.seen {
+		if !current[name] {
+			stale = append(stale, name)
+		}
+	}
+	t.seen = current
+	sort.Strings(stale)
+	return stale
+}
+
+// clear forgets all the targets of the provider and returns them.
+func (t *targets) clear() []string {
+	return t.update(nil)
+}
+
+// upPoint returns the target.up point of a provider target.
+func (t *targets) upPoint(target string, value float64) *metrics.MetricPoint {
+	source := util.GetNodeName()
+	if source == "" {
+		source = defaultTargetSource
+	}
+	return &metrics.MetricPoint{
+		Metric:    targetUpMetric,
+		Value:     value,
+		Timestamp: metrics.UnixMillis(time.Now()),
+		Source:    source,
+		Tags:      targetTags(t.provider, target),
+	}
+}
+
+// downBatch returns the target.up points of the targets that could not be scraped and of the stale targets.
+func (t *targets) downBatch(down, stale []string) *metrics.DataBatch {
+	batch := &metrics.DataBatch{Timestamp: time.Now()}
+	for _, target := range down {
+		batch.MetricPoints = append(batch.MetricPoints, t.upPoint(target, 0))
+	}
+	for _, target := range stale {
+		batch.MetricPoints = append(batch.MetricPoints, t.upPoint(target, 0))
+		unregisterTargetMetrics(t.provider, target)
+	}
+	return batch
+}
+
+// targetTags returns the tags of the internal metrics reported per target.
+func targetTags(provider, target string) map[string]string {
+	return map[string]string{"provider": provider, "target": target}
+}
+
+// unregisterTargetMetrics removes the internal metrics of a target that is gone.
+func unregisterTargetMetrics(provider, target string) {
+	tags := targetTags(provider, target)
+	for _, name := range []string{"target.scrape.errors", "target.scrape.latency", "target.scrape.timeouts"} {
+		gometrics.Unregister(reporting.EncodeKey(name, tags))
+	}
+}
@@ -0,0 +1,38 @@
+package sources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/metrics"
+	"github.com/wavefronthq/wavefront-kubernetes-collector/internal/util"
+)
+
+func TestTargetsUpdate(t *testing.T) {
+	tracked := newTargets("provider")
+	s1 := util.NewDummyMetricsSource("s1", 0)
+	s2 := util.NewDummyMetricsSource("s2", 0)
+	s3 := util.NewDummyMetricsSource("s3", 0)
+
+	assert.Empty(t, tracked.update([]metrics.MetricsSource{s1, s2, s3}))
+	assert.Empty(t, tracked.update([]metrics.MetricsSource{s1, s2, s3}))
+	assert.Equal(t, []string{"s1", "s3"}, tracked.update([]metrics.MetricsSource{s2}))
+	assert.Equal(t, []string{"s2"}, tracked.clear())
+	assert.Empty(t, tracked.clear())
+}
+
+func TestTargetsDownBatch(t *testing.T) {
+	tracked := newTargets("provider")
+	batch := tracked.downBatch([]string{"down"}, []string{"gone"})
+
+	values := make(map[string]float64)
+	for _, point := range batch.MetricPoints {
+		assert.Equal(t, targetUpMetric, point.Metric)
+		assert.Equal(t, "provider", point.Tags["provider"])
+		assert.InDelta(t, metrics.UnixMillis(time.Now()), point.Timestamp, float64(time.Minute/time.Millisecond))
+		values[point.Tags["target"]] = point.Value
+	}
+	assert.Equal(t, map[string]float64{"down": 0, "gone": 0}, values)
+}
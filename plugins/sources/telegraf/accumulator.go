@@ -1,6 +1,7 @@
 package telegraf
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -16,9 +17,15 @@ import (
 type telegrafDataBatch struct {
 	metrics.DataBatch
 	source *telegrafPluginSource
+	ctx    context.Context
 }
 
 func (t *telegrafDataBatch) preparePoints(measurement string, fields map[string]interface{}, tags map[string]string, timestamp ...time.Time) {
+	if t.ctx.Err() != nil {
+		// the scrape was abandoned, drop the points reported by the plugin after the fact
+		return
+	}
+
 	var ts time.Time
 	if len(timestamp) > 0 {
 		ts = timestamp[0]
@@ -49,7 +56,7 @@ func (t *telegrafDataBatch) preparePoints(measurement string, fields map[string]
 		point := &metrics.MetricPoint{
 			Metric:    metricName,
 			Value:     value,
-			Timestamp: ts.UnixNano() / 1000,
+			Timestamp: metrics.UnixMillis(ts),
 			Source:    t.source.source,
 			Tags:      t.buildTags(tags),
 		}
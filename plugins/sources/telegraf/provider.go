@@ -1,8 +1,10 @@
 package telegraf
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -30,6 +32,10 @@ type telegrafPluginSource struct {
 	errors          gm.Counter
 	targetPPS       gm.Counter
 	targetEPS       gm.Counter
+
+	// set while a Gather call is outstanding, including one abandoned by a timed out scrape
+	mtx       sync.Mutex
+	gathering bool
 }
 
 func newTelegrafPluginSource(name string, plugin telegraf.Input, prefix string, tags map[string]string, filters filter.Filter, discovered string) *telegrafPluginSource {
@@ -77,14 +83,39 @@ func (t *telegrafPluginSource) Name() string {
 	return "telegraf_" + t.name + "_source"
 }
 
-func (t *telegrafPluginSource) ScrapeMetrics() (*metrics.DataBatch, error) {
+func (t *telegrafPluginSource) ScrapeMetrics(ctx context.Context) (*metrics.DataBatch, error) {
+	// plugins are not safe for concurrent use, so fail the scrape until a previous Gather returns
+	t.mtx.Lock()
+	if t.gathering {
+		t.mtx.Unlock()
+		return nil, fmt.Errorf("previous %s gather has not completed", t.name)
+	}
+	t.gathering = true
+	t.mtx.Unlock()
+
 	result := &telegrafDataBatch{
 		DataBatch: metrics.DataBatch{Timestamp: time.Now()},
 		source:    t,
+		ctx:       ctx,
 	}
 
-	// Gather invokes callbacks on telegrafDataBatch
-	err := t.plugin.Gather(result)
+	// Gather invokes callbacks on telegrafDataBatch. It does not accept a context, so the
+	// scrape is abandoned once the context is done and the accumulator drops any further points.
+	gathered := make(chan error, 1)
+	go func() {
+		err := t.plugin.Gather(result)
+		t.mtx.Lock()
+		t.gathering = false
+		t.mtx.Unlock()
+		gathered <- err
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case err = <-gathered:
+	}
 	if err != nil {
 		t.errors.Inc(1)
 		if t.targetEPS != nil {
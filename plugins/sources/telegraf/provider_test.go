@@ -0,0 +1,54 @@
+package telegraf
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type blockingInput struct {
+	release chan struct{}
+}
+
+func (b *blockingInput) SampleConfig() string { return "" }
+
+func (b *blockingInput) Description() string { return "" }
+
+func (b *blockingInput) Gather(acc telegraf.Accumulator) error {
+	<-b.release
+	acc.AddGauge("test", map[string]interface{}{"value": 1.0}, nil)
+	return nil
+}
+
+func TestScrapeWhileGathering(t *testing.T) {
+	input := &blockingInput{release: make(chan struct{})}
+	src := newTelegrafPluginSource("blocking", input, "", nil, nil, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := src.ScrapeMetrics(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// the abandoned Gather is still running
+	_, err = src.ScrapeMetrics(context.Background())
+	assert.Error(t, err)
+
+	close(input.release)
+	for i := 0; i < 100 && gathering(src); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	batch, err := src.ScrapeMetrics(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(batch.MetricPoints))
+}
+
+func gathering(src *telegrafPluginSource) bool {
+	src.mtx.Lock()
+	defer src.mtx.Unlock()
+	return src.gathering
+}